@@ -23,6 +23,9 @@ const (
 	MixedAccountBranch = int32(udb.ExternalBranch)
 )
 
+// AddAccountMixerNotificationListener adds a listener for the start and end
+// of the account mixer. Listeners that also implement
+// AccountMixerSessionListener are notified of the mixer's sessions.
 func (mw *MultiWallet) AddAccountMixerNotificationListener(accountMixerNotificationListener AccountMixerNotificationListener, uniqueIdentifier string) error {
 	mw.notificationListenersMu.Lock()
 	defer mw.notificationListenersMu.Unlock()
@@ -55,12 +58,12 @@ func (wallet *Wallet) CreateMixerAccounts(mixedAccount, unmixedAccount, privPass
 		return errors.New(ErrExist)
 	}
 
-	err := wallet.UnlockWallet([]byte(privPass))
+	relock, err := wallet.unlockTemporarily([]byte(privPass))
 	if err != nil {
 		return err
 	}
 
-	defer wallet.LockWallet()
+	defer relock()
 
 	mixedAccountNumber, err := wallet.NextAccount(mixedAccount)
 	if err != nil {
@@ -98,11 +101,11 @@ func (wallet *Wallet) SetAccountMixerConfig(mixedAccount, unmixedAccount int32,
 		return errors.New(ErrNotExist)
 	}
 
-	err = wallet.UnlockWallet([]byte(privPass))
+	relock, err := wallet.unlockTemporarily([]byte(privPass))
 	if err != nil {
 		return err
 	}
-	wallet.LockWallet()
+	relock()
 
 	wallet.SetInt32ConfigValueForKey(AccountMixerMixedAccount, mixedAccount)
 	wallet.SetInt32ConfigValueForKey(AccountMixerUnmixedAccount, unmixedAccount)
@@ -111,6 +114,19 @@ func (wallet *Wallet) SetAccountMixerConfig(mixedAccount, unmixedAccount int32,
 	return nil
 }
 
+// SetAccountMixerCSPPServer sets the host:port of the coinshuffle++ server
+// used by the account mixer. An empty csppServer restores the default server.
+func (wallet *Wallet) SetAccountMixerCSPPServer(csppServer string) error {
+	if csppServer != "" {
+		if _, _, err := net.SplitHostPort(csppServer); err != nil {
+			return errors.New(ErrInvalid)
+		}
+	}
+
+	wallet.SetStringConfigValueForKey(AccountMixerCSPPServer, csppServer)
+	return nil
+}
+
 func (wallet *Wallet) AccountMixerMixChange() bool {
 	return wallet.ReadBoolConfigValueForKey(AccountMixerMixTxChange, false)
 }
@@ -160,15 +176,36 @@ func (mw *MultiWallet) StartAccountMixer(walletID int, walletPassphrase string)
 		return errors.New(ErrNotExist)
 	}
 
+	// Set the cancel func before checking the config so that the mixer is
+	// reported as active immediately and cannot be started twice.
+	wallet.cancelAccountMixerMu.Lock()
+	if wallet.cancelAccountMixer != nil {
+		wallet.cancelAccountMixerMu.Unlock()
+		return errors.New(ErrInvalid)
+	}
+	ctx, cancel := mw.contextWithShutdownCancel()
+	wallet.cancelAccountMixer = cancel
+	wallet.cancelAccountMixerMu.Unlock()
+
+	stopMixer := func() {
+		cancel()
+		wallet.cancelAccountMixerMu.Lock()
+		wallet.cancelAccountMixer = nil
+		wallet.cancelAccountMixerMu.Unlock()
+	}
+
 	cfg := wallet.readCSPPConfig()
 	if cfg == nil {
+		stopMixer()
 		return errors.New(ErrFailedPrecondition)
 	}
 
 	hasMixableOutput, err := wallet.accountHasMixableOutput(int32(cfg.ChangeAccount))
 	if err != nil {
+		stopMixer()
 		return translateError(err)
 	} else if !hasMixableOutput {
+		stopMixer()
 		return errors.New(ErrNoMixableOutput)
 	}
 
@@ -187,26 +224,22 @@ func (mw *MultiWallet) StartAccountMixer(walletID int, walletPassphrase string)
 
 	err = wallet.UnlockWallet([]byte(walletPassphrase))
 	if err != nil {
+		stopMixer()
 		return translateError(err)
 	}
 
 	go func() {
 		log.Info("Running account mixer")
-		if mw.accountMixerNotificationListener != nil {
-			mw.publishAccountMixerStarted(walletID)
-		}
+		mw.publishAccountMixerStarted(walletID)
 
-		ctx, cancel := mw.contextWithShutdownCancel()
-		wallet.cancelAccountMixer = cancel
-		err = tb.Run(ctx, []byte(walletPassphrase))
-		if err != nil {
+		err := tb.Run(ctx, []byte(walletPassphrase))
+		if err != nil && ctx.Err() == nil {
 			log.Errorf("AccountMixer instance errored: %v", err)
+			mw.publishAccountMixerFailed(walletID, err)
 		}
 
-		wallet.cancelAccountMixer = nil
-		if mw.accountMixerNotificationListener != nil {
-			mw.publishAccountMixerEnded(walletID)
-		}
+		stopMixer()
+		mw.publishAccountMixerEnded(walletID)
 	}()
 
 	return nil
@@ -221,17 +254,24 @@ func (wallet *Wallet) readCSPPConfig() *CSPPConfig {
 		return nil
 	}
 
-	var shufflePort = TestnetShufflePort
+	var csppServer = ShuffleServer + ":" + TestnetShufflePort
 	var dialCSPPServer func(ctx context.Context, network, addr string) (net.Conn, error)
 	if wallet.chainParams.Net == chaincfg.MainNetParams().Net {
-		shufflePort = MainnetShufflePort
-
-		pool := x509.NewCertPool()
-		pool.AppendCertsFromPEM([]byte(certs.CSPP))
+		csppServer = ShuffleServer + ":" + MainnetShufflePort
 
 		csppTLSConfig := new(tls.Config)
 		csppTLSConfig.ServerName = ShuffleServer
-		csppTLSConfig.RootCAs = pool
+
+		// The certificate of the default server is pinned, the certificates
+		// of other servers are verified with the system's root CAs.
+		if customServer := wallet.ReadStringConfigValueForKey(AccountMixerCSPPServer, ""); customServer != "" {
+			csppServer = customServer
+			csppTLSConfig.ServerName, _, _ = net.SplitHostPort(customServer)
+		} else {
+			pool := x509.NewCertPool()
+			pool.AppendCertsFromPEM([]byte(certs.CSPP))
+			csppTLSConfig.RootCAs = pool
+		}
 
 		dailer := new(net.Dialer)
 		dialCSPPServer = func(ctx context.Context, network, addr string) (net.Conn, error) {
@@ -243,10 +283,12 @@ func (wallet *Wallet) readCSPPConfig() *CSPPConfig {
 			conn = tls.Client(conn, csppTLSConfig)
 			return conn, nil
 		}
+	} else if customServer := wallet.ReadStringConfigValueForKey(AccountMixerCSPPServer, ""); customServer != "" {
+		csppServer = customServer
 	}
 
 	return &CSPPConfig{
-		CSPPServer:         ShuffleServer + ":" + shufflePort,
+		CSPPServer:         csppServer,
 		DialCSPPServer:     dialCSPPServer,
 		MixedAccount:       uint32(mixedAccount),
 		MixedAccountBranch: uint32(MixedAccountBranch),
@@ -255,7 +297,8 @@ func (wallet *Wallet) readCSPPConfig() *CSPPConfig {
 	}
 }
 
-// StopAccountMixer stops the active account mixer
+// StopAccountMixer stops the active account mixer. The mixer is reported as
+// active until it has stopped, when OnAccountMixerEnded is called.
 func (mw *MultiWallet) StopAccountMixer(walletID int) error {
 
 	wallet := mw.WalletWithID(walletID)
@@ -263,12 +306,14 @@ func (mw *MultiWallet) StopAccountMixer(walletID int) error {
 		return errors.New(ErrNotExist)
 	}
 
+	wallet.cancelAccountMixerMu.Lock()
+	defer wallet.cancelAccountMixerMu.Unlock()
+
 	if wallet.cancelAccountMixer == nil {
 		return errors.New(ErrInvalid)
 	}
 
 	wallet.cancelAccountMixer()
-	wallet.cancelAccountMixer = nil
 	return nil
 }
 
@@ -312,6 +357,8 @@ func (wallet *Wallet) accountHasMixableOutput(accountNumber int32) (bool, error)
 
 // IsAccountMixerActive returns true if account mixer is active
 func (wallet *Wallet) IsAccountMixerActive() bool {
+	wallet.cancelAccountMixerMu.Lock()
+	defer wallet.cancelAccountMixerMu.Unlock()
 	return wallet.cancelAccountMixer != nil
 }
 
@@ -332,3 +379,25 @@ func (mw *MultiWallet) publishAccountMixerEnded(walletID int) {
 		accountMixerNotificationListener.OnAccountMixerEnded(walletID)
 	}
 }
+
+func (mw *MultiWallet) publishAccountMixerSessionSucceeded(walletID int, txHash string) {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	for _, accountMixerNotificationListener := range mw.accountMixerNotificationListener {
+		if sessionListener, ok := accountMixerNotificationListener.(AccountMixerSessionListener); ok {
+			sessionListener.OnAccountMixerSessionSucceeded(walletID, txHash)
+		}
+	}
+}
+
+func (mw *MultiWallet) publishAccountMixerFailed(walletID int, err error) {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	for _, accountMixerNotificationListener := range mw.accountMixerNotificationListener {
+		if sessionListener, ok := accountMixerNotificationListener.(AccountMixerSessionListener); ok {
+			sessionListener.OnAccountMixerFailed(walletID, err.Error())
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"decred.org/dcrwallet/v2/errors"
+	"decred.org/dcrwallet/v2/wallet/udb"
+)
+
+// AccountAddress is an address of an account with the use of the address by
+// the wallet's indexed transactions.
+type AccountAddress struct {
+	Address string `json:"address"`
+	Branch  int32  `json:"branch"`
+	Index   int32  `json:"index"`
+	// Used is true if the address has ever received funds.
+	Used bool `json:"used"`
+	// TxCount is the number of transactions paying to or spending from the
+	// address.
+	TxCount       int32 `json:"tx_count"`
+	TotalReceived int64 `json:"total_received"`
+}
+
+// addressBatchSize is the number of addresses derived at a time when listing
+// the used addresses of an account.
+const addressBatchSize = 100
+
+// addressUsage is the use of an address by the indexed transactions.
+type addressUsage struct {
+	txCount       int32
+	totalReceived int64
+}
+
+// AddressesForAccount returns the json encoded AccountAddress list of the
+// receive addresses of the account followed by its change addresses, up to
+// the last used address of each branch, or the last returned address if
+// includeUnused is true. Without includeUnused, addresses that were never
+// used are left out. Up to limit addresses are returned from offset, all
+// addresses from offset are returned if limit is 0.
+//
+// The use of each listed address is read from the tx index, one read per
+// address. Without includeUnused, the addresses before offset cannot be
+// skipped since they have to be checked for use, so a page costs a read for
+// every address of the account up to the end of the page.
+func (wallet *Wallet) AddressesForAccount(account int32, includeUnused bool, offset, limit int32) (string, error) {
+	addresses, err := wallet.AddressesForAccountRaw(account, includeUnused, offset, limit)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(addresses)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+func (wallet *Wallet) AddressesForAccountRaw(account int32, includeUnused bool, offset, limit int32) ([]*AccountAddress, error) {
+	if !wallet.WalletOpened() {
+		return nil, errors.E(ErrWalletNotLoaded)
+	}
+
+	if account < 0 || uint32(account) == ImportedAccountNumber {
+		return nil, fmt.Errorf("%s: addresses cannot be listed for account %d", ErrInvalid, account)
+	}
+	if offset < 0 || limit < 0 {
+		return nil, fmt.Errorf("%s: offset and limit must not be negative", ErrInvalid)
+	}
+
+	accounts, err := wallet.Internal().Accounts(wallet.shutdownContext())
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var lastExternalIndex, lastInternalIndex uint32
+	var found bool
+	for _, a := range accounts.Accounts {
+		if a.AccountNumber != uint32(account) {
+			continue
+		}
+
+		found = true
+		lastExternalIndex, lastInternalIndex = a.LastUsedExternalIndex, a.LastUsedInternalIndex
+		if includeUnused {
+			lastExternalIndex = lastIndex(lastExternalIndex, a.LastReturnedExternalIndex)
+			lastInternalIndex = lastIndex(lastInternalIndex, a.LastReturnedInternalIndex)
+		}
+		break
+	}
+	if !found {
+		return nil, errors.New(ErrNotExist)
+	}
+
+	addresses := make([]*AccountAddress, 0)
+	branches := []struct {
+		branch    uint32
+		lastIndex uint32
+	}{
+		{udb.ExternalBranch, lastExternalIndex},
+		{udb.InternalBranch, lastInternalIndex},
+	}
+	for _, b := range branches {
+		// The last index is ^uint32(0) if no address of the branch was
+		// used or returned.
+		if b.lastIndex == ^uint32(0) {
+			continue
+		}
+		count := b.lastIndex + 1
+
+		// All addresses are listed if includeUnused is true, so the
+		// addresses before offset are skipped without deriving them.
+		// Otherwise the addresses are derived in batches and checked for
+		// use until the page is filled.
+		start, batchSize := uint32(0), uint32(addressBatchSize)
+		if includeUnused {
+			if uint32(offset) >= count {
+				offset -= int32(count)
+				continue
+			}
+			start, offset = uint32(offset), 0
+			batchSize = count - start
+			if remaining := limit - int32(len(addresses)); limit > 0 && uint32(remaining) < batchSize {
+				batchSize = uint32(remaining)
+			}
+		}
+
+		for ; start < count; start += batchSize {
+			if start+batchSize > count {
+				batchSize = count - start
+			}
+
+			derived, err := wallet.deriveAddresses(uint32(account), b.branch, start, batchSize)
+			if err != nil {
+				return nil, err
+			}
+
+			for i, addr := range derived {
+				if addr == "" {
+					continue
+				}
+
+				usage, err := wallet.accountAddressUsage(account, addr)
+				if err != nil {
+					return nil, err
+				}
+				if usage.txCount == 0 && !includeUnused {
+					continue
+				}
+				if offset > 0 {
+					offset--
+					continue
+				}
+
+				addresses = append(addresses, &AccountAddress{
+					Address:       addr,
+					Branch:        int32(b.branch),
+					Index:         int32(start) + int32(i),
+					Used:          usage.totalReceived > 0,
+					TxCount:       usage.txCount,
+					TotalReceived: usage.totalReceived,
+				})
+				if limit > 0 && len(addresses) == int(limit) {
+					return addresses, nil
+				}
+			}
+		}
+	}
+
+	return addresses, nil
+}
+
+// accountAddressUsage returns the use of an address of the account by the
+// indexed transactions, which are read from the tx index by address.
+func (wallet *Wallet) accountAddressUsage(account int32, address string) (addressUsage, error) {
+	var transactions []Transaction
+	err := wallet.walletDataDB.ReadForAddress(address, &transactions)
+	if err != nil {
+		return addressUsage{}, translateError(err)
+	}
+
+	usage := addressUsage{txCount: int32(len(transactions))}
+	for _, tx := range transactions {
+		for _, output := range tx.Outputs {
+			if output.Address == address && output.AccountNumber == account {
+				usage.totalReceived += output.Amount
+			}
+		}
+	}
+	return usage, nil
+}
+
+// lastIndex returns the larger of two child indexes, either of which may be
+// ^uint32(0) if unset.
+func lastIndex(a, b uint32) uint32 {
+	switch {
+	case a == ^uint32(0):
+		return b
+	case b == ^uint32(0):
+		return a
+	case a > b:
+		return a
+	default:
+		return b
+	}
+}
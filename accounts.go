@@ -1,6 +1,7 @@
 package dcrlibwallet
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 
 	"decred.org/dcrwallet/v2/errors"
 	w "decred.org/dcrwallet/v2/wallet"
+	"decred.org/dcrwallet/v2/wallet/txsizes"
 	"decred.org/dcrwallet/v2/wallet/udb"
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/dcrutil/v4"
@@ -21,24 +23,40 @@ const (
 )
 
 func (wallet *Wallet) GetAccounts() (string, error) {
-	accountsResponse, err := wallet.GetAccountsRaw()
+	return wallet.GetAccountsWithConfirmations(wallet.RequiredConfirmations())
+}
+
+func (wallet *Wallet) GetAccountsRaw() (*Accounts, error) {
+	return wallet.GetAccountsRawWithConfirmations(wallet.RequiredConfirmations())
+}
+
+// GetAccountsWithConfirmations returns the JSON encoding of the wallet's
+// accounts, with balances computed using requiredConfirmations.
+func (wallet *Wallet) GetAccountsWithConfirmations(requiredConfirmations int32) (string, error) {
+	accountsResponse, err := wallet.GetAccountsRawWithConfirmations(requiredConfirmations)
 	if err != nil {
-		return "", nil
+		return "", err
 	}
 
 	result, _ := json.Marshal(accountsResponse)
 	return string(result), nil
 }
 
-func (wallet *Wallet) GetAccountsRaw() (*Accounts, error) {
+// GetAccountsRawWithConfirmations returns the wallet's accounts, with balances
+// computed using requiredConfirmations.
+func (wallet *Wallet) GetAccountsRawWithConfirmations(requiredConfirmations int32) (*Accounts, error) {
+	if requiredConfirmations < 0 {
+		return nil, errors.New(ErrInvalid)
+	}
+
 	resp, err := wallet.Internal().Accounts(wallet.shutdownContext())
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	accounts := make([]*Account, len(resp.Accounts))
 	for i, a := range resp.Accounts {
-		balance, err := wallet.GetAccountBalance(int32(a.AccountNumber))
+		balance, err := wallet.getAccountBalance(int32(a.AccountNumber), requiredConfirmations)
 		if err != nil {
 			return nil, err
 		}
@@ -104,10 +122,58 @@ func (wallet *Wallet) GetAccount(accountNumber int32) (*Account, error) {
 	return nil, errors.New(ErrNotExist)
 }
 
+// UnconfirmedBalance returns the total value of the account's outputs that
+// do not have the required number of confirmations yet, including unmined
+// change outputs.
+func (wallet *Wallet) UnconfirmedBalance(accountNumber int32) (int64, error) {
+	balance, err := wallet.GetAccountBalance(accountNumber)
+	if err != nil {
+		return 0, err
+	}
+	return balance.UnConfirmed, nil
+}
+
 func (wallet *Wallet) GetAccountBalance(accountNumber int32) (*Balance, error) {
-	balance, err := wallet.Internal().AccountBalance(wallet.shutdownContext(), uint32(accountNumber), wallet.RequiredConfirmations())
+	return wallet.getAccountBalance(accountNumber, wallet.RequiredConfirmations())
+}
+
+// GetAccountBalanceWithConfirmations returns the JSON encoding of the
+// account's balance, computed using requiredConfirmations.
+func (wallet *Wallet) GetAccountBalanceWithConfirmations(accountNumber int32, requiredConfirmations int32) (string, error) {
+	balance, err := wallet.GetAccountBalanceRawWithConfirmations(accountNumber, requiredConfirmations)
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+
+	result, _ := json.Marshal(balance)
+	return string(result), nil
+}
+
+// GetAccountBalanceRawWithConfirmations returns the account's balance,
+// computed using requiredConfirmations. Outputs with fewer confirmations are
+// reported as unconfirmed rather than spendable.
+func (wallet *Wallet) GetAccountBalanceRawWithConfirmations(accountNumber int32, requiredConfirmations int32) (*Balance, error) {
+	if requiredConfirmations < 0 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	return wallet.getAccountBalance(accountNumber, requiredConfirmations)
+}
+
+// GetAccountBalanceContext is like GetAccountBalance but stops computing the
+// balance when ctx is canceled.
+func (wallet *Wallet) GetAccountBalanceContext(ctx context.Context, accountNumber int32) (*Balance, error) {
+	return wallet.getAccountBalanceContext(ctx, accountNumber, wallet.RequiredConfirmations())
+}
+
+func (wallet *Wallet) getAccountBalance(accountNumber int32, requiredConfirmations int32) (*Balance, error) {
+	return wallet.getAccountBalanceContext(wallet.shutdownContext(), accountNumber, requiredConfirmations)
+}
+
+func (wallet *Wallet) getAccountBalanceContext(ctx context.Context, accountNumber int32, requiredConfirmations int32) (*Balance, error) {
+	balance, err := wallet.Internal().AccountBalance(ctx, uint32(accountNumber), requiredConfirmations)
+	if err != nil {
+		return nil, translateError(err)
 	}
 
 	return &Balance{
@@ -122,18 +188,95 @@ func (wallet *Wallet) GetAccountBalance(accountNumber int32) (*Balance, error) {
 }
 
 func (wallet *Wallet) SpendableForAccount(account int32) (int64, error) {
-	bals, err := wallet.Internal().AccountBalance(wallet.shutdownContext(), uint32(account), wallet.RequiredConfirmations())
+	return wallet.SpendableForAccountWithConfirmations(account, wallet.RequiredConfirmations())
+}
+
+// SpendableForAccountWithConfirmations returns the sum of the account's
+// unspent outputs that have at least requiredConfirmations confirmations.
+// Outputs locked by tickets and dust outputs are excluded.
+func (wallet *Wallet) SpendableForAccountWithConfirmations(account int32, requiredConfirmations int32) (int64, error) {
+	if requiredConfirmations < 0 {
+		return 0, errors.New(ErrInvalid)
+	}
+
+	policy := w.OutputSelectionPolicy{
+		Account:               uint32(account),
+		RequiredConfirmations: requiredConfirmations,
+	}
+
+	// use targetAmount = 0 to fetch ALL spendable utxos in account
+	inputDetail, err := wallet.Internal().SelectInputs(wallet.shutdownContext(), dcrutil.Amount(0), policy)
 	if err != nil {
 		log.Error(err)
 		return 0, translateError(err)
 	}
-	return int64(bals.Spendable), nil
+
+	feeRate := wallet.feeRate()
+	var spendable int64
+	for _, input := range inputDetail.Inputs {
+		if isDustAmount(input.ValueIn, txsizes.P2PKHPkScriptSize, feeRate) {
+			continue
+		}
+		spendable += input.ValueIn
+	}
+
+	return spendable, nil
+}
+
+// TotalSpendable returns the spendable balance of all the wallet's accounts,
+// excluding the imported account, computed using requiredConfirmations.
+func (wallet *Wallet) TotalSpendable(requiredConfirmations int32) (int64, error) {
+	resp, err := wallet.Internal().Accounts(wallet.shutdownContext())
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	var total int64
+	for _, a := range resp.Accounts {
+		if a.AccountNumber == ImportedAccountNumber {
+			continue
+		}
+
+		spendable, err := wallet.SpendableForAccountWithConfirmations(int32(a.AccountNumber), requiredConfirmations)
+		if err != nil {
+			return 0, err
+		}
+		total += spendable
+	}
+
+	return total, nil
 }
 
 func (wallet *Wallet) UnspentOutputs(account int32) ([]*UnspentOutput, error) {
+	return wallet.UnspentOutputsWithConfirmations(account, wallet.RequiredConfirmations())
+}
+
+// UnspentOutputsJSON returns the JSON encoding of the account's unspent
+// outputs that have at least requiredConfirmations confirmations.
+func (wallet *Wallet) UnspentOutputsJSON(account int32, requiredConfirmations int32) (string, error) {
+	unspentOutputs, err := wallet.UnspentOutputsWithConfirmations(account, requiredConfirmations)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(unspentOutputs)
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
+// UnspentOutputsWithConfirmations returns the account's unspent outputs that
+// have at least requiredConfirmations confirmations.
+func (wallet *Wallet) UnspentOutputsWithConfirmations(account int32, requiredConfirmations int32) ([]*UnspentOutput, error) {
+	if requiredConfirmations < 0 {
+		return nil, errors.New(ErrInvalid)
+	}
+
 	policy := w.OutputSelectionPolicy{
 		Account:               uint32(account),
-		RequiredConfirmations: wallet.RequiredConfirmations(),
+		RequiredConfirmations: requiredConfirmations,
 	}
 
 	// fetch all utxos in account to extract details for the utxos selected by user
@@ -141,7 +284,7 @@ func (wallet *Wallet) UnspentOutputs(account int32) ([]*UnspentOutput, error) {
 	inputDetail, err := wallet.Internal().SelectInputs(wallet.shutdownContext(), dcrutil.Amount(0), policy)
 
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	unspentOutputs := make([]*UnspentOutput, len(inputDetail.Inputs))
@@ -181,12 +324,22 @@ func (wallet *Wallet) UnspentOutputs(account int32) ([]*UnspentOutput, error) {
 }
 
 func (wallet *Wallet) CreateNewAccount(accountName string, privPass []byte) (int32, error) {
-	err := wallet.UnlockWallet(privPass)
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	if err := udb.ValidateAccountName(accountName); err != nil {
+		return -1, translateError(err)
+	}
+
+	relock, err := wallet.unlockTemporarily(privPass)
 	if err != nil {
 		return -1, err
 	}
 
-	defer wallet.LockWallet()
+	defer relock()
 
 	return wallet.NextAccount(accountName)
 }
@@ -201,13 +354,17 @@ func (wallet *Wallet) NextAccount(accountName string) (int32, error) {
 
 	accountNumber, err := wallet.Internal().NextAccount(ctx, accountName)
 	if err != nil {
-		return -1, err
+		return -1, translateError(err)
 	}
 
 	return int32(accountNumber), nil
 }
 
 func (wallet *Wallet) RenameAccount(accountNumber int32, newName string) error {
+	if err := udb.ValidateAccountName(newName); err != nil {
+		return translateError(err)
+	}
+
 	err := wallet.Internal().RenameAccount(wallet.shutdownContext(), uint32(accountNumber), newName)
 	if err != nil {
 		return translateError(err)
@@ -238,6 +395,15 @@ func (wallet *Wallet) HasAccount(accountName string) bool {
 	return err == nil
 }
 
+// GetExtendedPubKey returns the serialized extended public key of the account.
+func (wallet *Wallet) GetExtendedPubKey(account int32) (string, error) {
+	extendedPublicKey, err := wallet.Internal().AccountXpub(wallet.shutdownContext(), uint32(account))
+	if err != nil {
+		return "", translateError(err)
+	}
+	return extendedPublicKey.String(), nil
+}
+
 func (wallet *Wallet) HDPathForAccount(accountNumber int32) (string, error) {
 	cointype, err := wallet.Internal().CoinType(wallet.shutdownContext())
 	if err != nil {
@@ -5,16 +5,26 @@ import (
 
 	"decred.org/dcrwallet/v2/errors"
 	w "decred.org/dcrwallet/v2/wallet"
+	"decred.org/dcrwallet/v2/wallet/udb"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/hdkeychain/v3"
 	"github.com/decred/dcrd/txscript/v4/stdaddr"
 )
 
+// maxPreviewAddresses is the largest number of addresses returned by
+// PreviewAddresses.
+const maxPreviewAddresses = 1000
+
 // AddressInfo holds information about an address
 // If the address belongs to the querying wallet, IsMine will be true and the AccountNumber and AccountName values will be populated
+// Branch and Index are also populated for addresses derived from the wallet's HD keys.
 type AddressInfo struct {
 	Address       string
 	IsMine        bool
 	AccountNumber uint32
 	AccountName   string
+	Branch        uint32
+	Index         uint32
 }
 
 func (mw *MultiWallet) IsAddressValid(address string) bool {
@@ -23,17 +33,25 @@ func (mw *MultiWallet) IsAddressValid(address string) bool {
 }
 
 func (wallet *Wallet) HaveAddress(address string) bool {
+	have, _ := wallet.HaveAddressWithError(address)
+	return have
+}
+
+// HaveAddressWithError checks if the address belongs to the wallet. Unlike
+// HaveAddress, an error is returned if the address is not valid for the
+// wallet's network.
+func (wallet *Wallet) HaveAddressWithError(address string) (bool, error) {
 	addr, err := stdaddr.DecodeAddress(address, wallet.chainParams)
 	if err != nil {
-		return false
+		return false, errors.New(ErrInvalidAddress)
 	}
 
 	have, err := wallet.Internal().HaveAddress(wallet.shutdownContext(), addr)
 	if err != nil {
-		return false
+		return false, translateError(err)
 	}
 
-	return have
+	return have, nil
 }
 
 func (wallet *Wallet) AccountOfAddress(address string) (string, error) {
@@ -53,7 +71,7 @@ func (wallet *Wallet) AccountOfAddress(address string) (string, error) {
 func (wallet *Wallet) AddressInfo(address string) (*AddressInfo, error) {
 	addr, err := stdaddr.DecodeAddress(address, wallet.chainParams)
 	if err != nil {
-		return nil, err
+		return nil, errors.New(ErrInvalidAddress)
 	}
 
 	addressInfo := &AddressInfo{
@@ -70,6 +88,10 @@ func (wallet *Wallet) AddressInfo(address string) (*AddressInfo, error) {
 			return nil, err
 		}
 		addressInfo.AccountNumber = uint32(accountNumber)
+
+		if bip44Addr, ok := known.(w.BIP0044Address); ok {
+			_, addressInfo.Branch, addressInfo.Index = bip44Addr.Path()
+		}
 	}
 
 	return addressInfo, nil
@@ -79,14 +101,18 @@ func (wallet *Wallet) AddressInfo(address string) (*AddressInfo, error) {
 // wallet. If that address has already been used to receive funds, the next
 // chained address is returned.
 func (wallet *Wallet) CurrentAddress(account int32) (string, error) {
+	if !wallet.WalletOpened() {
+		return "", errors.E(ErrWalletNotLoaded)
+	}
+
 	if wallet.IsRestored && !wallet.HasDiscoveredAccounts {
 		return "", errors.E(ErrAddressDiscoveryNotDone)
 	}
 
 	addr, err := wallet.Internal().CurrentAddress(uint32(account))
 	if err != nil {
-		log.Errorf("CurrentAddress error: %w", err)
-		return "", err
+		log.Errorf("CurrentAddress error: %v", err)
+		return "", translateError(err)
 	}
 	return addr.String(), nil
 }
@@ -95,6 +121,10 @@ func (wallet *Wallet) CurrentAddress(account int32) (string, error) {
 // payment address. If that address has already been used to receive funds,
 // the next chained address is returned.
 func (wallet *Wallet) NextAddress(account int32) (string, error) {
+	if !wallet.WalletOpened() {
+		return "", errors.E(ErrWalletNotLoaded)
+	}
+
 	if wallet.IsRestored && !wallet.HasDiscoveredAccounts {
 		return "", errors.E(ErrAddressDiscoveryNotDone)
 	}
@@ -106,13 +136,106 @@ func (wallet *Wallet) NextAddress(account int32) (string, error) {
 	// upstream.
 	_, err := wallet.Internal().NewExternalAddress(wallet.shutdownContext(), uint32(account), w.WithGapPolicyWrap())
 	if err != nil {
-		log.Errorf("NewExternalAddress error: %w", err)
-		return "", err
+		log.Errorf("NewExternalAddress error: %v", err)
+		return "", translateError(err)
 	}
 
 	return wallet.CurrentAddress(account)
 }
 
+// NextChangeAddress returns a new address from the internal (change) branch
+// of the account, for use as a change destination.
+func (wallet *Wallet) NextChangeAddress(account int32) (string, error) {
+	if !wallet.WalletOpened() {
+		return "", errors.E(ErrWalletNotLoaded)
+	}
+
+	if wallet.IsRestored && !wallet.HasDiscoveredAccounts {
+		return "", errors.E(ErrAddressDiscoveryNotDone)
+	}
+
+	addr, err := wallet.Internal().NewInternalAddress(wallet.shutdownContext(), uint32(account), w.WithGapPolicyWrap())
+	if err != nil {
+		log.Errorf("NewInternalAddress error: %v", err)
+		return "", translateError(err)
+	}
+
+	return addr.String(), nil
+}
+
+// PreviewAddresses derives count addresses of the account's branch, 0 for
+// receive addresses and 1 for change addresses, from index start. The
+// addresses are derived from the account's extended public key and are not
+// marked as returned, so the wallet's address cursor and gap limit are not
+// affected and the addresses are not watched. Indexes without a valid child
+// key are skipped, as they are by the wallet.
+func (wallet *Wallet) PreviewAddresses(account, branch, start, count int32) ([]string, error) {
+	if !wallet.WalletOpened() {
+		return nil, errors.E(ErrWalletNotLoaded)
+	}
+
+	if account < 0 || uint32(account) == ImportedAccountNumber {
+		return nil, fmt.Errorf("%s: addresses cannot be derived for account %d", ErrInvalid, account)
+	}
+	if branch != int32(udb.ExternalBranch) && branch != int32(udb.InternalBranch) {
+		return nil, fmt.Errorf("%s: branch must be %d or %d", ErrInvalid, udb.ExternalBranch, udb.InternalBranch)
+	}
+	if start < 0 || count <= 0 || count > maxPreviewAddresses {
+		return nil, fmt.Errorf("%s: start must not be negative and count must be between 1 and %d", ErrInvalid, maxPreviewAddresses)
+	}
+	if uint32(start)+uint32(count) > hdkeychain.HardenedKeyStart {
+		return nil, fmt.Errorf("%s: index out of range", ErrInvalid)
+	}
+
+	derived, err := wallet.deriveAddresses(uint32(account), uint32(branch), uint32(start), uint32(count))
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0, len(derived))
+	for _, addr := range derived {
+		if addr != "" {
+			addresses = append(addresses, addr)
+		}
+	}
+
+	return addresses, nil
+}
+
+// deriveAddresses derives count addresses of the account's branch from index
+// start. The address of an index without a valid child key is empty.
+func (wallet *Wallet) deriveAddresses(account, branch, start, count uint32) ([]string, error) {
+	acctXPub, err := wallet.Internal().AccountXpub(wallet.shutdownContext(), account)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	branchXPub, err := acctXPub.Child(branch)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	addresses := make([]string, count)
+	for i := range addresses {
+		child, err := branchXPub.Child(start + uint32(i))
+		if err == hdkeychain.ErrInvalidChild {
+			continue
+		}
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		pkHash := dcrutil.Hash160(child.SerializedPubKey())
+		addr, err := stdaddr.NewAddressPubKeyHashEcdsaSecp256k1V0(pkHash, wallet.chainParams)
+		if err != nil {
+			return nil, err
+		}
+		addresses[i] = addr.String()
+	}
+
+	return addresses, nil
+}
+
 func (wallet *Wallet) AddressPubKey(address string) (string, error) {
 	addr, err := stdaddr.DecodeAddress(address, wallet.chainParams)
 	if err != nil {
@@ -136,3 +259,34 @@ func (wallet *Wallet) AddressPubKey(address string) (string, error) {
 		return "", fmt.Errorf("address is not a managed pub key address")
 	}
 }
+
+// ImportScript imports the redeem script into the wallet with the provided id
+// and returns its P2SH address. Outputs paying to the address are treated as
+// the wallet's. If rescan is true, blocks are rescanned from scanFrom to find
+// the address's transactions. Importing a script that was imported before only
+// triggers the rescan, if requested.
+func (mw *MultiWallet) ImportScript(walletID int, script []byte, rescan bool, scanFrom int32) (string, error) {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return "", errors.New(ErrNotExist)
+	}
+
+	addr, err := stdaddr.NewAddressScriptHashV0(script, wallet.chainParams)
+	if err != nil {
+		return "", fmt.Errorf("%s: invalid script: %v", ErrInvalid, err)
+	}
+
+	err = wallet.Internal().ImportScript(wallet.shutdownContext(), script)
+	if err != nil && !errors.Is(err, errors.Exist) {
+		return "", translateError(err)
+	}
+
+	if rescan {
+		err = mw.RescanBlocksFromHeight(walletID, scanFrom)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return addr.String(), nil
+}
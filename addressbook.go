@@ -0,0 +1,124 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"strings"
+
+	"decred.org/dcrwallet/v2/errors"
+	"github.com/asdine/storm"
+)
+
+// AddressBookEntry is a labelled destination address saved by the user.
+type AddressBookEntry struct {
+	ID      int    `storm:"id,increment" json:"id"`
+	Label   string `storm:"unique" json:"label"`
+	Address string `storm:"index" json:"address"`
+}
+
+// AddToAddressBook saves address with the provided label and returns the id
+// of the new entry. ErrExist is returned if another entry has the same label.
+func (mw *MultiWallet) AddToAddressBook(label, address string) (int, error) {
+	entry := &AddressBookEntry{
+		Label:   strings.TrimSpace(label),
+		Address: strings.TrimSpace(address),
+	}
+	if err := mw.validateAddressBookEntry(entry); err != nil {
+		return 0, err
+	}
+
+	err := mw.db.Save(entry)
+	if err != nil {
+		return 0, addressBookError(err)
+	}
+
+	return entry.ID, nil
+}
+
+// UpdateAddressBookEntry changes the label and address of the entry with the
+// provided id.
+func (mw *MultiWallet) UpdateAddressBookEntry(id int, label, address string) error {
+	entry := &AddressBookEntry{
+		ID:      id,
+		Label:   strings.TrimSpace(label),
+		Address: strings.TrimSpace(address),
+	}
+	if err := mw.validateAddressBookEntry(entry); err != nil {
+		return err
+	}
+
+	var savedEntry AddressBookEntry
+	err := mw.db.One("ID", id, &savedEntry)
+	if err != nil {
+		return translateError(err)
+	}
+
+	return addressBookError(mw.db.Update(entry))
+}
+
+// DeleteAddressBookEntry deletes the entry with the provided id.
+func (mw *MultiWallet) DeleteAddressBookEntry(id int) error {
+	err := mw.db.DeleteStruct(&AddressBookEntry{ID: id})
+	if err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// AddressBookRaw returns the saved address book entries ordered by label.
+func (mw *MultiWallet) AddressBookRaw() ([]AddressBookEntry, error) {
+	var entries []AddressBookEntry
+	err := mw.db.AllByIndex("Label", &entries)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// AddressBook returns the saved address book entries as json.
+func (mw *MultiWallet) AddressBook() (string, error) {
+	entries, err := mw.AddressBookRaw()
+	if err != nil {
+		return "", err
+	}
+
+	if entries == nil {
+		entries = []AddressBookEntry{}
+	}
+
+	result, err := json.Marshal(entries)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// addressLabel returns the label of the address book entry for address or
+// an empty string if the address is not in the address book.
+func (mw *MultiWallet) addressLabel(address string) string {
+	var entry AddressBookEntry
+	err := mw.db.One("Address", address, &entry)
+	if err != nil {
+		if err != storm.ErrNotFound {
+			log.Errorf("Error reading address book label for %s: %v", address, err)
+		}
+		return ""
+	}
+	return entry.Label
+}
+
+func (mw *MultiWallet) validateAddressBookEntry(entry *AddressBookEntry) error {
+	if entry.Label == "" {
+		return errors.New(ErrInvalid)
+	}
+	if !mw.IsAddressValid(entry.Address) {
+		return errors.New(ErrInvalidAddress)
+	}
+	return nil
+}
+
+func addressBookError(err error) error {
+	if err == storm.ErrAlreadyExists {
+		return errors.New(ErrExist)
+	}
+	return translateError(err)
+}
@@ -0,0 +1,124 @@
+package dcrlibwallet
+
+import (
+	"fmt"
+
+	"decred.org/dcrwallet/v2/errors"
+)
+
+const (
+	// DefaultGapLimit is the number of unused addresses after the last used
+	// address of an account that are watched and checked during address
+	// discovery, unless a larger limit is set with SetAccountGapLimit.
+	DefaultGapLimit int32 = 20
+
+	// maxGapLimit is the largest gap limit accepted by SetAccountGapLimit
+	// and DiscoverUsedAddresses.
+	maxGapLimit int32 = 1000
+)
+
+// GapLimit returns the gap limit of the wallet's accounts saved with
+// SetAccountGapLimit or DefaultGapLimit.
+func (wallet *Wallet) GapLimit() int32 {
+	return wallet.ReadInt32ConfigValueForKey(GapLimitConfigKey, DefaultGapLimit)
+}
+
+// SetAccountGapLimit saves the number of unused addresses after the last used
+// address of each account that are watched and checked during address
+// discovery. The limit is applied the next time the wallet is opened, an
+// open wallet keeps using its current limit until it is closed.
+func (wallet *Wallet) SetAccountGapLimit(limit int32) error {
+	if limit < DefaultGapLimit || limit > maxGapLimit {
+		return fmt.Errorf("%s: gap limit must be between %d and %d", ErrInvalid, DefaultGapLimit, maxGapLimit)
+	}
+
+	wallet.SetInt32ConfigValueForKey(GapLimitConfigKey, limit)
+	return nil
+}
+
+// DiscoverUsedAddresses runs address discovery from the genesis block using
+// gapLimit, which may be larger than the wallet's gap limit, to find used
+// addresses and accounts missed by the discovery done during sync. If any are
+// found, the new addresses are watched and the blocks are rescanned for
+// their transactions. Discovery progress is reported to the sync progress
+// listeners.
+func (mw *MultiWallet) DiscoverUsedAddresses(walletID int, privPass []byte, gapLimit int32) (bool, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return false, errors.New(ErrNotExist)
+	}
+
+	if gapLimit < wallet.GapLimit() || gapLimit > maxGapLimit {
+		return false, fmt.Errorf("%s: gap limit must be between %d and %d", ErrInvalid, wallet.GapLimit(), maxGapLimit)
+	}
+
+	if !mw.IsSynced() || mw.IsRescanning() {
+		return false, errors.New(ErrNotConnected)
+	}
+
+	netBackend, err := wallet.Internal().NetworkBackend()
+	if err != nil {
+		return false, errors.New(ErrNotConnected)
+	}
+
+	usageBefore, err := wallet.addressUsage()
+	if err != nil {
+		return false, translateError(err)
+	}
+
+	relock, err := wallet.unlockTemporarily(privPass)
+	if err != nil {
+		return false, err
+	}
+	defer relock()
+
+	mw.publishOneShotAddressDiscoveryProgress(walletID, 0)
+
+	ctx := wallet.shutdownContext()
+	err = wallet.Internal().DiscoverActiveAddresses(ctx, netBackend, &wallet.chainParams.GenesisHash, true, uint32(gapLimit))
+	if err != nil {
+		return false, translateError(err)
+	}
+
+	mw.publishOneShotAddressDiscoveryProgress(walletID, 100)
+
+	usageAfter, err := wallet.addressUsage()
+	if err != nil {
+		return false, translateError(err)
+	}
+	if usageAfter == usageBefore {
+		return false, nil
+	}
+
+	log.Infof("[%d] Address discovery with gap limit %d found new used addresses", walletID, gapLimit)
+
+	// Watch the newly found addresses and find their transactions.
+	err = wallet.Internal().LoadActiveDataFilters(ctx, netBackend, true)
+	if err != nil {
+		return true, translateError(err)
+	}
+
+	return true, mw.RescanBlocks(walletID)
+}
+
+// publishOneShotAddressDiscoveryProgress reports the progress of address
+// discovery done outside of a sync to the sync progress listeners.
+func (mw *MultiWallet) publishOneShotAddressDiscoveryProgress(walletID int, progress int32) {
+	report := &AddressDiscoveryProgressReport{
+		GeneralSyncProgress: &GeneralSyncProgress{
+			TotalSyncProgress: progress,
+		},
+		AddressDiscoveryProgress: progress,
+		WalletID:                 walletID,
+	}
+
+	for _, syncProgressListener := range mw.syncProgressListeners() {
+		syncProgressListener.OnAddressDiscoveryProgress(report)
+	}
+}
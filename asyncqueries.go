@@ -0,0 +1,74 @@
+package dcrlibwallet
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// AsyncQuery is returned by the async variants of the query methods. The
+// query's callback is called exactly once, with an ErrContextCanceled error if
+// the query is canceled before it completes.
+type AsyncQuery struct {
+	cancel context.CancelFunc
+}
+
+// Cancel stops the query. Canceling a completed query has no effect.
+func (query *AsyncQuery) Cancel() {
+	query.cancel()
+}
+
+type TransactionsQueryCallback interface {
+	OnTransactionsQueryResult(transactions string, err error)
+}
+
+type BalanceQueryCallback interface {
+	OnBalanceQueryResult(balance *Balance, err error)
+}
+
+// GetTransactionsAsync is like GetTransactions but reads the transactions in
+// a goroutine and passes the json encoded result to callback.
+func (wallet *Wallet) GetTransactionsAsync(offset, limit, txFilter int32, newestFirst bool, callback TransactionsQueryCallback) *AsyncQuery {
+	ctx, cancel := wallet.shutdownContextWithCancel()
+	go func() {
+		defer cancel()
+		transactions, err := wallet.GetTransactionsRawContext(ctx, offset, limit, txFilter, newestFirst)
+		callback.OnTransactionsQueryResult(transactionsQueryResult(transactions, err))
+	}()
+	return &AsyncQuery{cancel: cancel}
+}
+
+// GetTransactionsAsync is like GetTransactions but reads the transactions in
+// a goroutine and passes the json encoded result to callback.
+func (mw *MultiWallet) GetTransactionsAsync(offset, limit, txFilter int32, newestFirst bool, callback TransactionsQueryCallback) *AsyncQuery {
+	ctx, cancel := mw.contextWithShutdownCancel()
+	go func() {
+		defer cancel()
+		transactions, err := mw.GetTransactionsRawContext(ctx, offset, limit, txFilter, newestFirst)
+		callback.OnTransactionsQueryResult(transactionsQueryResult(transactions, err))
+	}()
+	return &AsyncQuery{cancel: cancel}
+}
+
+func transactionsQueryResult(transactions []Transaction, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(transactions)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// GetAccountBalanceAsync is like GetAccountBalance but computes the balance in
+// a goroutine and passes it to callback.
+func (wallet *Wallet) GetAccountBalanceAsync(accountNumber int32, callback BalanceQueryCallback) *AsyncQuery {
+	ctx, cancel := wallet.shutdownContextWithCancel()
+	go func() {
+		defer cancel()
+		balance, err := wallet.GetAccountBalanceContext(ctx, accountNumber)
+		callback.OnBalanceQueryResult(balance, err)
+	}()
+	return &AsyncQuery{cancel: cancel}
+}
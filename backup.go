@@ -0,0 +1,529 @@
+package dcrlibwallet
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"decred.org/dcrwallet/v2/errors"
+	"github.com/kevinburke/nacl"
+	"github.com/planetdecred/dcrlibwallet/walletdata"
+)
+
+const (
+	// backupVersion is the version of the archives written by
+	// BackupWalletData, archives of other versions are not restored.
+	backupVersion = 2
+
+	backupManifestName = "manifest.json"
+
+	// maxBackupEntryNameSize is the maximum size of the name of an entry in
+	// a backup archive, larger names are rejected as corrupt.
+	maxBackupEntryNameSize = 255
+)
+
+// errBackupArchiveInvalid is returned when a backup archive is not framed
+// as written by BackupWalletData.
+var errBackupArchiveInvalid = errors.New("invalid backup archive")
+
+// BackupManifest describes the wallet whose databases are in an archive
+// written by BackupWalletData.
+type BackupManifest struct {
+	Version               int    `json:"version"`
+	Network               string `json:"network"`
+	WalletID              int    `json:"wallet_id"`
+	WalletName            string `json:"wallet_name"`
+	DbDriver              string `json:"db_driver"`
+	PrivatePassphraseType int32  `json:"private_passphrase_type"`
+	HasDiscoveredAccounts bool   `json:"has_discovered_accounts"`
+	// CustomPublicPassphrase is true if the wallet database is encrypted
+	// with a public passphrase other than the default one.
+	CustomPublicPassphrase bool  `json:"custom_public_passphrase"`
+	CreatedAt              int64 `json:"created_at"`
+}
+
+// BackupWalletData writes the wallet database and the transactions index
+// database, along with a manifest, to an archive encrypted with
+// backupPassphrase at destinationPath and returns the size of the backup
+// file. The databases are copied from read transactions, so the wallet
+// remains usable while the backup is made, and are encrypted as they are
+// written rather than held in memory. The private keys in the wallet
+// database are also encrypted with the private passphrase.
+func (wallet *Wallet) BackupWalletData(destinationPath string, backupPassphrase []byte) (int64, error) {
+	if !wallet.WalletOpened() {
+		return 0, errors.New(ErrWalletNotLoaded)
+	}
+
+	if len(backupPassphrase) == 0 {
+		return 0, errors.New(ErrPassphraseRequired)
+	}
+
+	manifest := &BackupManifest{
+		Version:                backupVersion,
+		Network:                wallet.chainParams.Name,
+		WalletID:               wallet.ID,
+		WalletName:             wallet.Name,
+		DbDriver:               wallet.DbDriver,
+		PrivatePassphraseType:  wallet.PrivatePassphraseType,
+		HasDiscoveredAccounts:  wallet.HasDiscoveredAccounts,
+		CustomPublicPassphrase: wallet.customPubPass,
+		CreatedAt:              time.Now().Unix(),
+	}
+
+	key, err := naclLoadFromPass(backupPassphrase)
+	if err != nil {
+		return 0, err
+	}
+
+	// Write to a temporary file so that an incomplete backup is never left
+	// at destinationPath.
+	tempPath := destinationPath + ".tmp"
+	size, err := wallet.writeBackupFile(tempPath, manifest, key)
+	if err != nil {
+		os.Remove(tempPath)
+		return 0, translateError(err)
+	}
+
+	err = os.Rename(tempPath, destinationPath)
+	if err != nil {
+		os.Remove(tempPath)
+		return 0, err
+	}
+
+	log.Infof("[%d] Wallet data backed up to %s", wallet.ID, destinationPath)
+	return size, nil
+}
+
+// writeBackupFile writes the manifest and the wallet's databases to a backup
+// archive at path, encrypted with key, and returns the size of the file.
+func (wallet *Wallet) writeBackupFile(path string, manifest *BackupManifest, key nacl.Key) (int64, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	stream, err := newSecretStreamWriter(file, key)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeBackupEntry(stream, backupManifestName, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(manifest)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeBackupEntry(stream, walletDbName, wallet.loader.CopyDatabase)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeBackupEntry(stream, walletdata.DbName, wallet.walletDataDB.Copy)
+	if err != nil {
+		return 0, err
+	}
+
+	err = stream.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	err = file.Sync()
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// writeBackupEntry writes an entry named name, with the data written by
+// write, to the backup archive w. The name and data are written as
+// length-prefixed frames followed by an empty frame, so the size of the data
+// does not need to be known before it is written.
+func writeBackupEntry(w io.Writer, name string, write func(io.Writer) error) error {
+	err := writeBackupFrame(w, []byte(name))
+	if err != nil {
+		return err
+	}
+
+	err = write(backupFrameWriter{w})
+	if err != nil {
+		return err
+	}
+
+	return writeBackupFrame(w, nil)
+}
+
+func writeBackupFrame(w io.Writer, frame []byte) error {
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(frame)))
+	_, err := w.Write(size[:])
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(frame)
+	return err
+}
+
+// backupFrameWriter writes each non-empty write as a frame of a backup
+// archive entry.
+type backupFrameWriter struct {
+	w io.Writer
+}
+
+func (fw backupFrameWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil // an empty frame ends the entry
+	}
+
+	err := writeBackupFrame(fw.w, p)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// backupArchive is a backup written by BackupWalletData, opened for reading
+// its entries in order.
+type backupArchive struct {
+	file     *os.File
+	r        io.Reader
+	entry    *backupEntryReader
+	manifest *BackupManifest
+}
+
+// Next returns the name and data of the next entry in the archive, or io.EOF
+// if there are no more entries. The unread data of the previous entry is
+// skipped.
+func (archive *backupArchive) Next() (string, io.Reader, error) {
+	if archive.entry != nil {
+		_, err := io.Copy(ioutil.Discard, archive.entry)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	var size [4]byte
+	_, err := io.ReadFull(archive.r, size[:])
+	if err != nil {
+		return "", nil, err
+	}
+
+	nameSize := binary.BigEndian.Uint32(size[:])
+	if nameSize == 0 || nameSize > maxBackupEntryNameSize {
+		return "", nil, errBackupArchiveInvalid
+	}
+
+	name := make([]byte, nameSize)
+	_, err = io.ReadFull(archive.r, name)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	archive.entry = &backupEntryReader{r: archive.r}
+	return string(name), archive.entry, nil
+}
+
+func (archive *backupArchive) Close() error {
+	return archive.file.Close()
+}
+
+// backupEntryReader reads the frames of a backup archive entry.
+type backupEntryReader struct {
+	r         io.Reader
+	remaining uint32
+	done      bool
+}
+
+func (entry *backupEntryReader) Read(p []byte) (int, error) {
+	for entry.remaining == 0 {
+		if entry.done {
+			return 0, io.EOF
+		}
+
+		var size [4]byte
+		_, err := io.ReadFull(entry.r, size[:])
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		entry.remaining = binary.BigEndian.Uint32(size[:])
+		entry.done = entry.remaining == 0
+	}
+
+	if uint32(len(p)) > entry.remaining {
+		p = p[:entry.remaining]
+	}
+	n, err := entry.r.Read(p)
+	entry.remaining -= uint32(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// backupArchiveError returns err, or an ErrInvalid error if err is caused
+// by a corrupt or truncated backup archive.
+func backupArchiveError(err error) error {
+	switch err {
+	case errSecretStreamCorrupt, errBackupArchiveInvalid, io.ErrUnexpectedEOF:
+		return fmt.Errorf("%s: invalid backup archive: %v", ErrInvalid, err)
+	}
+	return err
+}
+
+// RestoreWalletData adds the wallet in a backup written by BackupWalletData,
+// which is decrypted with backupPassphrase, as walletName, or the name of the
+// backed up wallet if walletName is empty. If a wallet with that name exists,
+// ErrExist is returned unless overwrite is true, in which case the existing
+// wallet is closed and its databases are replaced. The transactions index in
+// the backup is only restored to the backed up wallet, other wallets index
+// their transactions again. The restored wallet is opened, unless its
+// database is encrypted with a custom public passphrase.
+func (mw *MultiWallet) RestoreWalletData(archivePath string, backupPassphrase []byte, walletName string, overwrite bool) (*Wallet, error) {
+	archive, err := openBackupArchive(archivePath, backupPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	manifest := archive.manifest
+	if manifest.Network != mw.chainParams.Name {
+		return nil, fmt.Errorf("%s: backup is for %s wallets", ErrInvalid, manifest.Network)
+	}
+
+	if walletName == "" {
+		walletName = manifest.WalletName
+	}
+
+	var existingWallet *Wallet
+	for _, wallet := range mw.wallets {
+		if wallet.Name == walletName {
+			existingWallet = wallet
+			break
+		}
+	}
+
+	if existingWallet != nil {
+		if !overwrite {
+			return nil, errors.New(ErrExist)
+		}
+		return existingWallet, mw.overwriteWalletData(existingWallet, archive)
+	}
+
+	if manifest.DbDriver != mw.dbDriver {
+		return nil, fmt.Errorf("%s: backup database driver %s is not supported", ErrInvalid, manifest.DbDriver)
+	}
+
+	wallet := &Wallet{
+		Name:                  walletName,
+		PrivatePassphraseType: manifest.PrivatePassphraseType,
+		IsRestored:            true,
+		HasDiscoveredAccounts: manifest.HasDiscoveredAccounts,
+	}
+
+	return mw.saveNewWallet(wallet, func() error {
+		err := extractBackupDatabases(archive, wallet.dataDir, manifest.WalletID == wallet.ID)
+		if err != nil {
+			return err
+		}
+
+		err = wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID), mw.walletConfigDeleteFn(wallet.ID))
+		if err != nil {
+			return err
+		}
+
+		if manifest.CustomPublicPassphrase {
+			return nil
+		}
+		return wallet.openWallet()
+	})
+}
+
+// openBackupArchive opens the backup at archivePath for decryption with
+// backupPassphrase and reads its manifest.
+func openBackupArchive(archivePath string, backupPassphrase []byte) (*backupArchive, error) {
+	key, err := naclLoadFromPass(backupPassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := readBackupManifest(file, key)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return archive, nil
+}
+
+// readBackupManifest reads the manifest at the start of the backup in file.
+// A manifest that fails to decrypt is reported as a wrong passphrase.
+func readBackupManifest(file *os.File, key nacl.Key) (*backupArchive, error) {
+	stream, err := newSecretStreamReader(file, key)
+	if err != nil {
+		return nil, backupArchiveError(err)
+	}
+
+	archive := &backupArchive{file: file, r: stream}
+	name, entry, err := archive.Next()
+	if err == errSecretStreamCorrupt {
+		return nil, errors.New(ErrInvalidPassphrase)
+	}
+	if err == io.EOF || err == nil && name != backupManifestName {
+		return nil, fmt.Errorf("%s: backup manifest not found", ErrInvalid)
+	}
+	if err != nil {
+		return nil, backupArchiveError(err)
+	}
+
+	manifest := new(BackupManifest)
+	err = json.NewDecoder(entry).Decode(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid backup manifest: %v", ErrInvalid, err)
+	}
+	if manifest.Version != backupVersion {
+		return nil, fmt.Errorf("%s: unsupported backup version %d", ErrInvalid, manifest.Version)
+	}
+
+	archive.manifest = manifest
+	return archive, nil
+}
+
+// overwriteWalletData closes wallet and replaces its databases with the ones
+// in archive.
+func (mw *MultiWallet) overwriteWalletData(wallet *Wallet, archive *backupArchive) error {
+	manifest := archive.manifest
+	if wallet.DbDriver != manifest.DbDriver {
+		return fmt.Errorf("%s: backup database driver %s does not match the wallet's %s", ErrInvalid, manifest.DbDriver, wallet.DbDriver)
+	}
+
+	err := mw.CloseWallet(wallet.ID)
+	if err != nil {
+		return err
+	}
+
+	err = extractBackupDatabases(archive, wallet.dataDir, manifest.WalletID == wallet.ID)
+	if err != nil {
+		return err
+	}
+
+	wallet.PrivatePassphraseType = manifest.PrivatePassphraseType
+	wallet.HasDiscoveredAccounts = manifest.HasDiscoveredAccounts
+	err = mw.db.Save(wallet)
+	if err != nil {
+		return translateError(err)
+	}
+
+	log.Infof("[%d] Wallet data restored from backup", wallet.ID)
+
+	if manifest.CustomPublicPassphrase {
+		return nil
+	}
+	return wallet.openWallet()
+}
+
+// extractBackupDatabases writes the databases in archive to dataDir. The
+// transactions index is only restored if withTxIndex is true, since its
+// records carry the ID of the backed up wallet, otherwise the existing index
+// is deleted so that it is rebuilt. All databases are extracted to temporary
+// files before any existing database is replaced, so the wallet's databases
+// are left as they were if the archive is incomplete.
+func extractBackupDatabases(archive *backupArchive, dataDir string, withTxIndex bool) error {
+	dbNames := []string{walletDbName}
+	if withTxIndex {
+		dbNames = append(dbNames, walletdata.DbName)
+	}
+	restored := make(map[string]bool, len(dbNames))
+	for _, dbName := range dbNames {
+		restored[dbName] = true
+	}
+
+	tempPaths := make(map[string]string, len(dbNames))
+	defer func() {
+		for _, tempPath := range tempPaths {
+			os.Remove(tempPath)
+		}
+	}()
+
+	// The whole archive is read, so the archive is known to be complete
+	// before the databases are replaced.
+	for {
+		name, entry, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return backupArchiveError(err)
+		}
+
+		if _, extracted := tempPaths[name]; extracted || !restored[name] {
+			continue
+		}
+
+		tempPath := filepath.Join(dataDir, name+".tmp")
+		tempPaths[name] = tempPath
+		err = extractFile(entry, tempPath)
+		if err != nil {
+			return backupArchiveError(err)
+		}
+	}
+
+	for _, dbName := range dbNames {
+		if _, extracted := tempPaths[dbName]; !extracted {
+			return fmt.Errorf("%s: %s not found in backup", ErrInvalid, dbName)
+		}
+	}
+
+	if !withTxIndex {
+		err := os.Remove(filepath.Join(dataDir, walletdata.DbName))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	for _, dbName := range dbNames {
+		err := os.Rename(tempPaths[dbName], filepath.Join(dataDir, dbName))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractFile(r io.Reader, path string) error {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, r)
+	if err != nil {
+		return err
+	}
+	return out.Sync()
+}
@@ -0,0 +1,78 @@
+package dcrlibwallet
+
+import (
+	"decred.org/dcrwallet/v2/errors"
+)
+
+func (mw *MultiWallet) AddBalanceNotificationListener(listener BalanceNotificationListener, uniqueIdentifier string) error {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	if _, ok := mw.balanceNotificationListeners[uniqueIdentifier]; ok {
+		return errors.New(ErrListenerAlreadyExist)
+	}
+
+	mw.balanceNotificationListeners[uniqueIdentifier] = listener
+	return nil
+}
+
+func (mw *MultiWallet) RemoveBalanceNotificationListener(uniqueIdentifier string) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	delete(mw.balanceNotificationListeners, uniqueIdentifier)
+}
+
+// checkBalanceChanges recomputes the balances of all accounts of wallet and
+// notifies the balance notification listeners of the accounts whose balance
+// changed since the last check. Balances depend on the number of
+// confirmations of outputs, so this is done for every attached block to report
+// immature outputs becoming spendable even if no transaction was received. The
+// first check only records the balances.
+func (mw *MultiWallet) checkBalanceChanges(wallet *Wallet) {
+	if !wallet.WalletOpened() {
+		return
+	}
+
+	accounts, err := wallet.GetAccountsRaw()
+	if err != nil {
+		log.Errorf("[%d] Error reading account balances: %v", wallet.ID, err)
+		return
+	}
+
+	wallet.accountBalancesMu.Lock()
+	previousBalances := wallet.accountBalances
+	wallet.accountBalances = make(map[int32]Balance, len(accounts.Acc))
+	var changed []*Account
+	for _, account := range accounts.Acc {
+		wallet.accountBalances[account.Number] = *account.Balance
+
+		if previousBalances == nil {
+			continue
+		}
+		if previous, ok := previousBalances[account.Number]; !ok || previous != *account.Balance {
+			changed = append(changed, account)
+		}
+	}
+	wallet.accountBalancesMu.Unlock()
+
+	if len(changed) == 0 {
+		return
+	}
+
+	// The listeners are called from separate goroutines, like the async tx
+	// and block listeners, so that they can use the wallet while the next
+	// block is processed.
+	mw.notificationListenersMu.RLock()
+	listeners := make([]BalanceNotificationListener, 0, len(mw.balanceNotificationListeners))
+	for _, listener := range mw.balanceNotificationListeners {
+		listeners = append(listeners, listener)
+	}
+	mw.notificationListenersMu.RUnlock()
+
+	for _, account := range changed {
+		for _, listener := range listeners {
+			go listener.OnBalanceChanged(wallet.ID, account.Number, account.Balance)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"decred.org/dcrwallet/v2/errors"
+	w "decred.org/dcrwallet/v2/wallet"
+)
+
+// maxBlockTimestampsRange is the largest number of blocks for which
+// GetBlockHeightsAndTimestamps returns timestamps, about 30 days of blocks.
+const maxBlockTimestampsRange = 8640
+
+// BlockTimestamp is the height and timestamp of a main chain block.
+type BlockTimestamp struct {
+	Height    int32 `json:"height"`
+	Timestamp int64 `json:"timestamp"`
+}
+
+// GetBlockHeightsAndTimestamps returns a json array of the heights and
+// timestamps of the main chain blocks from startHeight to endHeight,
+// inclusive. endHeight may not be above the wallet's best block and the range
+// may not contain more than 8640 blocks.
+func (wallet *Wallet) GetBlockHeightsAndTimestamps(startHeight, endHeight int32) (string, error) {
+	blocks, err := wallet.GetBlockHeightsAndTimestampsRaw(startHeight, endHeight)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(blocks)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+func (wallet *Wallet) GetBlockHeightsAndTimestampsRaw(startHeight, endHeight int32) ([]BlockTimestamp, error) {
+	if wallet.Internal() == nil {
+		return nil, errors.New(ErrWalletNotLoaded)
+	}
+
+	if startHeight < 0 || endHeight < startHeight {
+		return nil, fmt.Errorf("%s: invalid block range %d to %d", ErrInvalid, startHeight, endHeight)
+	}
+	if endHeight-startHeight >= maxBlockTimestampsRange {
+		return nil, fmt.Errorf("%s: block range may not contain more than %d blocks", ErrInvalid, maxBlockTimestampsRange)
+	}
+
+	_, bestBlock := wallet.Internal().MainChainTip(wallet.shutdownContext())
+	if endHeight > bestBlock {
+		return nil, fmt.Errorf("%s: end height %d is above the best block %d", ErrInvalid, endHeight, bestBlock)
+	}
+
+	return wallet.blockTimestamps(startHeight, endHeight)
+}
+
+// blockTimestamps reads the timestamps of the main chain blocks from
+// startHeight to endHeight from the wallet's block headers, the range is not
+// validated.
+func (wallet *Wallet) blockTimestamps(startHeight, endHeight int32) ([]BlockTimestamp, error) {
+	ctx := wallet.shutdownContext()
+	blocks := make([]BlockTimestamp, 0, endHeight-startHeight+1)
+	for height := startHeight; height <= endHeight; height++ {
+		info, err := wallet.Internal().BlockInfo(ctx, w.NewBlockIdentifierFromHeight(height))
+		if err != nil {
+			return nil, translateError(err)
+		}
+		blocks = append(blocks, BlockTimestamp{
+			Height:    height,
+			Timestamp: info.Timestamp,
+		})
+	}
+	return blocks, nil
+}
+
+// bestBlockInfo returns the height and timestamp of the wallet's best block.
+func (wallet *Wallet) bestBlockInfo() (*BlockInfo, error) {
+	if wallet.Internal() == nil {
+		return nil, errors.New(ErrWalletNotLoaded)
+	}
+
+	_, height := wallet.Internal().MainChainTip(wallet.shutdownContext())
+	blocks, err := wallet.blockTimestamps(height, height)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockInfo{Height: height, Timestamp: blocks[0].Timestamp}, nil
+}
@@ -1,6 +1,8 @@
 package dcrlibwallet
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -93,13 +95,18 @@ func (wallet *Wallet) SetVoteChoice(agendaID, choiceID, hash string, passphrase
 		ticketHash = hash
 	}
 
+	err := wallet.validateVoteChoice(agendaID, choiceID)
+	if err != nil {
+		return err
+	}
+
 	// The wallet will need to be unlocked to sign the API
 	// request(s) for setting this vote choice with the VSP.
-	err := wallet.UnlockWallet(passphrase)
+	relock, err := wallet.unlockTemporarily(passphrase)
 	if err != nil {
 		return translateError(err)
 	}
-	defer wallet.LockWallet()
+	defer relock()
 
 	ctx := wallet.shutdownContext()
 
@@ -143,14 +150,24 @@ func (wallet *Wallet) SetVoteChoice(agendaID, choiceID, hash string, passphrase
 		}
 	}()
 
-	// If a ticket hash is provided, set the specified vote choice with
-	// the VSP associated with the provided ticket. Otherwise, set the
-	// vote choice with the VSPs associated with all "votable" tickets.
+	err = wallet.updateVSPVotingPreferences(ctx, ticketHash, []w.AgendaChoice{newChoice}, nil, nil)
+	vspPreferenceUpdateSuccess = err == nil
+	return err
+}
+
+// updateVSPVotingPreferences sets the provided voting preferences with the
+// VSP associated with the ticket with ticketHash or, if ticketHash is nil,
+// with the VSPs associated with all unspent, unexpired tickets. Tickets not
+// registered with a VSP are skipped. The wallet must be unlocked to sign the
+// VSP requests.
+func (wallet *Wallet) updateVSPVotingPreferences(ctx context.Context, ticketHash *chainhash.Hash,
+	choices []w.AgendaChoice, tspendPolicy, treasuryPolicy map[string]string) error {
+
 	ticketHashes := make([]*chainhash.Hash, 0)
 	if ticketHash != nil {
 		ticketHashes = append(ticketHashes, ticketHash)
 	} else {
-		err = wallet.Internal().ForUnspentUnexpiredTickets(ctx, func(hash *chainhash.Hash) error {
+		err := wallet.Internal().ForUnspentUnexpiredTickets(ctx, func(hash *chainhash.Hash) error {
 			ticketHashes = append(ticketHashes, hash)
 			return nil
 		})
@@ -173,23 +190,82 @@ func (wallet *Wallet) SetVoteChoice(agendaID, choiceID, hash string, passphrase
 			continue // try next tHash
 		}
 
-		// Update the vote choice for the ticket with the associated VSP.
+		// Update the voting preferences for the ticket with the associated VSP.
 		vspClient, err := wallet.VSPClient(vspTicketInfo.Host, vspTicketInfo.PubKey)
-		if err != nil && firstErr == nil {
-			firstErr = err
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
 			continue // try next tHash
 		}
-		err = vspClient.SetVoteChoice(ctx, tHash, []w.AgendaChoice{newChoice}, nil, nil)
+		err = vspClient.SetVoteChoice(ctx, tHash, choices, tspendPolicy, treasuryPolicy)
 		if err != nil && firstErr == nil {
 			firstErr = err
 			continue // try next tHash
 		}
 	}
 
-	vspPreferenceUpdateSuccess = firstErr == nil
 	return firstErr
 }
 
+// validateVoteChoice returns an error if agendaID is not an agenda of the
+// current stake version or choiceID is not one of its choices.
+func (wallet *Wallet) validateVoteChoice(agendaID, choiceID string) error {
+	if wallet.chainParams.Deployments == nil {
+		return fmt.Errorf("%s: no agendas on %s", ErrInvalid, wallet.chainParams.Name)
+	}
+
+	for _, d := range wallet.chainParams.Deployments[voteVersion(wallet.chainParams)] {
+		if d.Vote.Id != agendaID {
+			continue
+		}
+
+		for _, choice := range d.Vote.Choices {
+			if choice.Id == choiceID {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: agenda %q has no choice %q", ErrInvalid, agendaID, choiceID)
+	}
+
+	return fmt.Errorf("%s: unknown agenda %q", ErrInvalid, agendaID)
+}
+
+// GetVoteChoices returns a JSON object mapping the ids of the agendas of the
+// current stake version to the choice saved in the wallet for each agenda.
+func (wallet *Wallet) GetVoteChoices() (string, error) {
+	choices, err := wallet.GetVoteChoicesRaw()
+	if err != nil {
+		return "", err
+	}
+
+	result, _ := json.Marshal(choices)
+	return string(result), nil
+}
+
+// GetVoteChoicesRaw returns the choice saved in the wallet for each agenda of
+// the current stake version. Agendas without a saved choice are reported as
+// "abstain".
+func (wallet *Wallet) GetVoteChoicesRaw() (map[string]string, error) {
+	ctx := wallet.shutdownContext()
+	choices, _, err := wallet.Internal().AgendaChoices(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	voteChoices := make(map[string]string)
+	if wallet.chainParams.Deployments != nil {
+		for _, d := range wallet.chainParams.Deployments[voteVersion(wallet.chainParams)] {
+			voteChoices[d.Vote.Id] = "abstain"
+		}
+	}
+	for i := range choices {
+		voteChoices[choices[i].AgendaID] = choices[i].ChoiceID
+	}
+
+	return voteChoices, nil
+}
+
 // AllVoteAgendas returns all agendas of all stake versions for the active
 // network and this version of the software. Also returns any saved vote
 // preferences for the agendas of the current stake version. Vote preferences
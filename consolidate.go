@@ -0,0 +1,129 @@
+package dcrlibwallet
+
+import (
+	"fmt"
+	"sort"
+
+	"decred.org/dcrwallet/v2/errors"
+	"decred.org/dcrwallet/v2/wallet/txrules"
+	"decred.org/dcrwallet/v2/wallet/txsizes"
+	"github.com/decred/dcrd/wire"
+)
+
+// maxConsolidationInputs is the largest number of outputs spent by a single
+// consolidation tx, it keeps the tx below the standard tx size limit.
+const maxConsolidationInputs = 500
+
+// ConsolidationResult is the result of ConsolidateUTXOs.
+type ConsolidationResult struct {
+	TxHash             string `json:"tx_hash"`
+	InputsConsolidated int32  `json:"inputs_consolidated"`
+	Amount             int64  `json:"amount"`
+	Fee                int64  `json:"fee"`
+}
+
+// ConsolidateUTXOs publishes a tx that spends up to maxInputs of the smallest
+// spendable outputs of account to a single output paying toAddress, or a new
+// internal address of account if toAddress is empty. The tx pays the default
+// relay fee rate. Immature outputs, outputs locked by tickets or by the user
+// and dust outputs are never spent, so this can be called repeatedly until
+// the account has few enough outputs.
+func (mw *MultiWallet) ConsolidateUTXOs(walletID int, privPass []byte, account int32, maxInputs int32, toAddress string) (*ConsolidationResult, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	if maxInputs < 2 || maxInputs > maxConsolidationInputs {
+		return nil, fmt.Errorf("%s: max inputs must be between 2 and %d", ErrInvalid, maxConsolidationInputs)
+	}
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return nil, errors.New(ErrNotExist)
+	}
+
+	utxoKeys, err := wallet.consolidationInputs(account, int(maxInputs))
+	if err != nil {
+		return nil, err
+	}
+	if len(utxoKeys) < 2 {
+		return nil, fmt.Errorf("%s: account has fewer than 2 outputs to consolidate", ErrInvalid)
+	}
+
+	if toAddress == "" {
+		address, err := wallet.Internal().NewChangeAddress(wallet.shutdownContext(), uint32(account))
+		if err != nil {
+			return nil, translateError(err)
+		}
+		toAddress = address.String()
+	}
+
+	tx, err := mw.NewUnsignedTxWithConfirmations(walletID, account, wallet.RequiredConfirmations())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.SetFeeRate(int64(txrules.DefaultRelayFeePerKb)); err != nil {
+		return nil, err
+	}
+	if err = tx.UseInputs(utxoKeys); err != nil {
+		return nil, err
+	}
+	if err = tx.AddSendDestination(toAddress, 0, true); err != nil {
+		return nil, err
+	}
+
+	msgTx, err := mw.broadcastAndIndexTx(tx, privPass)
+	if err != nil {
+		return nil, err
+	}
+
+	fee := int64(tx.unsignedTx.TotalInput) - msgTx.TxOut[0].Value
+	log.Infof("[%d] Consolidated %d outputs of account %d", walletID, len(utxoKeys), account)
+
+	return &ConsolidationResult{
+		TxHash:             msgTx.TxHash().String(),
+		InputsConsolidated: int32(len(utxoKeys)),
+		Amount:             msgTx.TxOut[0].Value,
+		Fee:                fee,
+	}, nil
+}
+
+// consolidationInputs returns the keys of up to maxInputs of the smallest
+// spendable outputs of account. Outputs of coinbase and stake txs that have
+// not matured, and outputs worth less than the fee to spend them, are skipped.
+func (wallet *Wallet) consolidationInputs(account int32, maxInputs int) ([]string, error) {
+	unspentOutputs, err := wallet.UnspentOutputsWithConfirmations(account, wallet.RequiredConfirmations())
+	if err != nil {
+		return nil, err
+	}
+
+	eligible := make([]*UnspentOutput, 0, len(unspentOutputs))
+	for _, utxo := range unspentOutputs {
+		// Outputs of stake txs mature like coinbase outputs.
+		needsMaturity := utxo.FromCoinbase || utxo.Tree == int32(wire.TxTreeStake)
+		if needsMaturity && blocksUntilMature(TxTypeCoinBase, utxo.Confirmations, wallet.chainParams) > 0 {
+			continue
+		}
+		// The consolidation tx pays the relay fee rate, see Consolidate.
+		if isDustAmount(utxo.Amount, txsizes.P2PKHPkScriptSize, txrules.DefaultRelayFeePerKb) {
+			continue
+		}
+		eligible = append(eligible, utxo)
+	}
+
+	sort.Slice(eligible, func(i, j int) bool {
+		return eligible[i].Amount < eligible[j].Amount
+	})
+	if len(eligible) > maxInputs {
+		eligible = eligible[:maxInputs]
+	}
+
+	utxoKeys := make([]string, len(eligible))
+	for i, utxo := range eligible {
+		utxoKeys[i] = utxo.OutputKey
+	}
+	return utxoKeys, nil
+}
@@ -1,9 +1,12 @@
 package dcrlibwallet
 
 import (
+	"encoding/json"
 	"fmt"
 
+	"decred.org/dcrwallet/v2/errors"
 	"decred.org/dcrwallet/v2/wallet"
+	"decred.org/dcrwallet/v2/wallet/udb"
 	"github.com/decred/dcrd/blockchain/stake/v4"
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/dcrutil/v4"
@@ -17,15 +20,33 @@ const BlockValid = 1 << 0
 
 // DecodeTransaction uses `walletTx.Hex` to retrieve detailed information for a transaction.
 func (w *Wallet) DecodeTransaction(walletTx *TxInfoFromWallet, netParams *chaincfg.Params) (*Transaction, error) {
-	msgTx, txFee, txSize, txFeeRate, err := txhelper.MsgTxFeeSizeRate(walletTx.Hex)
+	msgTx, _, txSize, _, err := txhelper.MsgTxFeeSizeRate(walletTx.Hex)
 	if err != nil {
 		return nil, err
 	}
 
-	inputs, totalWalletInput, totalWalletUnmixedInputs := w.decodeTxInputs(msgTx, walletTx.Inputs)
+	txFee := walletTxFee(msgTx, walletTx.Inputs)
+
+	inputs, _, totalWalletUnmixedInputs := w.decodeTxInputs(msgTx, walletTx.Inputs)
 	outputs, totalWalletOutput, totalWalletMixedOutputs, mixedOutputsCount := w.decodeTxOutputs(msgTx, netParams, walletTx.Outputs)
 
-	amount, direction := txhelper.TransactionAmountAndDirection(totalWalletInput, totalWalletOutput, int64(txFee))
+	isMixedTx, mixDenom, _ := txhelpers.IsMixTx(msgTx)
+
+	txType := txhelper.FormatTransactionType(wallet.TxTransactionType(msgTx))
+	if isMixedTx {
+		txType = txhelper.TxTypeMixed
+
+		mixChange := totalWalletOutput - totalWalletMixedOutputs
+		txFee = dcrutil.Amount(totalWalletUnmixedInputs - (totalWalletMixedOutputs + mixChange))
+	}
+
+	var txFeeRate int64
+	if txSize > 0 {
+		txFeeRate = int64(txFee) * 1000 / int64(txSize)
+	}
+
+	debits, credits := accountDebitsAndCredits(inputs, outputs)
+	amount, direction := txhelper.TransactionAmountAndDirection(txType, debits, credits, int64(txFee))
 
 	ssGenVersion, lastBlockValid, voteBits, ticketSpentHash := voteInfo(msgTx)
 
@@ -39,22 +60,13 @@ func (w *Wallet) DecodeTransaction(walletTx *TxInfoFromWallet, netParams *chainc
 		amount = msgTx.TxOut[0].Value
 	}
 
-	isMixedTx, mixDenom, _ := txhelpers.IsMixTx(msgTx)
-
-	txType := txhelper.FormatTransactionType(wallet.TxTransactionType(msgTx))
-	if isMixedTx {
-		txType = txhelper.TxTypeMixed
-
-		mixChange := totalWalletOutput - totalWalletMixedOutputs
-		txFee = dcrutil.Amount(totalWalletUnmixedInputs - (totalWalletMixedOutputs + mixChange))
-	}
-
 	return &Transaction{
 		WalletID:    walletTx.WalletID,
 		Hash:        msgTx.TxHash().String(),
 		Type:        txType,
 		Hex:         walletTx.Hex,
 		Timestamp:   walletTx.Timestamp,
+		FirstSeen:   walletTx.Timestamp,
 		BlockHeight: walletTx.BlockHeight,
 
 		MixDenomination: mixDenom,
@@ -64,7 +76,7 @@ func (w *Wallet) DecodeTransaction(walletTx *TxInfoFromWallet, netParams *chainc
 		LockTime: int32(msgTx.LockTime),
 		Expiry:   int32(msgTx.Expiry),
 		Fee:      int64(txFee),
-		FeeRate:  int64(txFeeRate),
+		FeeRate:  txFeeRate,
 		Size:     txSize,
 
 		Direction: direction,
@@ -79,6 +91,62 @@ func (w *Wallet) DecodeTransaction(walletTx *TxInfoFromWallet, netParams *chainc
 	}, nil
 }
 
+// walletTxFee returns the fee of a transaction that only spends outputs of the
+// wallet, computed from the amounts of the wallet's debits rather than the
+// input amounts of the serialized transaction, which are not committed to by
+// signatures. Transactions that spend outputs the wallet doesn't own report a
+// fee of 0 because the wallet did not pay it or cannot know it.
+func walletTxFee(msgTx *wire.MsgTx, walletInputs []*WalletInput) dcrutil.Amount {
+	if len(walletInputs) == 0 || len(walletInputs) != len(msgTx.TxIn) {
+		return 0
+	}
+
+	var fee int64
+	for _, input := range walletInputs {
+		fee += input.AmountIn
+	}
+	for _, output := range msgTx.TxOut {
+		fee -= output.Value
+	}
+
+	if fee < 0 {
+		return 0
+	}
+	return dcrutil.Amount(fee)
+}
+
+// accountDebitsAndCredits returns the amounts spent from and paid to each
+// wallet account by a transaction with the provided decoded inputs and
+// outputs.
+func accountDebitsAndCredits(inputs []*TxInput, outputs []*TxOutput) (debits, credits map[int32]int64) {
+	debits = make(map[int32]int64)
+	for _, input := range inputs {
+		if input.AccountNumber != -1 {
+			debits[input.AccountNumber] += input.Amount
+		}
+	}
+
+	credits = make(map[int32]int64)
+	for _, output := range outputs {
+		if output.AccountNumber != -1 {
+			credits[output.AccountNumber] += output.Amount
+		}
+	}
+
+	return debits, credits
+}
+
+// DecodeTransactionDirection returns the direction of the hex encoded
+// transaction from the wallet's point of view, one of the TxDirection
+// constants.
+func (wallet *Wallet) DecodeTransactionDirection(txHex string) (int32, error) {
+	tx, err := wallet.decodeRawTransaction(txHex)
+	if err != nil {
+		return TxDirectionInvalid, err
+	}
+	return tx.Direction, nil
+}
+
 func (wallet *Wallet) decodeTxInputs(mtx *wire.MsgTx, walletInputs []*WalletInput) (inputs []*TxInput, totalWalletInputs, totalWalletUnmixedInputs int64) {
 	inputs = make([]*TxInput, len(mtx.TxIn))
 	unmixedAccountNumber := wallet.ReadInt32ConfigValueForKey(AccountMixerUnmixedAccount, -1)
@@ -121,23 +189,7 @@ func (wallet *Wallet) decodeTxOutputs(mtx *wire.MsgTx, netParams *chaincfg.Param
 
 	for i, txOut := range mtx.TxOut {
 		// get address and script type for output
-		var address, scriptType string
-		if (txType == stake.TxTypeSStx) && (stake.IsStakeCommitmentTxOut(i)) {
-			addr, err := stake.AddrFromSStxPkScrCommitment(txOut.PkScript, netParams)
-			if err == nil {
-				address = addr.String()
-			}
-			scriptType = stdscript.STStakeSubmissionPubKeyHash.String()
-		} else {
-			// Ignore the error here since an error means the script
-			// couldn't parse and there is no additional information
-			// about it anyways.
-			scriptClass, addrs := stdscript.ExtractAddrs(txOut.Version, txOut.PkScript, netParams)
-			if len(addrs) > 0 {
-				address = addrs[0].String()
-			}
-			scriptType = scriptClass.String()
-		}
+		address, scriptType := outputAddressAndScriptType(txType, i, txOut, netParams)
 
 		output := &TxOutput{
 			Index:         int32(i),
@@ -172,6 +224,209 @@ func (wallet *Wallet) decodeTxOutputs(mtx *wire.MsgTx, netParams *chaincfg.Param
 	return
 }
 
+func outputAddressAndScriptType(txType stake.TxType, index int, txOut *wire.TxOut, netParams *chaincfg.Params) (address, scriptType string) {
+	if (txType == stake.TxTypeSStx) && (stake.IsStakeCommitmentTxOut(index)) {
+		addr, err := stake.AddrFromSStxPkScrCommitment(txOut.PkScript, netParams)
+		if err == nil {
+			address = addr.String()
+		}
+		scriptType = stdscript.STStakeSubmissionPubKeyHash.String()
+	} else {
+		// Ignore the error here since an error means the script
+		// couldn't parse and there is no additional information
+		// about it anyways.
+		scriptClass, addrs := stdscript.ExtractAddrs(txOut.Version, txOut.PkScript, netParams)
+		if len(addrs) > 0 {
+			address = addrs[0].String()
+		}
+		scriptType = scriptClass.String()
+	}
+	return
+}
+
+// DecodeRawTransaction decodes a hex encoded transaction and returns the
+// JSON encoded details. Wallet specific details such as the direction and
+// the accounts of inputs and outputs are not set, use the wallet's
+// DecodeRawTransaction method for those.
+func (mw *MultiWallet) DecodeRawTransaction(txHex string) (string, error) {
+	tx, err := decodeRawTransaction(txHex, mw.chainParams)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(tx)
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
+// DecodeRawTransaction decodes a hex encoded transaction and returns the
+// JSON encoded details, including the wallet's inputs and outputs. The
+// transaction does not have to be saved in the wallet.
+func (wallet *Wallet) DecodeRawTransaction(txHex string) (string, error) {
+	tx, err := wallet.decodeRawTransaction(txHex)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(tx)
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
+func (wallet *Wallet) decodeRawTransaction(txHex string) (*Transaction, error) {
+	if !wallet.WalletOpened() {
+		return decodeRawTransaction(txHex, wallet.chainParams)
+	}
+
+	msgTx, err := txhelpers.MsgTxFromHex(txHex)
+	if err != nil {
+		return nil, errors.E(errors.Invalid, "invalid transaction")
+	}
+
+	ctx := wallet.shutdownContext()
+	txHash := msgTx.TxHash()
+	txSummary, _, blockHash, err := wallet.Internal().TransactionSummary(ctx, &txHash)
+	if err == nil {
+		return wallet.decodeTransactionWithTxSummary(txSummary, blockHash)
+	}
+
+	// The transaction is not known to the wallet, look up wallet
+	// inputs and outputs individually.
+	walletTx := &TxInfoFromWallet{
+		WalletID:    wallet.ID,
+		Hex:         txHex,
+		BlockHeight: BlockHeightInvalid,
+	}
+
+	for i, txIn := range msgTx.TxIn {
+		prevOut := txIn.PreviousOutPoint
+		prevTxSummary, _, _, err := wallet.Internal().TransactionSummary(ctx, &prevOut.Hash)
+		if err != nil {
+			continue
+		}
+
+		for _, output := range prevTxSummary.MyOutputs {
+			if output.Index != prevOut.Index {
+				continue
+			}
+
+			accountNumber := int32(output.Account)
+			accountName, _ := wallet.AccountName(accountNumber)
+			walletTx.Inputs = append(walletTx.Inputs, &WalletInput{
+				Index:    int32(i),
+				AmountIn: int64(output.Amount),
+				WalletAccount: &WalletAccount{
+					AccountNumber: accountNumber,
+					AccountName:   accountName,
+				},
+			})
+		}
+	}
+
+	txType := txhelpers.DetermineTxType(msgTx, true)
+	for i, txOut := range msgTx.TxOut {
+		address, _ := outputAddressAndScriptType(txType, i, txOut, wallet.chainParams)
+		if address == "" {
+			continue
+		}
+
+		addressInfo, err := wallet.AddressInfo(address)
+		if err != nil || !addressInfo.IsMine {
+			continue
+		}
+
+		walletTx.Outputs = append(walletTx.Outputs, &WalletOutput{
+			Index:     int32(i),
+			AmountOut: txOut.Value,
+			Internal:  addressInfo.Branch == udb.InternalBranch,
+			Address:   address,
+			WalletAccount: &WalletAccount{
+				AccountNumber: int32(addressInfo.AccountNumber),
+				AccountName:   addressInfo.AccountName,
+			},
+		})
+	}
+
+	return wallet.DecodeTransaction(walletTx, wallet.chainParams)
+}
+
+// decodeRawTransaction decodes a hex encoded transaction without any
+// wallet specific details.
+func decodeRawTransaction(txHex string, netParams *chaincfg.Params) (*Transaction, error) {
+	msgTx, txFee, txSize, txFeeRate, err := txhelper.MsgTxFeeSizeRate(txHex)
+	if err != nil {
+		return nil, errors.E(errors.Invalid, "invalid transaction")
+	}
+
+	inputs := make([]*TxInput, len(msgTx.TxIn))
+	for i, txIn := range msgTx.TxIn {
+		inputs[i] = &TxInput{
+			PreviousTransactionHash:  txIn.PreviousOutPoint.Hash.String(),
+			PreviousTransactionIndex: int32(txIn.PreviousOutPoint.Index),
+			PreviousOutpoint:         txIn.PreviousOutPoint.String(),
+			Amount:                   txIn.ValueIn,
+			AccountNumber:            -1,
+		}
+	}
+
+	txType := txhelpers.DetermineTxType(msgTx, true)
+	outputs := make([]*TxOutput, len(msgTx.TxOut))
+	for i, txOut := range msgTx.TxOut {
+		address, scriptType := outputAddressAndScriptType(txType, i, txOut, netParams)
+		outputs[i] = &TxOutput{
+			Index:         int32(i),
+			Amount:        txOut.Value,
+			Version:       int32(txOut.Version),
+			ScriptType:    scriptType,
+			Address:       address,
+			AccountNumber: -1,
+		}
+	}
+
+	ssGenVersion, lastBlockValid, voteBits, ticketSpentHash := voteInfo(msgTx)
+	if txhelpers.IsSSRtx(msgTx) {
+		ticketSpentHash = msgTx.TxIn[0].PreviousOutPoint.Hash.String()
+	}
+
+	formattedTxType := txhelper.FormatTransactionType(wallet.TxTransactionType(msgTx))
+	isMixedTx, mixDenom, mixCount := txhelpers.IsMixTx(msgTx)
+	if isMixedTx {
+		formattedTxType = txhelper.TxTypeMixed
+	}
+
+	return &Transaction{
+		Hash:        msgTx.TxHash().String(),
+		Type:        formattedTxType,
+		Hex:         txHex,
+		BlockHeight: BlockHeightInvalid,
+
+		MixDenomination: mixDenom,
+		MixCount:        int32(mixCount),
+
+		Version:  int32(msgTx.Version),
+		LockTime: int32(msgTx.LockTime),
+		Expiry:   int32(msgTx.Expiry),
+		Fee:      int64(txFee),
+		FeeRate:  int64(txFeeRate),
+		Size:     txSize,
+
+		Direction: txhelper.TxDirectionInvalid,
+		Inputs:    inputs,
+		Outputs:   outputs,
+
+		VoteVersion:     int32(ssGenVersion),
+		LastBlockValid:  lastBlockValid,
+		VoteBits:        voteBits,
+		TicketSpentHash: ticketSpentHash,
+	}, nil
+}
+
 func voteInfo(msgTx *wire.MsgTx) (ssGenVersion uint32, lastBlockValid bool, voteBits string, ticketSpentHash string) {
 	if stake.IsSSGen(msgTx, true) {
 		ssGenVersion = stake.SSGenVersion(msgTx)
@@ -1,6 +1,11 @@
 package dcrlibwallet
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
 	"decred.org/dcrwallet/v2/errors"
 	"github.com/asdine/storm"
 )
@@ -41,21 +46,187 @@ const (
 	ErrIndexOutOfRange              = "err_index_out_of_range"
 	ErrNoMixableOutput              = "err_no_mixable_output"
 	ErrInvalidVoteBit               = "err_invalid_vote_bit"
+	ErrInvalidSeedLength            = "invalid_seed_length"
+	ErrAddressNotOwned              = "address_not_owned"
+	ErrAddressCannotSign            = "address_cannot_sign"
+	ErrOutputIsDust                 = "output_is_dust"
+	ErrSendMaxMultipleDestinations  = "send_max_multiple_destinations"
+	ErrZeroSpendableBalance         = "zero_spendable_balance"
+	ErrInvalidFeeRate               = "invalid_fee_rate"
+	ErrTxRejected                   = "tx_rejected"
+	ErrTxDoubleSpend                = "tx_double_spend"
+	ErrTxNoteTooLong                = "tx_note_too_long"
+	ErrNoFundsToSweep               = "no_funds_to_sweep"
+	ErrExchangeRateDisabled         = "exchange_rate_disabled"
+	ErrInvalidPublicPassphrase      = "invalid_public_passphrase"
+	ErrProtocolViolation            = "protocol_violation"
+	ErrDeadlineExceeded             = "deadline_exceeded"
+	ErrInvalidPort                  = "invalid_port"
+	ErrDuplicateDestination         = "duplicate_destination"
 )
 
-// todo, should update this method to translate more error kinds.
+// errorCodes maps each error code above to a stable number that is returned by
+// ErrorCode.  Numbers must never be reused or changed; new codes are appended.
+var errorCodes = map[string]int{
+	ErrInsufficientBalance:          1,
+	ErrInvalid:                      2,
+	ErrWalletLocked:                 3,
+	ErrWalletDatabaseInUse:          4,
+	ErrWalletNotLoaded:              5,
+	ErrWalletNotFound:               6,
+	ErrWalletNameExist:              7,
+	ErrReservedWalletName:           8,
+	ErrWalletIsRestored:             9,
+	ErrWalletIsWatchOnly:            10,
+	ErrUnusableSeed:                 11,
+	ErrPassphraseRequired:           12,
+	ErrInvalidPassphrase:            13,
+	ErrNotConnected:                 14,
+	ErrExist:                        15,
+	ErrNotExist:                     16,
+	ErrEmptySeed:                    17,
+	ErrInvalidAddress:               18,
+	ErrInvalidAuth:                  19,
+	ErrUnavailable:                  20,
+	ErrContextCanceled:              21,
+	ErrFailedPrecondition:           22,
+	ErrSyncAlreadyInProgress:        23,
+	ErrNoPeers:                      24,
+	ErrInvalidPeers:                 25,
+	ErrListenerAlreadyExist:         26,
+	ErrLoggerAlreadyRegistered:      27,
+	ErrLogRotatorAlreadyInitialized: 28,
+	ErrAddressDiscoveryNotDone:      29,
+	ErrChangingPassphrase:           30,
+	ErrSavingWallet:                 31,
+	ErrIndexOutOfRange:              32,
+	ErrNoMixableOutput:              33,
+	ErrInvalidVoteBit:               34,
+	ErrInvalidSeedLength:            35,
+	ErrAddressNotOwned:              36,
+	ErrAddressCannotSign:            37,
+	ErrOutputIsDust:                 38,
+	ErrSendMaxMultipleDestinations:  39,
+	ErrZeroSpendableBalance:         40,
+	ErrInvalidFeeRate:               41,
+	ErrTxRejected:                   42,
+	ErrTxDoubleSpend:                43,
+	ErrTxNoteTooLong:                44,
+	ErrNoFundsToSweep:               45,
+	ErrExchangeRateDisabled:         46,
+	ErrInvalidPublicPassphrase:      47,
+	ErrProtocolViolation:            48,
+	ErrDeadlineExceeded:             49,
+	ErrInvalidPort:                  50,
+	ErrDuplicateDestination:         51,
+}
+
+const (
+	// ErrorCodeNone is returned by ErrorCode for a nil error.
+	ErrorCodeNone = 0
+	// ErrorCodeUnknown is returned by ErrorCode for errors that do not
+	// carry one of the error codes defined in this file.
+	ErrorCodeUnknown = -1
+)
+
+// ErrorCode returns the stable numeric code of err so that callers on other
+// platforms can switch on it instead of matching error strings.  Errors that
+// are prefixed with an error code, e.g. "invalid: bad amount", report the
+// code of the prefix.
+func ErrorCode(err error) int {
+	if err == nil {
+		return ErrorCodeNone
+	}
+
+	message := translateError(err).Error()
+	if i := strings.Index(message, ":"); i != -1 {
+		message = message[:i]
+	}
+
+	if code, ok := errorCodes[message]; ok {
+		return code
+	}
+	return ErrorCodeUnknown
+}
+
+// translateError converts errors returned by dcrwallet and the wallets db to
+// the error codes defined in this file.  Errors that have no matching code are
+// returned unchanged.
 func translateError(err error) error {
-	if err, ok := err.(*errors.Error); ok {
-		switch err.Kind {
-		case errors.InsufficientBalance:
-			return errors.New(ErrInsufficientBalance)
-		case errors.NotExist, storm.ErrNotFound:
-			return errors.New(ErrNotExist)
-		case errors.Passphrase:
-			return errors.New(ErrInvalidPassphrase)
-		case errors.NoPeers:
-			return errors.New(ErrNoPeers)
-		}
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled):
+		return errors.New(ErrContextCanceled)
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%s: %v", ErrDeadlineExceeded, err)
+	case errors.Is(err, storm.ErrNotFound):
+		return errors.New(ErrNotExist)
+	}
+
+	// Failures to resolve or connect to a host.
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return fmt.Errorf("%s: %v", ErrUnavailable, err)
+	}
+
+	if _, ok := err.(*errors.Error); !ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, errors.InsufficientBalance):
+		return errors.New(ErrInsufficientBalance)
+	case errors.Is(err, errors.Exist):
+		return errors.New(ErrExist)
+	case errors.Is(err, errors.NotExist):
+		return errors.New(ErrNotExist)
+	case errors.Is(err, errors.Locked):
+		return errors.New(ErrWalletLocked)
+	case errors.Is(err, errors.Passphrase):
+		return errors.New(ErrInvalidPassphrase)
+	case errors.Is(err, errors.NoPeers):
+		return errors.New(ErrNoPeers)
+	case errors.Is(err, errors.Seed):
+		return errors.New(ErrUnusableSeed)
+	case errors.Is(err, errors.WatchingOnly):
+		return errors.New(ErrWalletIsWatchOnly)
+	case errors.Is(err, errors.Permission):
+		// Returned when an RPC server rejects the credentials.
+		return errors.New(ErrInvalidAuth)
+	case errors.Is(err, errors.IO):
+		// Returned when the connection to an RPC server or peer fails.
+		return fmt.Errorf("%s: %v", ErrUnavailable, err)
+	case errors.Is(err, errors.Invalid):
+		return fmt.Errorf("%s: %v", ErrInvalid, err)
+	case errors.Is(err, errors.Protocol):
+		// Returned when a peer violates the p2p protocol.
+		return fmt.Errorf("%s: %v", ErrProtocolViolation, err)
 	}
 	return err
 }
+
+// errorKind returns the kind of the outermost dcrwallet error in the chain of
+// err that has a kind, or errors.Other if there is none.
+func errorKind(err error) errors.Kind {
+	var e *errors.Error
+	for errors.As(err, &e) {
+		if e.Kind != errors.Other {
+			return e.Kind
+		}
+		err = e.Err
+	}
+	return errors.Other
+}
+
+// translatePublishError translates errors returned when publishing a
+// transaction, in addition to the errors translated by translateError.
+func translatePublishError(err error) error {
+	switch {
+	case errors.Is(err, errors.DoubleSpend):
+		return errors.New(ErrTxDoubleSpend)
+	case errors.Is(err, errors.Invalid), errors.Is(err, errors.Protocol):
+		return fmt.Errorf("%s: %v", ErrTxRejected, err)
+	}
+	return translateError(err)
+}
@@ -0,0 +1,57 @@
+package dcrlibwallet
+
+import (
+	"context"
+	"net"
+
+	"decred.org/dcrwallet/v2/errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Errors", func() {
+	Describe("ErrorCode", func() {
+		It("round trips every error code through translateError", func() {
+			for errCode, code := range errorCodes {
+				err := translateError(errors.New(errCode))
+				Expect(err.Error()).To(Equal(errCode))
+				Expect(ErrorCode(err)).To(Equal(code))
+			}
+		})
+
+		It("assigns a unique number to every error code", func() {
+			seen := make(map[int]string)
+			for errCode, code := range errorCodes {
+				Expect(seen).ToNot(HaveKey(code), errCode)
+				seen[code] = errCode
+			}
+		})
+
+		It("translates dcrwallet error kinds", func() {
+			Expect(ErrorCode(errors.E(errors.Locked))).To(Equal(errorCodes[ErrWalletLocked]))
+			Expect(ErrorCode(errors.E(errors.Passphrase))).To(Equal(errorCodes[ErrInvalidPassphrase]))
+			Expect(ErrorCode(errors.E(errors.Seed))).To(Equal(errorCodes[ErrUnusableSeed]))
+			Expect(ErrorCode(errors.E(errors.Invalid, "bad input"))).To(Equal(errorCodes[ErrInvalid]))
+		})
+
+		It("translates wrapped context and network errors", func() {
+			Expect(ErrorCode(errors.E(errors.Op("spv.Run"), context.Canceled))).To(Equal(errorCodes[ErrContextCanceled]))
+			Expect(ErrorCode(errors.E(errors.Op("spv.Run"), context.DeadlineExceeded))).To(Equal(errorCodes[ErrDeadlineExceeded]))
+			Expect(ErrorCode(&net.DNSError{Err: "no such host", Name: "example"})).To(Equal(errorCodes[ErrUnavailable]))
+			Expect(ErrorCode(errors.E(errors.Protocol, "bad header"))).To(Equal(errorCodes[ErrProtocolViolation]))
+		})
+
+		It("reports unknown errors", func() {
+			Expect(ErrorCode(nil)).To(Equal(ErrorCodeNone))
+			Expect(ErrorCode(errors.New("something else"))).To(Equal(ErrorCodeUnknown))
+		})
+	})
+
+	Describe("errorKind", func() {
+		It("finds the kind of wrapped dcrwallet errors", func() {
+			err := errors.E(errors.Op("spv.Run"), errors.E(errors.Protocol, "bad header"))
+			Expect(errorKind(err)).To(Equal(errors.Protocol))
+			Expect(errorKind(errors.New("plain"))).To(Equal(errors.Other))
+		})
+	})
+})
@@ -0,0 +1,345 @@
+package dcrlibwallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"decred.org/dcrwallet/v2/errors"
+)
+
+const (
+	// Exchange rate sources. ExchangeRateSourceNone disables exchange rate
+	// requests and is the default.
+	ExchangeRateSourceNone    = "none"
+	ExchangeRateSourceBittrex = "bittrex"
+	ExchangeRateSourceBinance = "binance"
+
+	// Currency pairs supported by GetCurrentExchangeRate. The DCR-USD rate
+	// is computed from the DCR-BTC and BTC-USD rates.
+	CurrencyPairDCRBTC = "DCR-BTC"
+	CurrencyPairBTCUSD = "BTC-USD"
+	CurrencyPairDCRUSD = "DCR-USD"
+
+	exchangeRateRequestTimeout = 30 * time.Second
+
+	// minExchangeRateTickerInterval is the shortest interval accepted by
+	// StartExchangeRateTicker, to avoid hitting the sources' rate limits.
+	minExchangeRateTickerInterval = 60
+)
+
+// ExchangeRate is the last rate fetched for a currency pair. LastUpdated is
+// the time the rate was fetched, rates fetched long ago are returned if the
+// source cannot be reached.
+type ExchangeRate struct {
+	CurrencyPair string  `json:"currency_pair"`
+	Rate         float64 `json:"rate"`
+	Source       string  `json:"source"`
+	LastUpdated  int64   `json:"last_updated"`
+}
+
+// exchangeRateMarkets maps each source to the market names of the currency
+// pairs fetched from it.
+var exchangeRateMarkets = map[string]map[string]string{
+	ExchangeRateSourceBittrex: {
+		CurrencyPairDCRBTC: "DCR-BTC",
+		CurrencyPairBTCUSD: "BTC-USD",
+	},
+	ExchangeRateSourceBinance: {
+		CurrencyPairDCRBTC: "DCRBTC",
+		CurrencyPairBTCUSD: "BTCUSDT",
+	},
+}
+
+var exchangeRateHttpClient = &http.Client{Timeout: exchangeRateRequestTimeout}
+
+func (mw *MultiWallet) AddExchangeRateListener(listener ExchangeRateListener, uniqueIdentifier string) error {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	if _, ok := mw.exchangeRateListeners[uniqueIdentifier]; ok {
+		return errors.New(ErrListenerAlreadyExist)
+	}
+
+	mw.exchangeRateListeners[uniqueIdentifier] = listener
+	return nil
+}
+
+func (mw *MultiWallet) RemoveExchangeRateListener(uniqueIdentifier string) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	delete(mw.exchangeRateListeners, uniqueIdentifier)
+}
+
+func (mw *MultiWallet) publishExchangeRateUpdated(rate *ExchangeRate) {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	for _, listener := range mw.exchangeRateListeners {
+		listener.OnExchangeRateUpdated(rate)
+	}
+}
+
+// ExchangeRateSource returns the source of exchange rates saved with
+// SetExchangeRateSource.
+func (mw *MultiWallet) ExchangeRateSource() string {
+	source := mw.ReadStringConfigValueForKey(ExchangeRateSourceConfigKey)
+	if _, ok := exchangeRateMarkets[source]; !ok {
+		return ExchangeRateSourceNone
+	}
+	return source
+}
+
+// SetExchangeRateSource saves the source used to fetch exchange rates.
+// ExchangeRateSourceNone stops the exchange rate ticker and disables
+// exchange rate requests.
+func (mw *MultiWallet) SetExchangeRateSource(source string) error {
+	if _, ok := exchangeRateMarkets[source]; !ok && source != ExchangeRateSourceNone {
+		return fmt.Errorf("%s: unsupported exchange rate source %q", ErrInvalid, source)
+	}
+
+	mw.SetStringConfigValueForKey(ExchangeRateSourceConfigKey, source)
+	if source == ExchangeRateSourceNone {
+		mw.StopExchangeRateTicker()
+	}
+	return nil
+}
+
+// GetCurrentExchangeRate returns the json encoded rate of currencyPair from
+// the selected exchange rate source. The last fetched rate is returned if the
+// source cannot be reached.
+func (mw *MultiWallet) GetCurrentExchangeRate(currencyPair string) (string, error) {
+	rate, err := mw.GetCurrentExchangeRateRaw(currencyPair)
+	if err != nil {
+		return "", err
+	}
+
+	result, _ := json.Marshal(rate)
+	return string(result), nil
+}
+
+func (mw *MultiWallet) GetCurrentExchangeRateRaw(currencyPair string) (*ExchangeRate, error) {
+	return mw.currentExchangeRate(mw.shutdownCtx, currencyPair)
+}
+
+// currentExchangeRate is like GetCurrentExchangeRateRaw but the rates are
+// requested with ctx.
+func (mw *MultiWallet) currentExchangeRate(ctx context.Context, currencyPair string) (*ExchangeRate, error) {
+	source := mw.ExchangeRateSource()
+	if source == ExchangeRateSourceNone {
+		return nil, errors.New(ErrExchangeRateDisabled)
+	}
+
+	if currencyPair == CurrencyPairDCRUSD {
+		dcrBTC, err := mw.currentExchangeRate(ctx, CurrencyPairDCRBTC)
+		if err != nil {
+			return nil, err
+		}
+		btcUSD, err := mw.currentExchangeRate(ctx, CurrencyPairBTCUSD)
+		if err != nil {
+			return nil, err
+		}
+		return dcrUSDRate(dcrBTC, btcUSD), nil
+	}
+
+	market, ok := exchangeRateMarkets[source][currencyPair]
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported currency pair %q", ErrInvalid, currencyPair)
+	}
+
+	rate, err := fetchExchangeRate(ctx, source, market)
+	if err != nil {
+		log.Warnf("Error fetching %s rate from %s: %v", currencyPair, source, err)
+		if cached := mw.cachedExchangeRate(source, currencyPair); cached != nil {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("%s: %v", ErrUnavailable, err)
+	}
+
+	exchangeRate := &ExchangeRate{
+		CurrencyPair: currencyPair,
+		Rate:         rate,
+		Source:       source,
+		LastUpdated:  time.Now().Unix(),
+	}
+	mw.cacheExchangeRate(exchangeRate)
+
+	return exchangeRate, nil
+}
+
+// dcrUSDRate computes the DCR-USD rate from the DCR-BTC and BTC-USD rates,
+// the rate is as old as the oldest of both rates.
+func dcrUSDRate(dcrBTC, btcUSD *ExchangeRate) *ExchangeRate {
+	lastUpdated := dcrBTC.LastUpdated
+	if btcUSD.LastUpdated < lastUpdated {
+		lastUpdated = btcUSD.LastUpdated
+	}
+
+	return &ExchangeRate{
+		CurrencyPair: CurrencyPairDCRUSD,
+		Rate:         dcrBTC.Rate * btcUSD.Rate,
+		Source:       dcrBTC.Source,
+		LastUpdated:  lastUpdated,
+	}
+}
+
+// StartExchangeRateTicker fetches the rates of all supported currency pairs
+// every intervalSeconds and notifies the exchange rate listeners of the new
+// rates until StopExchangeRateTicker is called or the exchange rate source
+// is set to ExchangeRateSourceNone.
+func (mw *MultiWallet) StartExchangeRateTicker(intervalSeconds int) error {
+	if intervalSeconds < minExchangeRateTickerInterval {
+		return fmt.Errorf("%s: interval must be at least %d seconds", ErrInvalid, minExchangeRateTickerInterval)
+	}
+
+	if mw.ExchangeRateSource() == ExchangeRateSourceNone {
+		return errors.New(ErrExchangeRateDisabled)
+	}
+
+	mw.exchangeRateMu.Lock()
+	defer mw.exchangeRateMu.Unlock()
+
+	if mw.cancelExchangeRateTicker != nil {
+		mw.cancelExchangeRateTicker()
+	}
+
+	ctx, cancel := mw.contextWithShutdownCancel()
+	mw.cancelExchangeRateTicker = cancel
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(intervalSeconds) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			mw.refreshExchangeRates(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// StopExchangeRateTicker stops the exchange rate ticker if it is running.
+func (mw *MultiWallet) StopExchangeRateTicker() {
+	mw.exchangeRateMu.Lock()
+	defer mw.exchangeRateMu.Unlock()
+
+	if mw.cancelExchangeRateTicker != nil {
+		mw.cancelExchangeRateTicker()
+		mw.cancelExchangeRateTicker = nil
+	}
+}
+
+// refreshExchangeRates fetches the rates of all supported currency pairs and
+// notifies the exchange rate listeners. The DCR-USD rate is computed from the
+// fetched rates rather than fetching them again.
+func (mw *MultiWallet) refreshExchangeRates(ctx context.Context) {
+	rates := make(map[string]*ExchangeRate)
+	for _, currencyPair := range []string{CurrencyPairDCRBTC, CurrencyPairBTCUSD} {
+		rate, err := mw.currentExchangeRate(ctx, currencyPair)
+		if err != nil {
+			log.Errorf("Error refreshing %s exchange rate: %v", currencyPair, err)
+			continue
+		}
+		rates[currencyPair] = rate
+		mw.publishExchangeRateUpdated(rate)
+	}
+
+	dcrBTC, btcUSD := rates[CurrencyPairDCRBTC], rates[CurrencyPairBTCUSD]
+	if dcrBTC != nil && btcUSD != nil {
+		mw.publishExchangeRateUpdated(dcrUSDRate(dcrBTC, btcUSD))
+	}
+}
+
+func (mw *MultiWallet) cachedExchangeRates() map[string]*ExchangeRate {
+	var rates map[string]*ExchangeRate
+	_ = mw.ReadUserConfigValue(ExchangeRateCacheConfigKey, &rates) // errors are logged
+	if rates == nil {
+		rates = make(map[string]*ExchangeRate)
+	}
+	return rates
+}
+
+func (mw *MultiWallet) cachedExchangeRate(source, currencyPair string) *ExchangeRate {
+	mw.exchangeRateMu.Lock()
+	defer mw.exchangeRateMu.Unlock()
+
+	return mw.cachedExchangeRates()[source+"_"+currencyPair]
+}
+
+func (mw *MultiWallet) cacheExchangeRate(rate *ExchangeRate) {
+	mw.exchangeRateMu.Lock()
+	defer mw.exchangeRateMu.Unlock()
+
+	rates := mw.cachedExchangeRates()
+	rates[rate.Source+"_"+rate.CurrencyPair] = rate
+	mw.SaveUserConfigValue(ExchangeRateCacheConfigKey, rates)
+}
+
+// fetchExchangeRate returns the last trade price of market from source. The
+// request is canceled if ctx is canceled.
+func fetchExchangeRate(ctx context.Context, source, market string) (float64, error) {
+	var url string
+	var price func(respBytes []byte) (string, error)
+
+	switch source {
+	case ExchangeRateSourceBittrex:
+		url = "https://api.bittrex.com/v3/markets/" + market + "/ticker"
+		price = func(respBytes []byte) (string, error) {
+			var ticker struct {
+				LastTradeRate string `json:"lastTradeRate"`
+			}
+			err := json.Unmarshal(respBytes, &ticker)
+			return ticker.LastTradeRate, err
+		}
+	case ExchangeRateSourceBinance:
+		url = "https://api.binance.com/api/v3/ticker/price?symbol=" + market
+		price = func(respBytes []byte) (string, error) {
+			var ticker struct {
+				Price string `json:"price"`
+			}
+			err := json.Unmarshal(respBytes, &ticker)
+			return ticker.Price, err
+		}
+	default:
+		return 0, fmt.Errorf("unsupported exchange rate source %q", source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := exchangeRateHttpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%d response from %s: %s", resp.StatusCode, source, string(respBytes))
+	}
+
+	priceStr, err := price(respBytes)
+	if err != nil {
+		return 0, err
+	}
+
+	rate, err := strconv.ParseFloat(priceStr, 64)
+	if err != nil || rate <= 0 {
+		return 0, fmt.Errorf("invalid %s price from %s: %q", market, source, priceStr)
+	}
+	return rate, nil
+}
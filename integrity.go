@@ -0,0 +1,194 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"decred.org/dcrwallet/v2/errors"
+	w "decred.org/dcrwallet/v2/wallet"
+	"github.com/asdine/storm/q"
+)
+
+const (
+	// integrityHeaderChainDepth is the number of blocks below the tip block
+	// whose headers are checked by VerifyWalletIntegrity.
+	integrityHeaderChainDepth = 256
+
+	// Repairs recommended by VerifyWalletIntegrity.
+	IntegrityFixNone    = "none"
+	IntegrityFixReindex = "reindex"
+	IntegrityFixRescan  = "rescan"
+)
+
+// IntegrityProblem describes an inconsistency found by VerifyWalletIntegrity.
+type IntegrityProblem struct {
+	Check       string `json:"check"`
+	Description string `json:"description"`
+}
+
+// IntegrityReport is the result of VerifyWalletIntegrity. RecommendedFix is
+// one of the IntegrityFix constants, a reindex can be applied with
+// FixTxIndexInconsistencies and a rescan with MultiWallet.RescanBlocks.
+type IntegrityReport struct {
+	WalletID       int                 `json:"wallet_id"`
+	Problems       []*IntegrityProblem `json:"problems"`
+	RecommendedFix string              `json:"recommended_fix"`
+}
+
+func (report *IntegrityReport) addProblem(check, fix, format string, args ...interface{}) {
+	report.Problems = append(report.Problems, &IntegrityProblem{
+		Check:       check,
+		Description: fmt.Sprintf(format, args...),
+	})
+
+	// A rescan also reindexes the wallet transactions.
+	if report.RecommendedFix != IntegrityFixRescan && fix != IntegrityFixNone {
+		report.RecommendedFix = fix
+	}
+}
+
+// VerifyWalletIntegrity checks the wallet database, accounts, tx index and
+// block headers for inconsistencies without changing anything, and returns
+// the problems found as a json encoded IntegrityReport.
+func (wallet *Wallet) VerifyWalletIntegrity() (string, error) {
+	report, err := wallet.VerifyWalletIntegrityRaw()
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(report)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// VerifyWalletIntegrityRaw is like VerifyWalletIntegrity but returns the
+// report as a struct.
+func (wallet *Wallet) VerifyWalletIntegrityRaw() (*IntegrityReport, error) {
+	report := &IntegrityReport{
+		WalletID:       wallet.ID,
+		Problems:       []*IntegrityProblem{},
+		RecommendedFix: IntegrityFixNone,
+	}
+
+	if !wallet.WalletOpened() {
+		report.addProblem("wallet_db", IntegrityFixNone, "wallet database is not open")
+		return report, nil
+	}
+
+	wallet.verifyAccounts(report)
+	wallet.verifyTxIndex(report)
+	wallet.verifyHeaderChain(report)
+
+	return report, nil
+}
+
+// FixTxIndexInconsistencies rebuilds the tx index if VerifyWalletIntegrity
+// finds that it is inconsistent with the wallet and returns the report of the
+// problems found before the repair. Problems that require a rescan are not
+// fixed.
+func (wallet *Wallet) FixTxIndexInconsistencies() (string, error) {
+	report, err := wallet.VerifyWalletIntegrityRaw()
+	if err != nil {
+		return "", err
+	}
+
+	if report.RecommendedFix == IntegrityFixReindex {
+		if wallet.rescanning != nil && wallet.rescanning() {
+			return "", errors.New(ErrSyncAlreadyInProgress)
+		}
+
+		log.Infof("[%d] Rebuilding inconsistent tx index", wallet.ID)
+		err = wallet.RebuildTxIndex(nil)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	result, err := json.Marshal(report)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+func (wallet *Wallet) verifyAccounts(report *IntegrityReport) {
+	ctx := wallet.shutdownContext()
+
+	accounts, err := wallet.Internal().Accounts(ctx)
+	if err != nil {
+		report.addProblem("accounts", IntegrityFixNone, "error reading accounts: %v", err)
+		return
+	}
+
+	for _, account := range accounts.Accounts {
+		_, err = wallet.Internal().AccountName(ctx, account.AccountNumber)
+		if err != nil {
+			report.addProblem("accounts", IntegrityFixNone, "error reading account %d: %v",
+				account.AccountNumber, err)
+		}
+	}
+}
+
+func (wallet *Wallet) verifyTxIndex(report *IntegrityReport) {
+	bestBlock := wallet.GetBestBlock()
+
+	indexHeight, err := wallet.walletDataDB.LastIndexPoint()
+	if err != nil {
+		report.addProblem("tx_index", IntegrityFixReindex, "error reading tx index height: %v", err)
+		return
+	}
+
+	if indexHeight > bestBlock {
+		report.addProblem("tx_index", IntegrityFixReindex, "tx index height %d is above the wallet best block %d",
+			indexHeight, bestBlock)
+	} else if indexHeight < bestBlock && wallet.synced {
+		report.addProblem("tx_index", IntegrityFixReindex, "tx index height %d is below the wallet best block %d",
+			indexHeight, bestBlock)
+	}
+
+	var txs []Transaction
+	err = wallet.walletDataDB.Find(q.Gt("BlockHeight", bestBlock), &txs)
+	if err != nil {
+		report.addProblem("tx_index", IntegrityFixReindex, "error reading indexed transactions: %v", err)
+		return
+	}
+	if len(txs) > 0 {
+		report.addProblem("tx_index", IntegrityFixReindex, "%d indexed transaction(s) are mined above the wallet best block %d",
+			len(txs), bestBlock)
+	}
+}
+
+func (wallet *Wallet) verifyHeaderChain(report *IntegrityReport) {
+	ctx := wallet.shutdownContext()
+
+	hash, height := wallet.Internal().MainChainTip(ctx)
+	for depth := 0; depth < integrityHeaderChainDepth && height > 0; depth++ {
+		header, err := wallet.Internal().BlockHeader(ctx, &hash)
+		if err != nil {
+			report.addProblem("headers", IntegrityFixRescan, "error reading header of block %s: %v", hash, err)
+			return
+		}
+
+		if int32(header.Height) != height {
+			report.addProblem("headers", IntegrityFixRescan, "block %s has height %d, expected %d",
+				hash, header.Height, height)
+			return
+		}
+
+		blockInfo, err := wallet.Internal().BlockInfo(ctx, w.NewBlockIdentifierFromHeight(height))
+		if err != nil {
+			report.addProblem("headers", IntegrityFixRescan, "error reading main chain block at height %d: %v", height, err)
+			return
+		}
+		if blockInfo.Hash != hash {
+			report.addProblem("headers", IntegrityFixRescan, "main chain block at height %d is %s, expected %s",
+				height, blockInfo.Hash, hash)
+			return
+		}
+
+		hash = header.PrevBlock
+		height--
+	}
+}
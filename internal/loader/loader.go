@@ -7,6 +7,7 @@ package loader
 
 import (
 	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -86,6 +87,14 @@ func (l *Loader) SetDatabaseDriver(driver string) {
 	l.dbDriver = driver
 }
 
+// SetGapLimit sets the gap limit used by the wallets opened or created after
+// this returns.
+func (l *Loader) SetGapLimit(gapLimit uint32) {
+	l.mu.Lock()
+	l.gapLimit = gapLimit
+	l.mu.Unlock()
+}
+
 // onLoaded executes each added callback and prevents loader from loading any
 // additional wallets.  Requires mutex to be locked.
 func (l *Loader) onLoaded(w *wallet.Wallet, db wallet.DB) {
@@ -408,6 +417,32 @@ func (l *Loader) UnloadWallet() error {
 	return nil
 }
 
+// CopyDatabase writes a copy of the loaded wallet's database to w. The copy is
+// made from a single read transaction, so the wallet remains usable.
+func (l *Loader) CopyDatabase(w io.Writer) error {
+	const op errors.Op = "loader.CopyDatabase"
+
+	defer l.mu.Unlock()
+	l.mu.Lock()
+
+	if l.wallet == nil {
+		return errors.E(op, errors.Invalid, "wallet is unopened")
+	}
+
+	// The database returned by wallet.OpenDB wraps a walletdb.DB, which
+	// implements Copy.
+	db, ok := l.db.(interface{ Copy(io.Writer) error })
+	if !ok {
+		return errors.E(op, errors.Invalid, "database does not support copying")
+	}
+
+	err := db.Copy(w)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	return nil
+}
+
 // NetworkBackend returns the associated wallet network backend, if any, and a
 // bool describing whether a non-nil network backend was set.
 func (l *Loader) NetworkBackend() (n wallet.NetworkBackend, ok bool) {
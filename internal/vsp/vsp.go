@@ -331,7 +331,7 @@ func (c *Client) SetVoteChoice(ctx context.Context, hash *chainhash.Hash,
 
 	// Check treasury policies.
 	for newKey, newChoice := range treasuryPolicy {
-		vspChoice, ok := status.TSpendPolicy[newKey]
+		vspChoice, ok := status.TreasuryPolicy[newKey]
 		if !ok {
 			update = true
 			break
@@ -6,7 +6,10 @@
 package dcrlibwallet
 
 import (
+	"bytes"
+	"fmt"
 	"os"
+	"sync"
 
 	"decred.org/dcrwallet/v2/errors"
 	"decred.org/dcrwallet/v2/p2p"
@@ -22,16 +25,70 @@ import (
 	"github.com/planetdecred/dcrlibwallet/spv"
 )
 
-// logWriter implements an io.Writer that outputs to both standard output and
-// the write-end pipe of an initialized log rotator.
+// LogListener receives a copy of every log line written at or above the
+// level it was registered with.
+type LogListener interface {
+	OnLogMessage(level, message string)
+}
+
+type logListener struct {
+	listener LogListener
+	minLevel slog.Level
+}
+
+var (
+	logListenersMu sync.RWMutex
+	logListeners   = make(map[string]*logListener)
+)
+
+// logWriter implements an io.Writer that outputs to standard output, the
+// write-end pipe of an initialized log rotator and any registered log
+// listeners.
 type logWriter struct{}
 
 func (logWriter) Write(p []byte) (n int, err error) {
 	os.Stdout.Write(p)
-	logRotator.Write(p)
+
+	logRotatorMu.Lock()
+	if logRotator != nil {
+		logRotator.Write(p)
+	}
+	logRotatorMu.Unlock()
+
+	logListenersMu.RLock()
+	defer logListenersMu.RUnlock()
+	if len(logListeners) == 0 {
+		return len(p), nil
+	}
+
+	level, ok := logLineLevel(p)
+	if !ok {
+		return len(p), nil
+	}
+	message := string(bytes.TrimSpace(p))
+	for _, l := range logListeners {
+		if level >= l.minLevel {
+			l.listener.OnLogMessage(level.String(), message)
+		}
+	}
+
 	return len(p), nil
 }
 
+// logLineLevel extracts the level tag from a log line formatted by the slog
+// backend, e.g. "2006-01-02 15:04:05.000 [WRN] SYNC: message".
+func logLineLevel(line []byte) (slog.Level, bool) {
+	start := bytes.IndexByte(line, '[')
+	if start == -1 {
+		return 0, false
+	}
+	end := bytes.IndexByte(line[start:], ']')
+	if end == -1 {
+		return 0, false
+	}
+	return slog.LevelFromString(string(line[start+1 : start+end]))
+}
+
 // Loggers per subsystem.  A single backend logger is created and all subsytem
 // loggers created from it will write to the backend.  When adding new
 // subsystems, add the subsystem logger variable here and to the
@@ -48,7 +105,8 @@ var (
 
 	// logRotator is one of the logging outputs.  It should be closed on
 	// application shutdown.
-	logRotator *rotator.Rotator
+	logRotator   *rotator.Rotator
+	logRotatorMu sync.Mutex
 
 	log          = backendLog.Logger("DLWL")
 	loaderLog    = backendLog.Logger("LODR")
@@ -93,15 +151,76 @@ var subsystemLoggers = map[string]slog.Logger{
 // create roll files in the same directory.  It must be called before the
 // package-global log rotater variables are used.
 func initLogRotator(logFile string) error {
-	r, err := rotator.New(logFile, 10*1024, false, 3)
+	return InitLogRotator(logFile, defaultLogMaxRolls)
+}
+
+// InitLogRotator initializes the logging rotator to write logs to logFilePath
+// and keep at most maxRolls rolled files in the same directory.  Files are
+// rolled once they exceed 10MB.  A log rotator that was previously initialized
+// is closed and replaced.
+func InitLogRotator(logFilePath string, maxRolls int) error {
+	if maxRolls < 1 {
+		return fmt.Errorf("%s: maxRolls must be at least 1", ErrInvalid)
+	}
+
+	r, err := rotator.New(logFilePath, logRollSizeKB, false, maxRolls)
 	if err != nil {
 		return errors.Errorf("failed to create file rotator: %v", err)
 	}
 
+	logRotatorMu.Lock()
+	if logRotator != nil {
+		logRotator.Close()
+	}
 	logRotator = r
+	logRotatorMu.Unlock()
+
 	return nil
 }
 
+// closeLogRotator closes the log rotator if it was initialized.
+func closeLogRotator() {
+	logRotatorMu.Lock()
+	defer logRotatorMu.Unlock()
+
+	if logRotator != nil {
+		logRotator.Close()
+		logRotator = nil
+	}
+}
+
+// AddLogListener registers listener to receive every log line written at
+// minLevel or above, e.g. "warn" to mirror warnings and errors.  Listeners
+// are called synchronously and must not log through this package.
+func AddLogListener(listener LogListener, minLevel, uniqueIdentifier string) error {
+	level, ok := slog.LevelFromString(minLevel)
+	if !ok {
+		return fmt.Errorf("%s: invalid log level %q", ErrInvalid, minLevel)
+	}
+
+	logListenersMu.Lock()
+	defer logListenersMu.Unlock()
+
+	if _, ok := logListeners[uniqueIdentifier]; ok {
+		return errors.New(ErrListenerAlreadyExist)
+	}
+
+	logListeners[uniqueIdentifier] = &logListener{
+		listener: listener,
+		minLevel: level,
+	}
+	return nil
+}
+
+// RemoveLogListener stops delivering log lines to the listener registered
+// with uniqueIdentifier.
+func RemoveLogListener(uniqueIdentifier string) {
+	logListenersMu.Lock()
+	defer logListenersMu.Unlock()
+
+	delete(logListeners, uniqueIdentifier)
+}
+
 // UseLoggers sets the subsystem logs to use the provided loggers.
 func UseLoggers(main, loaderLog, walletLog, tkbyLog,
 	syncLog, cmgrLog, amgrLog slog.Logger) {
@@ -123,7 +242,10 @@ func UseLogger(logger slog.Logger) {
 
 // RegisterLogger should be called before logRotator is initialized.
 func RegisterLogger(tag string) (slog.Logger, error) {
-	if logRotator != nil {
+	logRotatorMu.Lock()
+	initialized := logRotator != nil
+	logRotatorMu.Unlock()
+	if initialized {
 		return nil, errors.E(ErrLogRotatorAlreadyInitialized)
 	}
 
@@ -137,6 +259,9 @@ func RegisterLogger(tag string) (slog.Logger, error) {
 	return logger, nil
 }
 
+// SetLogLevels sets the logging level of every subsystem logger.  The loggers
+// are shared with the running wallet and sync goroutines so the new level takes
+// effect immediately.  Invalid levels are ignored.
 func SetLogLevels(logLevel string) {
 	_, ok := slog.LevelFromString(logLevel)
 	if !ok {
@@ -3,15 +3,25 @@ package dcrlibwallet
 import (
 	"decred.org/dcrwallet/v2/errors"
 	w "decred.org/dcrwallet/v2/wallet"
+	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/txscript/v4/stdaddr"
 )
 
+// SignMessage signs message with the private key of address. The passphrase is
+// verified even if the wallet is unlocked, and the wallet is unlocked for the
+// duration of the call.
 func (wallet *Wallet) SignMessage(passphrase []byte, address string, message string) ([]byte, error) {
-	err := wallet.UnlockWallet(passphrase)
+	defer func() {
+		for i := range passphrase {
+			passphrase[i] = 0
+		}
+	}()
+
+	relock, err := wallet.unlockTemporarily(passphrase)
 	if err != nil {
 		return nil, translateError(err)
 	}
-	defer wallet.LockWallet()
+	defer relock()
 
 	return wallet.signMessage(address, message)
 }
@@ -19,7 +29,7 @@ func (wallet *Wallet) SignMessage(passphrase []byte, address string, message str
 func (wallet *Wallet) signMessage(address string, message string) ([]byte, error) {
 	addr, err := stdaddr.DecodeAddress(address, wallet.chainParams)
 	if err != nil {
-		return nil, translateError(err)
+		return nil, errors.New(ErrInvalidAddress)
 	}
 
 	// Addresses must have an associated secp256k1 private key and therefore
@@ -28,7 +38,15 @@ func (wallet *Wallet) signMessage(address string, message string) ([]byte, error
 	case *stdaddr.AddressPubKeyEcdsaSecp256k1V0:
 	case *stdaddr.AddressPubKeyHashEcdsaSecp256k1V0:
 	default:
-		return nil, errors.New(ErrInvalidAddress)
+		return nil, errors.New(ErrAddressCannotSign)
+	}
+
+	have, err := wallet.Internal().HaveAddress(wallet.shutdownContext(), addr)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if !have {
+		return nil, errors.New(ErrAddressNotOwned)
 	}
 
 	sig, err := wallet.Internal().SignMessage(wallet.shutdownContext(), message, addr)
@@ -40,13 +58,31 @@ func (wallet *Wallet) signMessage(address string, message string) ([]byte, error
 }
 
 func (mw *MultiWallet) VerifyMessage(address string, message string, signatureBase64 string) (bool, error) {
-	var valid bool
-
 	addr, err := stdaddr.DecodeAddress(address, mw.chainParams)
 	if err != nil {
-		return false, translateError(err)
+		return false, errors.New(ErrInvalidAddress)
+	}
+
+	return verifyMessage(addr, message, signatureBase64, mw.chainParams)
+}
+
+// VerifyMessage checks that signatureBase64 is a valid signature of message by
+// the private key of address. The network is inferred from the address so no
+// wallet needs to be loaded.
+func VerifyMessage(address string, message string, signatureBase64 string) (bool, error) {
+	for _, params := range []*chaincfg.Params{chaincfg.MainNetParams(), chaincfg.TestNet3Params(), chaincfg.SimNetParams()} {
+		addr, err := stdaddr.DecodeAddress(address, params)
+		if err != nil {
+			continue
+		}
+
+		return verifyMessage(addr, message, signatureBase64, params)
 	}
 
+	return false, errors.New(ErrInvalidAddress)
+}
+
+func verifyMessage(addr stdaddr.Address, message string, signatureBase64 string, params *chaincfg.Params) (bool, error) {
 	signature, err := DecodeBase64(signatureBase64)
 	if err != nil {
 		return false, err
@@ -58,10 +94,10 @@ func (mw *MultiWallet) VerifyMessage(address string, message string, signatureBa
 	case *stdaddr.AddressPubKeyEcdsaSecp256k1V0:
 	case *stdaddr.AddressPubKeyHashEcdsaSecp256k1V0:
 	default:
-		return false, errors.New(ErrInvalidAddress)
+		return false, errors.New(ErrAddressCannotSign)
 	}
 
-	valid, err = w.VerifyMessage(message, addr, signature, mw.chainParams)
+	valid, err := w.VerifyMessage(message, addr, signature, params)
 	if err != nil {
 		return false, translateError(err)
 	}
@@ -2,6 +2,7 @@ package dcrlibwallet
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -16,6 +17,7 @@ import (
 	"github.com/asdine/storm"
 	"github.com/asdine/storm/q"
 	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/hdkeychain/v3"
 	"github.com/planetdecred/dcrlibwallet/utils"
 	"github.com/planetdecred/dcrlibwallet/walletdata"
 	bolt "go.etcd.io/bbolt"
@@ -37,15 +39,37 @@ type MultiWallet struct {
 
 	blocksRescanProgressListener     BlocksRescanProgressListener
 	accountMixerNotificationListener map[string]AccountMixerNotificationListener
+	walletLockStateListeners         map[string]WalletLockStateListener
+	ticketNotificationListeners      map[string]TicketNotificationListener
+	balanceNotificationListeners     map[string]BalanceNotificationListener
+	exchangeRateListeners            map[string]ExchangeRateListener
+	syncDataListeners                map[string]SyncDataListener
 
-	shuttingDown chan bool
-	cancelFuncs  []context.CancelFunc
+	shutdownOnce   sync.Once
+	shutdownCtx    context.Context
+	shutdownCancel context.CancelFunc
 
 	Politeia  *Politeia
 	dexClient *DexClient
 
 	vspMu sync.RWMutex
 	vsps  []*VSP
+
+	exchangeRateMu           sync.Mutex
+	cancelExchangeRateTicker context.CancelFunc
+
+	// syncDataUsageMu guards the daily sync data usage totals saved to the
+	// config db.
+	syncDataUsageMu sync.Mutex
+
+	// networkMu guards the state of the device's network connection set
+	// with SetNetworkAvailable and SetNetworkMetered, and the sync paused
+	// because of it.
+	networkMu        sync.Mutex
+	networkAvailable bool
+	networkMetered   bool
+	syncPaused       bool
+	pausedRescan     *pausedRescan
 }
 
 func NewMultiWallet(rootDir, dbDriver, netType, politeiaHost string) (*MultiWallet, error) {
@@ -53,21 +77,34 @@ func NewMultiWallet(rootDir, dbDriver, netType, politeiaHost string) (*MultiWall
 
 	chainParams, err := utils.ChainParams(netType)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s: %v", ErrInvalid, err)
 	}
 
-	rootDir = filepath.Join(rootDir, netType)
+	rootDir = filepath.Join(rootDir, chainParams.Name)
 	err = os.MkdirAll(rootDir, os.ModePerm)
 	if err != nil {
 		return nil, errors.Errorf("failed to create rootDir: %v", err)
 	}
 
-	err = initLogRotator(filepath.Join(rootDir, logFileName))
+	err = checkDirWritable(rootDir)
 	if err != nil {
-		return nil, errors.Errorf("failed to init logRotator: %v", err.Error())
+		return nil, errors.Errorf("rootDir is not writable: %v", err)
+	}
+
+	// Keep the log file chosen by the app if it called InitLogRotator.
+	logRotatorMu.Lock()
+	initialized := logRotator != nil
+	logRotatorMu.Unlock()
+	if !initialized {
+		err = initLogRotator(filepath.Join(rootDir, logFileName))
+		if err != nil {
+			return nil, errors.Errorf("failed to init logRotator: %v", err.Error())
+		}
 	}
 
-	mwDB, err := storm.Open(filepath.Join(rootDir, walletsDbName))
+	// Fail instead of blocking if another process has the database open.
+	boltOptions := &bolt.Options{Timeout: walletsDbLockTimeout}
+	mwDB, err := storm.Open(filepath.Join(rootDir, walletsDbName), storm.BoltOptions(0600, boltOptions))
 	if err != nil {
 		log.Errorf("Error opening wallets database: %s", err.Error())
 		if err == bolt.ErrTimeout {
@@ -100,9 +137,16 @@ func NewMultiWallet(rootDir, dbDriver, netType, politeiaHost string) (*MultiWall
 		badWallets:  make(map[int]*Wallet),
 		syncData: &syncData{
 			syncProgressListeners: make(map[string]SyncProgressListener),
+			bestBlockOnNetwork:    -1,
 		},
 		txAndBlockNotificationListeners:  make(map[string]TxAndBlockNotificationListener),
 		accountMixerNotificationListener: make(map[string]AccountMixerNotificationListener),
+		walletLockStateListeners:         make(map[string]WalletLockStateListener),
+		ticketNotificationListeners:      make(map[string]TicketNotificationListener),
+		balanceNotificationListeners:     make(map[string]BalanceNotificationListener),
+		exchangeRateListeners:            make(map[string]ExchangeRateListener),
+		syncDataListeners:                make(map[string]SyncDataListener),
+		networkAvailable:                 true,
 	}
 
 	mw.Politeia, err = newPoliteia(mw, politeiaHost)
@@ -120,7 +164,7 @@ func NewMultiWallet(rootDir, dbDriver, netType, politeiaHost string) (*MultiWall
 
 	// prepare the wallets loaded from db for use
 	for _, wallet := range wallets {
-		err = wallet.prepare(rootDir, chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID))
+		err = wallet.prepare(rootDir, chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID), mw.walletConfigDeleteFn(wallet.ID))
 		if err == nil && !WalletExistsAt(wallet.dataDir) {
 			err = fmt.Errorf("missing wallet database file")
 		}
@@ -128,11 +172,16 @@ func NewMultiWallet(rootDir, dbDriver, netType, politeiaHost string) (*MultiWall
 			mw.badWallets[wallet.ID] = wallet
 			log.Warnf("Ignored wallet load error for wallet %d (%s)", wallet.ID, wallet.Name)
 		} else {
+			wallet.lockStateChanged = mw.publishWalletLockStateChanged
+			wallet.rescanning = mw.IsRescanning
+			wallet.ticketPurchaseFailed = mw.publishTicketPurchaseFailed
+			wallet.addressLabel = mw.addressLabel
+			wallet.transactionFeeRate = mw.TransactionFeeRate
 			mw.wallets[wallet.ID] = wallet
 		}
 	}
 
-	mw.listenForShutdown()
+	mw.initShutdownContext()
 
 	logLevel := mw.ReadStringConfigValueForKey(LogLevelConfigKey)
 	SetLogLevels(logLevel)
@@ -146,15 +195,35 @@ func NewMultiWallet(rootDir, dbDriver, netType, politeiaHost string) (*MultiWall
 	return mw, nil
 }
 
+// Shutdown cancels and waits for any sync or rescan in progress, stops the
+// politeia sync, locks and unloads all wallets, closes their databases and
+// releases the wallets database. It is safe to call Shutdown more than once,
+// only the first call has any effect.
 func (mw *MultiWallet) Shutdown() {
+	mw.shutdownOnce.Do(mw.shutdown)
+}
+
+func (mw *MultiWallet) shutdown() {
 	log.Info("Shutting down dcrlibwallet")
 
-	// Trigger shuttingDown signal to cancel all contexts created with `shutdownContextWithCancel`.
-	mw.shuttingDown <- true
+	// Cancel all contexts created with `contextWithShutdownCancel`.
+	mw.shutdownCancel()
+
+	mw.syncData.mu.RLock()
+	rescanDone := mw.syncData.rescanDone
+	mw.syncData.mu.RUnlock()
 
 	mw.CancelRescan()
+	if rescanDone != nil {
+		<-rescanDone
+	}
+
 	mw.CancelSync()
 
+	if mw.Politeia != nil {
+		mw.Politeia.StopSync()
+	}
+
 	for _, wallet := range mw.wallets {
 		wallet.Shutdown()
 	}
@@ -167,17 +236,30 @@ func (mw *MultiWallet) Shutdown() {
 		}
 	}
 
-	if logRotator != nil {
-		log.Info("Shutting down log rotator")
-		logRotator.Close()
-		log.Info("Shutdown log rotator successfully")
-	}
+	log.Info("Shutting down log rotator")
+	closeLogRotator()
 }
 
 func (mw *MultiWallet) NetType() string {
 	return mw.chainParams.Name
 }
 
+// ChainParams returns the parameters of the network the wallets are on.
+func (mw *MultiWallet) ChainParams() *chaincfg.Params {
+	return mw.chainParams
+}
+
+// CoinbaseMaturity returns the number of blocks before coinbase and vote
+// outputs can be spent.
+func (mw *MultiWallet) CoinbaseMaturity() int32 {
+	return int32(mw.chainParams.CoinbaseMaturity)
+}
+
+// TargetTimePerBlock returns the target time between blocks in seconds.
+func (mw *MultiWallet) TargetTimePerBlock() int32 {
+	return int32(mw.chainParams.TargetTimePerBlock.Seconds())
+}
+
 func (mw *MultiWallet) LogDir() string {
 	return filepath.Join(mw.rootDir, logFileName)
 }
@@ -285,6 +367,66 @@ func (mw *MultiWallet) OpenWallets(startupPassphrase []byte) error {
 	return nil
 }
 
+// OpenWallet opens the wallet with the provided id, if it isn't already open.
+func (mw *MultiWallet) OpenWallet(walletID int) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	return wallet.openWallet()
+}
+
+// OpenWalletWithPublicPassphrase is like OpenWallet but opens a wallet whose
+// database is encrypted with a custom public passphrase. The passphrase can
+// be retried if ErrInvalidPublicPassphrase is returned.
+func (mw *MultiWallet) OpenWalletWithPublicPassphrase(walletID int, pubPass []byte) error {
+	defer func() {
+		for i := range pubPass {
+			pubPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	return wallet.openWalletWithPublicPassphrase(pubPass)
+}
+
+// CloseWallet stops any sync or rescan in progress, closes the transactions
+// index database of the wallet with the provided id and unloads the wallet.
+// Sync is restarted for the remaining opened wallets, if sync was canceled.
+func (mw *MultiWallet) CloseWallet(walletID int) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	mw.CancelRescan()
+	if mw.IsConnectedToDecredNetwork() {
+		otherOpenedWallets := mw.OpenedWalletsCount()
+		if wallet.WalletOpened() {
+			otherOpenedWallets--
+		}
+		mw.cancelSync(otherOpenedWallets > 0)
+		defer func() {
+			if mw.OpenedWalletsCount() > 0 {
+				mw.SpvSync()
+			}
+		}()
+	}
+
+	err := wallet.closeWallet()
+	if err != nil {
+		log.Errorf("[%d] Error closing wallet: %v", walletID, err)
+		return translateError(err)
+	}
+
+	return nil
+}
+
 func (mw *MultiWallet) AllWalletsAreWatchOnly() (bool, error) {
 	if len(mw.wallets) == 0 {
 		return false, errors.New(ErrInvalid)
@@ -307,7 +449,7 @@ func (mw *MultiWallet) CreateWatchOnlyWallet(walletName, extendedPublicKey strin
 	}
 
 	return mw.saveNewWallet(wallet, func() error {
-		err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID))
+		err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID), mw.walletConfigDeleteFn(wallet.ID))
 		if err != nil {
 			return err
 		}
@@ -335,7 +477,7 @@ func (mw *MultiWallet) CreateNewWallet(walletName, privatePassphrase string, pri
 	}
 
 	return mw.saveNewWallet(wallet, func() error {
-		err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID))
+		err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID), mw.walletConfigDeleteFn(wallet.ID))
 		if err != nil {
 			return err
 		}
@@ -351,10 +493,11 @@ func (mw *MultiWallet) RestoreWallet(walletName, seedMnemonic, privatePassphrase
 		PrivatePassphraseType: privatePassphraseType,
 		IsRestored:            true,
 		HasDiscoveredAccounts: false,
+		RescanOnFirstSync:     true,
 	}
 
 	return mw.saveNewWallet(wallet, func() error {
-		err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID))
+		err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID), mw.walletConfigDeleteFn(wallet.ID))
 		if err != nil {
 			return err
 		}
@@ -363,6 +506,27 @@ func (mw *MultiWallet) RestoreWallet(walletName, seedMnemonic, privatePassphrase
 	})
 }
 
+// CreateWalletFromHexSeed restores a wallet from a hex-encoded seed, such as
+// raw entropy produced by a hardware RNG, rather than a seed mnemonic.
+func (mw *MultiWallet) CreateWalletFromHexSeed(walletName, hexSeed, privatePassphrase string, privatePassphraseType int32) (*Wallet, error) {
+	hexSeed = strings.TrimSpace(hexSeed)
+	if len(hexSeed) == 0 {
+		return nil, errors.New(ErrEmptySeed)
+	}
+
+	seed, err := hex.DecodeString(hexSeed)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+	if len(seed) < hdkeychain.MinSeedBytes || len(seed) > hdkeychain.MaxSeedBytes {
+		return nil, errors.New(ErrInvalidSeedLength)
+	}
+
+	// walletseed.DecodeUserInput, used when creating the wallet, decodes a
+	// single word input as a hex seed.
+	return mw.RestoreWallet(walletName, hexSeed, privatePassphrase, privatePassphraseType)
+}
+
 func (mw *MultiWallet) LinkExistingWallet(walletName, walletDataDir, originalPubPass string, privatePassphraseType int32) (*Wallet, error) {
 	// check if `walletDataDir` contains wallet.db
 	if !WalletExistsAt(walletDataDir) {
@@ -399,7 +563,7 @@ func (mw *MultiWallet) LinkExistingWallet(walletName, walletDataDir, originalPub
 
 		// prepare the wallet for use and open it
 		err := (func() error {
-			err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID))
+			err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID), mw.walletConfigDeleteFn(wallet.ID))
 			if err != nil {
 				return err
 			}
@@ -450,7 +614,7 @@ func (mw *MultiWallet) saveNewWallet(wallet *Wallet, setupWallet func() error) (
 	}
 
 	if mw.IsConnectedToDecredNetwork() {
-		mw.CancelSync()
+		mw.cancelSync(true)
 		defer mw.SpvSync()
 	}
 	// Perform database save operations in batch transaction
@@ -493,9 +657,22 @@ func (mw *MultiWallet) saveNewWallet(wallet *Wallet, setupWallet func() error) (
 	})
 
 	if err != nil {
+		// The transactions index db is opened by wallet.prepare before the
+		// wallet is created, close it so the db file isn't left locked.
+		if wallet.walletDataDB != nil {
+			if closeErr := wallet.walletDataDB.Close(); closeErr != nil {
+				log.Errorf("tx db closed with error: %v", closeErr)
+			}
+			wallet.walletDataDB = nil
+		}
 		return nil, translateError(err)
 	}
 
+	wallet.lockStateChanged = mw.publishWalletLockStateChanged
+	wallet.rescanning = mw.IsRescanning
+	wallet.ticketPurchaseFailed = mw.publishTicketPurchaseFailed
+	wallet.addressLabel = mw.addressLabel
+	wallet.transactionFeeRate = mw.TransactionFeeRate
 	mw.wallets[wallet.ID] = wallet
 
 	return wallet, nil
@@ -529,7 +706,11 @@ func (mw *MultiWallet) DeleteWallet(walletID int, privPass []byte) error {
 	}
 
 	if mw.IsConnectedToDecredNetwork() {
-		mw.CancelSync()
+		otherOpenedWallets := mw.OpenedWalletsCount()
+		if wallet.WalletOpened() {
+			otherOpenedWallets--
+		}
+		mw.cancelSync(otherOpenedWallets > 0)
 		defer func() {
 			if mw.OpenedWalletsCount() > 0 {
 				mw.SpvSync()
@@ -547,6 +728,7 @@ func (mw *MultiWallet) DeleteWallet(walletID int, privPass []byte) error {
 		return translateError(err)
 	}
 
+	mw.deleteWalletConfigValues(walletID)
 	delete(mw.wallets, walletID)
 
 	return nil
@@ -672,6 +854,43 @@ func (mw *MultiWallet) UnlockWallet(walletID int, privPass []byte) error {
 	return wallet.UnlockWallet(privPass)
 }
 
+func (mw *MultiWallet) UnlockWalletWithTimeout(walletID int, privPass []byte, timeoutSeconds int64) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	return wallet.UnlockWalletWithTimeout(privPass, timeoutSeconds)
+}
+
+func (mw *MultiWallet) AddWalletLockStateListener(listener WalletLockStateListener, uniqueIdentifier string) error {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	if _, ok := mw.walletLockStateListeners[uniqueIdentifier]; ok {
+		return errors.New(ErrListenerAlreadyExist)
+	}
+
+	mw.walletLockStateListeners[uniqueIdentifier] = listener
+	return nil
+}
+
+func (mw *MultiWallet) RemoveWalletLockStateListener(uniqueIdentifier string) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	delete(mw.walletLockStateListeners, uniqueIdentifier)
+}
+
+func (mw *MultiWallet) publishWalletLockStateChanged(walletID int, locked bool) {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	for _, listener := range mw.walletLockStateListeners {
+		listener.OnWalletLockStateChanged(walletID, locked)
+	}
+}
+
 // ChangePrivatePassphraseForWallet attempts to change the wallet's passphrase and re-encrypts the seed with the new passphrase.
 func (mw *MultiWallet) ChangePrivatePassphraseForWallet(walletID int, oldPrivatePassphrase, newPrivatePassphrase []byte, privatePassphraseType int32) error {
 	if privatePassphraseType != PassphraseTypePin && privatePassphraseType != PassphraseTypePass {
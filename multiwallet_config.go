@@ -11,6 +11,10 @@ const (
 
 	SpendUnconfirmedConfigKey   = "spend_unconfirmed"
 	CurrencyConversionConfigKey = "currency_conversion_option"
+	ExchangeRateSourceConfigKey = "exchange_rate_source"
+	ExchangeRateCacheConfigKey  = "exchange_rate_cache"
+	SyncDataUsageConfigKey      = "sync_data_usage"
+	TransactionFeeRateConfigKey = "tx_fee_rate"
 
 	IsStartupSecuritySetConfigKey = "startup_security_set"
 	StartupSecurityTypeConfigKey  = "startup_security_type"
@@ -23,12 +27,15 @@ const (
 	NetworkModeConfigKey                = "network_mode"
 	SpvPersistentPeerAddressesConfigKey = "spv_peer_addresses"
 	UserAgentConfigKey                  = "user_agent"
+	SyncOnlyOnUnmeteredNetworkConfigKey = "sync_only_on_unmetered_network"
+	SpvLastDNSSeedConfigKey             = "spv_last_dns_seed"
 
 	PoliteiaNotificationConfigKey = "politeia_notification"
 
 	LastTxHashConfigKey = "last_tx_hash"
 
 	KnownVSPsConfigKey = "known_vsps"
+	VSPListConfigKey   = "vsp_list"
 
 	TicketBuyerVSPHostConfigKey = "tb_vsp_host"
 	TicketBuyerWalletConfigKey  = "tb_wallet_id"
@@ -41,6 +48,7 @@ const (
 
 type configSaveFn = func(key string, value interface{}) error
 type configReadFn = func(multiwallet bool, key string, valueOut interface{}) error
+type configDeleteFn = func(key string) error
 
 func (mw *MultiWallet) walletConfigSetFn(walletID int) configSaveFn {
 	return func(key string, value interface{}) error {
@@ -58,6 +66,13 @@ func (mw *MultiWallet) walletConfigReadFn(walletID int) configReadFn {
 	}
 }
 
+func (mw *MultiWallet) walletConfigDeleteFn(walletID int) configDeleteFn {
+	return func(key string) error {
+		walletUniqueKey := WalletUniqueConfigKey(walletID, key)
+		return mw.db.Delete(userConfigBucketName, walletUniqueKey)
+	}
+}
+
 func (mw *MultiWallet) SaveUserConfigValue(key string, value interface{}) {
 	err := mw.db.Set(userConfigBucketName, key, value)
 	if err != nil {
@@ -80,6 +95,34 @@ func (mw *MultiWallet) DeleteUserConfigValueForKey(key string) {
 	}
 }
 
+// deleteWalletConfigValues deletes the config values saved for the wallet
+// with the provided id.
+func (mw *MultiWallet) deleteWalletConfigValues(walletID int) {
+	walletConfigKeys := []string{
+		AccountMixerConfigSet,
+		AccountMixerMixedAccount,
+		AccountMixerUnmixedAccount,
+		AccountMixerMixTxChange,
+		AccountMixerCSPPServer,
+		TicketBuyerATMConfigKey,
+		TicketBuyerAccountConfigKey,
+		TicketBuyerVSPHostConfigKey,
+		WalletBirthdayConfigKey,
+		LockedOutputsConfigKey,
+		VSPHostConfigKey,
+		ForceFullSyncConfigKey,
+		GapLimitConfigKey,
+	}
+
+	deleteConfigValue := mw.walletConfigDeleteFn(walletID)
+	for _, key := range walletConfigKeys {
+		err := deleteConfigValue(key)
+		if err != nil && err != storm.ErrNotFound {
+			log.Errorf("error deleting config value for key: %s, error: %v", key, err)
+		}
+	}
+}
+
 func (mw *MultiWallet) ClearConfig() {
 	err := mw.db.Drop(userConfigBucketName)
 	if err != nil {
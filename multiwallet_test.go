@@ -0,0 +1,509 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"decred.org/dcrwallet/v2/walletseed"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/hdkeychain/v3"
+	"github.com/decred/dcrd/txscript/v4"
+	"github.com/decred/dcrd/wire"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/planetdecred/dcrlibwallet/txhelper"
+)
+
+const testPrivatePassphrase = "test passphrase"
+
+// newTestMultiWallet returns a testnet MultiWallet in a temporary directory,
+// which is removed when the MultiWallet is shut down by the returned func.
+func newTestMultiWallet() (*MultiWallet, func()) {
+	rootDir, err := ioutil.TempDir("", "dcrlibwallet")
+	Expect(err).To(BeNil())
+
+	mw, err := NewMultiWallet(rootDir, "bdb", "testnet3", "")
+	Expect(err).To(BeNil())
+
+	return mw, func() {
+		mw.Shutdown()
+		os.RemoveAll(rootDir)
+	}
+}
+
+var _ = Describe("MultiWallet", func() {
+	var mw *MultiWallet
+	var shutdown func()
+
+	BeforeEach(func() {
+		mw, shutdown = newTestMultiWallet()
+	})
+
+	AfterEach(func() {
+		shutdown()
+	})
+
+	Context("CreateNewWallet", func() {
+		It("initializes the tx index of a new wallet without reopening it", func() {
+			wallet, err := mw.CreateNewWallet("wallet", testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+			Expect(wallet.walletDataDB).ToNot(BeNil())
+
+			hash := strings.Repeat("a", 64)
+			_, err = wallet.walletDataDB.SaveOrUpdate(&Transaction{}, &Transaction{Hash: hash, Type: txhelper.TxTypeRegular,
+				BlockHeight: 1, Timestamp: 1600000000})
+			Expect(err).To(BeNil())
+			transactions, err := wallet.GetTransactionsRaw(0, 0, TxFilterAll, true)
+			Expect(err).To(BeNil())
+			Expect(transactions).To(HaveLen(1))
+			Expect(transactions[0].Hash).To(Equal(hash))
+
+			By("Closing the tx index with the wallet")
+			Expect(mw.CloseWallet(wallet.ID)).To(Succeed())
+			Expect(wallet.walletDataDB).To(BeNil())
+			Expect(mw.OpenWallet(wallet.ID)).To(Succeed())
+			count, err := wallet.CountTransactions(TxFilterAll)
+			Expect(err).To(BeNil())
+			Expect(count).To(Equal(1))
+		})
+	})
+
+	Context("OpenWallet", func() {
+		It("does not advance the external branch when a wallet is opened", func() {
+			wallet, err := mw.CreateNewWallet("wallet", testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+			address, err := wallet.CurrentAddress(DefaultAccountNum)
+			Expect(err).To(BeNil())
+			extChild, _, err := wallet.Internal().BIP0044BranchNextIndexes(context.Background(), DefaultAccountNum)
+			Expect(err).To(BeNil())
+
+			for i := 0; i < 2; i++ {
+				Expect(mw.CloseWallet(wallet.ID)).To(Succeed())
+				Expect(mw.OpenWallet(wallet.ID)).To(Succeed())
+			}
+
+			reopenedExtChild, _, err := wallet.Internal().BIP0044BranchNextIndexes(context.Background(), DefaultAccountNum)
+			Expect(err).To(BeNil())
+			Expect(reopenedExtChild).To(Equal(extChild))
+			Expect(wallet.CurrentAddress(DefaultAccountNum)).To(Equal(address))
+		})
+	})
+
+	Context("CloseWallet", func() {
+		It("skips the closed wallet when reading the transactions of all wallets", func() {
+			wallet, err := mw.CreateNewWallet("closed", testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+			_, err = mw.CreateNewWallet("opened", testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+
+			Expect(mw.CloseWallet(wallet.ID)).To(Succeed())
+			Expect(wallet.WalletOpened()).To(BeFalse())
+
+			By("Returning ErrWalletNotLoaded from the closed wallet")
+			_, err = wallet.CountTransactions(TxFilterAll)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrWalletNotLoaded]))
+			_, err = wallet.GetTransactionsRaw(0, 0, TxFilterAll, true)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrWalletNotLoaded]))
+			_, err = wallet.WalletActivitySummaryRaw()
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrWalletNotLoaded]))
+
+			By("Reading the transactions of the opened wallet")
+			transactions, err := mw.GetTransactionsRaw(0, 0, TxFilterAll, true)
+			Expect(err).To(BeNil())
+			Expect(transactions).To(BeEmpty())
+			count, err := mw.CountTransactions(TxFilterAll)
+			Expect(err).To(BeNil())
+			Expect(count).To(Equal(0))
+			_, err = mw.PendingTransactions()
+			Expect(err).To(BeNil())
+			_, err = mw.StakingRewardsInRange(0, 0)
+			Expect(err).To(BeNil())
+			_, err = mw.StakingOverview()
+			Expect(err).To(BeNil())
+
+			By("Reopening the closed wallet")
+			Expect(mw.OpenWallet(wallet.ID)).To(Succeed())
+			count, err = wallet.CountTransactions(TxFilterAll)
+			Expect(err).To(BeNil())
+			Expect(count).To(Equal(0))
+		})
+
+		It("closes and reopens a wallet while syncing", func() {
+			wallet, err := mw.CreateNewWallet("closed", testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+			_, err = mw.CreateNewWallet("opened", testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+
+			// Sync from a peer that is never reachable so the sync keeps
+			// running without connecting to the network.
+			mw.SetStringConfigValueForKey(SpvPersistentPeerAddressesConfigKey, "127.0.0.1:1")
+			Expect(mw.SpvSync()).To(Succeed())
+			Expect(mw.IsSyncing()).To(BeTrue())
+
+			Expect(mw.CloseWallet(wallet.ID)).To(Succeed())
+			Expect(wallet.WalletOpened()).To(BeFalse())
+			Expect(mw.IsSyncing()).To(BeTrue())
+
+			By("Returning ErrWalletNotLoaded from every tx index read and write")
+			txHash := chainhash.Hash{1}.String()
+			_, err = wallet.PublishTransaction(nil)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrWalletNotLoaded]))
+			_, err = wallet.GetTransactionRaw(txHash)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrWalletNotLoaded]))
+			_, err = wallet.GetTransactionsForFilterRaw("{}", 0, 0)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrWalletNotLoaded]))
+			_, err = wallet.GetTransactionsSinceHeight(0)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrWalletNotLoaded]))
+			_, err = wallet.GetTransactionsSinceTimestamp(0)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrWalletNotLoaded]))
+			_, err = wallet.SearchTransactions(txHash, 0, 0)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrWalletNotLoaded]))
+			err = wallet.SetTransactionNote(txHash, "note")
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrWalletNotLoaded]))
+			_, err = wallet.TicketHasVotedOrRevoked(txHash)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrWalletNotLoaded]))
+			_, err = wallet.TicketSpender(txHash)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrWalletNotLoaded]))
+			_, err = wallet.WalletActivitySummaryRaw()
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrWalletNotLoaded]))
+
+			By("Reopening the closed wallet")
+			Expect(mw.OpenWallet(wallet.ID)).To(Succeed())
+			_, err = wallet.GetTransactionsSinceHeight(0)
+			Expect(err).To(BeNil())
+			_, err = wallet.SearchTransactions(txHash, 0, 0)
+			Expect(err).To(BeNil())
+			_, err = wallet.WalletActivitySummaryRaw()
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Context("CreateWalletFromHexSeed", func() {
+		It("restores the same wallet as the seed's mnemonic", func() {
+			seed := bytes.Repeat([]byte{0x5a}, hdkeychain.RecommendedSeedLen)
+
+			fromHex, err := mw.CreateWalletFromHexSeed("hex", hex.EncodeToString(seed), testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+			fromMnemonic, err := mw.RestoreWallet("mnemonic", walletseed.EncodeMnemonic(seed), testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+
+			hexAddresses, err := fromHex.deriveAddresses(DefaultAccountNum, 0, 0, 5)
+			Expect(err).To(BeNil())
+			mnemonicAddresses, err := fromMnemonic.deriveAddresses(DefaultAccountNum, 0, 0, 5)
+			Expect(err).To(BeNil())
+			Expect(hexAddresses).To(Equal(mnemonicAddresses))
+
+			By("Rejecting empty, non-hex and wrongly sized seeds")
+			_, err = mw.CreateWalletFromHexSeed("empty", " ", testPrivatePassphrase, PassphraseTypePass)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrEmptySeed]))
+			_, err = mw.CreateWalletFromHexSeed("non-hex", "not hex", testPrivatePassphrase, PassphraseTypePass)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrInvalid]))
+			_, err = mw.CreateWalletFromHexSeed("short", hex.EncodeToString(seed[:hdkeychain.MinSeedBytes-1]), testPrivatePassphrase, PassphraseTypePass)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrInvalidSeedLength]))
+		})
+	})
+
+	Context("SendTransactionToMultiple", func() {
+		It("rejects destinations that repeat an address", func() {
+			wallet, err := mw.CreateNewWallet("wallet", testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+			address, err := wallet.CurrentAddress(DefaultAccountNum)
+			Expect(err).To(BeNil())
+
+			destinations, err := json.Marshal([]TransactionDestination{
+				{Address: address, AtomAmount: 1e8},
+				{Address: address, AtomAmount: 2e8},
+			})
+			Expect(err).To(BeNil())
+
+			_, err = mw.SendTransactionToMultiple(wallet.ID, []byte(testPrivatePassphrase), string(destinations), DefaultAccountNum, 0)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrDuplicateDestination]))
+		})
+	})
+
+	Context("SignTransaction", func() {
+		It("reports inputs spending outputs of other parties as unsigned", func() {
+			wallet, err := mw.CreateNewWallet("wallet", testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+
+			msgTx := wire.NewMsgTx()
+			foreignSigScript := []byte{txscript.OP_TRUE}
+			prevOut := wire.NewOutPoint(&chainhash.Hash{1}, 0, wire.TxTreeRegular)
+			msgTx.AddTxIn(wire.NewTxIn(prevOut, 1e8, foreignSigScript))
+			msgTx.AddTxOut(wire.NewTxOut(1e8, []byte{txscript.OP_TRUE}))
+			serializedTx, err := msgTx.Bytes()
+			Expect(err).To(BeNil())
+
+			signed, err := wallet.SignTransaction([]byte(testPrivatePassphrase), serializedTx)
+			Expect(err).To(BeNil())
+			Expect(signed.Complete()).To(BeFalse())
+			Expect(signed.UnsignedInputs).To(Equal([]int32{0}))
+			Expect(signed.SerializedTx).To(Equal(serializedTx))
+		})
+	})
+
+	Context("UnlockWalletWithTimeout", func() {
+		It("restores the lock state after a temporary unlock", func() {
+			wallet, err := mw.CreateNewWallet("wallet", testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+
+			By("Keeping the remaining timeout of a timed unlock")
+			Expect(wallet.UnlockWalletWithTimeout([]byte(testPrivatePassphrase), 60)).To(Succeed())
+			deadline := wallet.lockDeadline
+			relock, err := wallet.unlockTemporarily([]byte(testPrivatePassphrase))
+			Expect(err).To(BeNil())
+			Expect(wallet.ExtendUnlockTimeout(60)).ToNot(Succeed())
+			relock()
+			Expect(wallet.IsLocked()).To(BeFalse())
+			Expect(wallet.lockDeadline).To(Equal(deadline))
+
+			By("Locking the wallet once a timeout that expired during the unlock is restored")
+			Expect(wallet.UnlockWalletWithTimeout([]byte(testPrivatePassphrase), 1)).To(Succeed())
+			relock, err = wallet.unlockTemporarily([]byte(testPrivatePassphrase))
+			Expect(err).To(BeNil())
+			time.Sleep(1100 * time.Millisecond)
+			Expect(wallet.IsLocked()).To(BeFalse())
+			relock()
+			Eventually(wallet.IsLocked).Should(BeTrue())
+
+			By("Leaving a wallet unlocked without a timeout unlocked")
+			Expect(wallet.UnlockWallet([]byte(testPrivatePassphrase))).To(Succeed())
+			relock, err = wallet.unlockTemporarily([]byte(testPrivatePassphrase))
+			Expect(err).To(BeNil())
+			relock()
+			Expect(wallet.IsLocked()).To(BeFalse())
+
+			By("Locking a locked wallet again")
+			wallet.LockWallet()
+			relock, err = wallet.unlockTemporarily([]byte(testPrivatePassphrase))
+			Expect(err).To(BeNil())
+			Expect(wallet.IsLocked()).To(BeFalse())
+			relock()
+			Expect(wallet.IsLocked()).To(BeTrue())
+
+			_, err = wallet.unlockTemporarily([]byte("wrong passphrase"))
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrInvalidPassphrase]))
+		})
+	})
+
+	Context("AddressesForAccountRaw", func() {
+		It("derives the addresses of the requested page", func() {
+			wallet, err := mw.CreateNewWallet("wallet", testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+
+			for i := 0; i < 3; i++ {
+				_, err = wallet.NextAddress(DefaultAccountNum)
+				Expect(err).To(BeNil())
+			}
+
+			all, err := wallet.AddressesForAccountRaw(DefaultAccountNum, true, 0, 0)
+			Expect(err).To(BeNil())
+			Expect(len(all)).To(BeNumerically(">=", 3))
+
+			page, err := wallet.AddressesForAccountRaw(DefaultAccountNum, true, 1, 2)
+			Expect(err).To(BeNil())
+			Expect(page).To(Equal(all[1:3]))
+
+			page, err = wallet.AddressesForAccountRaw(DefaultAccountNum, true, int32(len(all)), 0)
+			Expect(err).To(BeNil())
+			Expect(page).To(BeEmpty())
+
+			used, err := wallet.AddressesForAccountRaw(DefaultAccountNum, false, 0, 0)
+			Expect(err).To(BeNil())
+			Expect(used).To(BeEmpty())
+		})
+
+		It("reports the use of the addresses by the indexed transactions", func() {
+			wallet, err := mw.CreateNewWallet("wallet", testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+			_, err = wallet.NextAddress(DefaultAccountNum)
+			Expect(err).To(BeNil())
+			listed, err := wallet.AddressesForAccountRaw(DefaultAccountNum, true, 0, 0)
+			Expect(err).To(BeNil())
+			Expect(listed).NotTo(BeEmpty())
+			address := listed[0].Address
+
+			for i, amount := range []int64{5000, 7000} {
+				tx := &Transaction{
+					Hash:        strings.Repeat(string(rune('a'+i)), 64),
+					Type:        txhelper.TxTypeRegular,
+					BlockHeight: int32(i + 1),
+					Timestamp:   1600000000 + int64(i),
+					Outputs: []*TxOutput{
+						{Index: 0, Amount: amount, Address: address, AccountNumber: DefaultAccountNum},
+						{Index: 1, Amount: 100, Address: "TsfDLrRkk9ciUuwfp2b8PawwnukYD7yAjGd", AccountNumber: -1},
+					},
+					Addresses: []string{address, "TsfDLrRkk9ciUuwfp2b8PawwnukYD7yAjGd"},
+				}
+				_, err = wallet.walletDataDB.SaveOrUpdate(&Transaction{}, tx)
+				Expect(err).To(BeNil())
+			}
+
+			all, err := wallet.AddressesForAccountRaw(DefaultAccountNum, true, 0, 0)
+			Expect(err).To(BeNil())
+
+			var found bool
+			for _, a := range all {
+				if a.Address != address {
+					Expect(a.TxCount).To(BeZero())
+					continue
+				}
+				found = true
+				Expect(a.Used).To(BeTrue())
+				Expect(a.TxCount).To(Equal(int32(2)))
+				Expect(a.TotalReceived).To(Equal(int64(12000)))
+			}
+			Expect(found).To(BeTrue())
+		})
+	})
+
+	Context("SpvPeerStatsRaw", func() {
+		It("counts the addresses of the saved peers file until it is reset", func() {
+			peersFile := `{
+				"Version": 2,
+				"Addresses": [
+					{"Addr": "10.0.0.1:19108", "Src": "10.0.0.9:19108", "Attempts": 0, "TimeStamp": 1600000000},
+					{"Addr": "10.0.0.2:19108", "Src": "10.0.0.9:19108", "Attempts": 1, "TimeStamp": 1600000000},
+					{"Addr": "10.0.0.3:19108", "Src": "10.0.0.9:19108", "Attempts": 0, "TimeStamp": 1600000000}
+				],
+				"NewBuckets": [["10.0.0.3:19108"]],
+				"TriedBuckets": [["10.0.0.1:19108"], [], ["10.0.0.2:19108"]]
+			}`
+			err := ioutil.WriteFile(filepath.Join(mw.rootDir, peersFileName), []byte(peersFile), 0600)
+			Expect(err).To(BeNil())
+			mw.SetStringConfigValueForKey(SpvPersistentPeerAddressesConfigKey, "10.0.0.1:19108; 10.0.0.4:19108")
+
+			stats, err := mw.SpvPeerStatsRaw()
+			Expect(err).To(BeNil())
+			Expect(stats.KnownAddresses).To(Equal(3))
+			Expect(stats.GoodAddresses).To(Equal(2))
+			Expect(stats.PersistentPeers).To(Equal([]string{"10.0.0.1:19108", "10.0.0.4:19108"}))
+
+			Expect(mw.ResetPeerDatabase()).To(BeNil())
+
+			stats, err = mw.SpvPeerStatsRaw()
+			Expect(err).To(BeNil())
+			Expect(stats.KnownAddresses).To(Equal(0))
+			Expect(stats.GoodAddresses).To(Equal(0))
+		})
+	})
+
+	Context("BackupWalletData", func() {
+		It("restores a backed up wallet", func() {
+			wallet, err := mw.CreateNewWallet("wallet", testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+			address, err := wallet.CurrentAddress(DefaultAccountNum)
+			Expect(err).To(BeNil())
+
+			backupPath := filepath.Join(mw.rootDir, "wallet.backup")
+			backupPassphrase := []byte("backup passphrase")
+			size, err := wallet.BackupWalletData(backupPath, backupPassphrase)
+			Expect(err).To(BeNil())
+			Expect(size).To(BeNumerically(">", 0))
+
+			By("Refusing a wrong passphrase and overwriting without being forced")
+			_, err = mw.RestoreWalletData(backupPath, []byte("wrong passphrase"), "restored", false)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrInvalidPassphrase]))
+			_, err = mw.RestoreWalletData(backupPath, backupPassphrase, "", false)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrExist]))
+
+			By("Refusing a truncated backup")
+			backup, err := ioutil.ReadFile(backupPath)
+			Expect(err).To(BeNil())
+			truncatedPath := filepath.Join(mw.rootDir, "truncated.backup")
+			Expect(ioutil.WriteFile(truncatedPath, backup[:len(backup)-secretStreamChunkSize/2], 0600)).To(Succeed())
+			_, err = mw.RestoreWalletData(truncatedPath, backupPassphrase, "truncated", false)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrInvalid]))
+			Expect(mw.WalletNameExists("truncated")).To(BeFalse())
+
+			By("Restoring the backup as a new wallet")
+			restored, err := mw.RestoreWalletData(backupPath, backupPassphrase, "restored", false)
+			Expect(err).To(BeNil())
+			Expect(restored.ID).ToNot(Equal(wallet.ID))
+			Expect(restored.WalletOpened()).To(BeTrue())
+			restoredAddress, err := restored.CurrentAddress(DefaultAccountNum)
+			Expect(err).To(BeNil())
+			Expect(restoredAddress).To(Equal(address))
+
+			By("Overwriting the backed up wallet when forced")
+			overwritten, err := mw.RestoreWalletData(backupPath, backupPassphrase, "", true)
+			Expect(err).To(BeNil())
+			Expect(overwritten.ID).To(Equal(wallet.ID))
+			Expect(overwritten.WalletOpened()).To(BeTrue())
+			overwrittenAddress, err := overwritten.CurrentAddress(DefaultAccountNum)
+			Expect(err).To(BeNil())
+			Expect(overwrittenAddress).To(Equal(address))
+		})
+	})
+
+	Context("ExportTransactionsCSV", func() {
+		It("exports the indexed transactions oldest first", func() {
+			wallet, err := mw.CreateNewWallet("wallet", testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+
+			received := strings.Repeat("a", 64)
+			vote := strings.Repeat("b", 64)
+			unmined := strings.Repeat("c", 64)
+			for _, tx := range []*Transaction{
+				{Hash: unmined, Type: txhelper.TxTypeRegular, Direction: txhelper.TxDirectionSent, BlockHeight: BlockHeightInvalid,
+					Timestamp: 1600000300, Amount: 12345, Fee: 2550},
+				{Hash: vote, Type: txhelper.TxTypeVote, Direction: txhelper.TxDirectionReceived, BlockHeight: 20,
+					Timestamp: 1600000200, Amount: 200000000, VoteReward: 1234567},
+				{Hash: received, Type: txhelper.TxTypeRegular, Direction: txhelper.TxDirectionReceived, BlockHeight: 10,
+					Timestamp: 1600000100, Amount: 150000000},
+			} {
+				_, err = wallet.walletDataDB.SaveOrUpdate(&Transaction{}, tx)
+				Expect(err).To(BeNil())
+			}
+			Expect(wallet.SetTransactionNote(received, "salary, march")).To(Succeed())
+
+			exported, err := wallet.ExportTransactionsCSV(TxFilterAll)
+			Expect(err).To(BeNil())
+			rows, err := csv.NewReader(strings.NewReader(exported)).ReadAll()
+			Expect(err).To(BeNil())
+			Expect(rows).To(Equal([][]string{
+				csvExportHeader,
+				{"2020-09-13T12:28:20Z", "10", txhelper.TxTypeRegular, "received", "1.5", "0", received, "salary, march"},
+				{"2020-09-13T12:30:00Z", "20", txhelper.TxTypeVote, "received", "0.01234567", "0", vote, ""},
+				{"2020-09-13T12:31:40Z", "-1", txhelper.TxTypeRegular, "sent", "0.00012345", "0.0000255", unmined, ""},
+			}))
+
+			By("Writing the same rows to a file")
+			csvPath := filepath.Join(mw.rootDir, "transactions.csv")
+			count, err := wallet.ExportTransactionsToCSV(csvPath, TxFilterAll)
+			Expect(err).To(BeNil())
+			Expect(count).To(Equal(int32(3)))
+			Expect(ioutil.ReadFile(csvPath)).To(Equal([]byte(exported)))
+			Expect(csvPath + ".tmp").ToNot(BeAnExistingFile())
+
+			staking, err := wallet.ExportTransactionsCSV(TxFilterStaking)
+			Expect(err).To(BeNil())
+			Expect(strings.Count(staking, "\n")).To(Equal(2))
+		})
+	})
+
+	Context("GetBlockHeightsAndTimestampsRaw", func() {
+		It("reads the timestamps from the wallet's block headers", func() {
+			wallet, err := mw.CreateNewWallet("wallet", testPrivatePassphrase, PassphraseTypePass)
+			Expect(err).To(BeNil())
+
+			blocks, err := wallet.GetBlockHeightsAndTimestampsRaw(0, 0)
+			Expect(err).To(BeNil())
+			Expect(blocks).To(Equal([]BlockTimestamp{{
+				Height:    0,
+				Timestamp: mw.chainParams.GenesisBlock.Header.Timestamp.Unix(),
+			}}))
+
+			_, err = wallet.GetBlockHeightsAndTimestampsRaw(0, 1)
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrInvalid]))
+		})
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"decred.org/dcrwallet/v2/deployments"
 	"decred.org/dcrwallet/v2/errors"
@@ -22,6 +23,16 @@ const (
 	logFileName   = "dcrlibwallet.log"
 	walletsDbName = "wallets.db"
 
+	// logRollSizeKB is the size a log file may grow to before it is rolled
+	// and defaultLogMaxRolls is the number of rolled files kept by default.
+	logRollSizeKB      = 10 * 1024
+	defaultLogMaxRolls = 3
+
+	// walletsDbLockTimeout is how long to wait for another process to
+	// release the wallets database before failing with
+	// ErrWalletDatabaseInUse.
+	walletsDbLockTimeout = 3 * time.Second
+
 	walletsMetadataBucketName    = "metadata"
 	walletstartupPassphraseField = "startup-passphrase"
 )
@@ -62,7 +73,7 @@ func (mw *MultiWallet) loadWalletTemporarily(ctx context.Context, walletDataDir,
 	}
 
 	// initialize the wallet loader
-	walletLoader := initWalletLoader(mw.chainParams, walletDataDir, mw.dbDriver)
+	walletLoader := initWalletLoader(mw.chainParams, walletDataDir, mw.dbDriver, uint32(DefaultGapLimit))
 
 	// open the wallet to get ready for temporary use
 	wallet, err := walletLoader.OpenExistingWallet(ctx, []byte(walletPublicPass))
@@ -96,6 +107,17 @@ func (mw *MultiWallet) markWalletAsDiscoveredAccounts(walletID int) error {
 	return nil
 }
 
+func (mw *MultiWallet) markWalletAsRescanned(walletID int) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	log.Infof("Set rescan on first sync = false for wallet %d", wallet.ID)
+	wallet.RescanOnFirstSync = false
+	return mw.db.Save(wallet)
+}
+
 // RootDirFileSizeInBytes returns the total directory size of
 // multiwallet's root directory in bytes.
 func (mw *MultiWallet) RootDirFileSizeInBytes() (int64, error) {
@@ -0,0 +1,141 @@
+package dcrlibwallet
+
+// pausedRescan is a rescan canceled because the network became unusable,
+// it is resumed from resumeHeight once the wallets are synced.
+type pausedRescan struct {
+	walletID     int
+	startHeight  int32
+	resumeHeight int32
+}
+
+// SetNetworkAvailable tells the library whether the device is connected to a
+// network. The sync and any rescan are stopped when the network is lost,
+// with OnSyncCanceled(true) reported to the sync progress listeners, and
+// restarted when the network is restored.
+func (mw *MultiWallet) SetNetworkAvailable(available bool) {
+	mw.networkMu.Lock()
+	mw.networkAvailable = available
+	mw.networkMu.Unlock()
+
+	mw.applyNetworkState()
+}
+
+// SetNetworkMetered tells the library whether the device's network connection
+// is metered, such as a cellular connection. The sync is paused on metered
+// networks if SyncOnlyOnUnmeteredNetwork is set.
+func (mw *MultiWallet) SetNetworkMetered(metered bool) {
+	mw.networkMu.Lock()
+	mw.networkMetered = metered
+	mw.networkMu.Unlock()
+
+	mw.applyNetworkState()
+}
+
+// SetSyncOnlyOnUnmeteredNetwork sets whether the wallets are synced while the
+// device's network connection is metered. The setting is saved.
+func (mw *MultiWallet) SetSyncOnlyOnUnmeteredNetwork(unmeteredOnly bool) {
+	mw.SetBoolConfigValueForKey(SyncOnlyOnUnmeteredNetworkConfigKey, unmeteredOnly)
+	mw.applyNetworkState()
+}
+
+func (mw *MultiWallet) SyncOnlyOnUnmeteredNetwork() bool {
+	return mw.ReadBoolConfigValueForKey(SyncOnlyOnUnmeteredNetworkConfigKey, false)
+}
+
+// IsSyncPaused returns true if the sync was stopped, or not started, because
+// the network is unavailable or metered. It is started when a usable network
+// is available.
+func (mw *MultiWallet) IsSyncPaused() bool {
+	mw.networkMu.Lock()
+	defer mw.networkMu.Unlock()
+	return mw.syncPaused
+}
+
+// networkUsable returns true if the network state set by the app allows
+// syncing. mw.networkMu must be held.
+func (mw *MultiWallet) networkUsable() bool {
+	if !mw.networkAvailable {
+		return false
+	}
+	return !mw.networkMetered || !mw.SyncOnlyOnUnmeteredNetwork()
+}
+
+// pauseSyncIfNetworkUnusable marks the sync as paused, to be started when a
+// usable network is available, and returns true if the network is unusable.
+func (mw *MultiWallet) pauseSyncIfNetworkUnusable() bool {
+	mw.networkMu.Lock()
+	defer mw.networkMu.Unlock()
+
+	if mw.networkUsable() {
+		return false
+	}
+	mw.syncPaused = true
+	return true
+}
+
+// applyNetworkState pauses the sync and rescan if the network became unusable
+// and resumes them if a usable network is available again.
+func (mw *MultiWallet) applyNetworkState() {
+	// Read the sync state before taking networkMu, mw.syncData.mu is never
+	// held while taking networkMu and the reverse.
+	connected := mw.IsConnectedToDecredNetwork()
+
+	mw.networkMu.Lock()
+	usable := mw.networkUsable()
+	resume := usable && mw.syncPaused
+	pause := !usable && !mw.syncPaused && connected
+	if resume || pause {
+		mw.syncPaused = pause
+	}
+	mw.networkMu.Unlock()
+
+	switch {
+	case pause:
+		log.Info("Network is unavailable or metered, pausing sync.")
+
+		var rescan *pausedRescan
+		mw.syncData.mu.RLock()
+		if mw.syncData.rescanning && mw.syncData.rescanResumable {
+			rescan = &pausedRescan{
+				walletID:     mw.syncData.rescanWalletID,
+				startHeight:  mw.syncData.rescanStartHeight,
+				resumeHeight: mw.syncData.rescannedThrough + 1,
+			}
+		}
+		mw.syncData.mu.RUnlock()
+
+		if rescan != nil {
+			mw.networkMu.Lock()
+			mw.pausedRescan = rescan
+			mw.networkMu.Unlock()
+		}
+
+		mw.CancelRescan()
+		mw.cancelSync(true)
+
+	case resume:
+		log.Info("Usable network available, resuming sync.")
+		if err := mw.SpvSync(); err != nil {
+			log.Errorf("Error resuming sync: %v", err)
+		}
+	}
+}
+
+// resumePausedRescan starts the rescan stopped when the network became
+// unusable, if any. It is called when the wallets are synced.
+func (mw *MultiWallet) resumePausedRescan() {
+	mw.networkMu.Lock()
+	rescan := mw.pausedRescan
+	mw.pausedRescan = nil
+	mw.networkMu.Unlock()
+
+	if rescan == nil || mw.WalletWithID(rescan.walletID) == nil {
+		return
+	}
+
+	log.Infof("[%d] Resuming blocks rescan from height %d", rescan.walletID, rescan.resumeHeight)
+	err := mw.rescanBlocks(rescan.walletID, rescan.startHeight, rescan.resumeHeight)
+	if err != nil {
+		log.Errorf("[%d] Error resuming blocks rescan: %v", rescan.walletID, err)
+	}
+}
@@ -0,0 +1,122 @@
+package dcrlibwallet
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"decred.org/dcrwallet/v2/errors"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+)
+
+const paymentURIScheme = "decred"
+
+// GeneratePaymentURI returns a decred: URI requesting payment to address.
+// The amount and label are only included if they are set.
+func (mw *MultiWallet) GeneratePaymentURI(address string, amountAtoms int64, label string) (string, error) {
+	if _, err := stdaddr.DecodeAddress(address, mw.chainParams); err != nil {
+		return "", errors.New(ErrInvalidAddress)
+	}
+
+	if amountAtoms < 0 || amountAtoms > dcrutil.MaxAmount {
+		return "", errors.New(ErrInvalid)
+	}
+
+	query := url.Values{}
+	if amountAtoms > 0 {
+		query.Set("amount", formatAmountAtoms(dcrutil.Amount(amountAtoms)))
+	}
+	if label != "" {
+		query.Set("label", label)
+	}
+
+	uri := paymentURIScheme + ":" + address
+	if len(query) > 0 {
+		uri += "?" + query.Encode()
+	}
+
+	return uri, nil
+}
+
+// ParsePaymentURI parses a decred: URI. The address in the URI must be valid
+// for the network the multiwallet is running on.
+func (mw *MultiWallet) ParsePaymentURI(uri string) (*PaymentURI, error) {
+	uri = strings.TrimSpace(uri)
+	scheme := paymentURIScheme + ":"
+	if len(uri) < len(scheme) || !strings.EqualFold(uri[:len(scheme)], scheme) {
+		return nil, errors.New(ErrInvalid)
+	}
+	uri = strings.TrimPrefix(uri[len(scheme):], "//")
+
+	address, rawQuery := uri, ""
+	if i := strings.IndexByte(uri, '?'); i >= 0 {
+		address, rawQuery = uri[:i], uri[i+1:]
+	}
+
+	if _, err := stdaddr.DecodeAddress(address, mw.chainParams); err != nil {
+		return nil, errors.New(ErrInvalidAddress)
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	paymentURI := &PaymentURI{
+		Address: address,
+		Label:   query.Get("label"),
+	}
+
+	if amount := query.Get("amount"); amount != "" {
+		atoms, err := parseAmountAtoms(amount)
+		if err != nil {
+			return nil, err
+		}
+		paymentURI.AmountAtoms = int64(atoms)
+	}
+
+	return paymentURI, nil
+}
+
+// formatAmountAtoms formats amount like AmountString, trimming trailing
+// zeros.
+func formatAmountAtoms(amount dcrutil.Amount) string {
+	return strings.TrimSuffix(strings.TrimRight(AmountString(int64(amount)), "0"), ".")
+}
+
+// parseAmountAtoms is the inverse of formatAmountAtoms. Amounts with more
+// than 8 decimal places are rejected rather than rounded.
+func parseAmountAtoms(amount string) (dcrutil.Amount, error) {
+	wholeStr, fracStr := amount, ""
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		wholeStr, fracStr = amount[:i], amount[i+1:]
+	}
+
+	if (wholeStr == "" && fracStr == "") || len(fracStr) > 8 {
+		return 0, errors.New(ErrInvalid)
+	}
+	if wholeStr == "" {
+		wholeStr = "0"
+	}
+
+	whole, err := strconv.ParseUint(wholeStr, 10, 64)
+	if err != nil || whole > uint64(dcrutil.MaxAmount/dcrutil.AtomsPerCoin) {
+		return 0, errors.New(ErrInvalid)
+	}
+
+	var frac uint64
+	if fracStr != "" {
+		frac, err = strconv.ParseUint(fracStr+strings.Repeat("0", 8-len(fracStr)), 10, 64)
+		if err != nil {
+			return 0, errors.New(ErrInvalid)
+		}
+	}
+
+	atoms := dcrutil.Amount(whole)*dcrutil.AtomsPerCoin + dcrutil.Amount(frac)
+	if atoms > dcrutil.MaxAmount {
+		return 0, errors.New(ErrInvalid)
+	}
+
+	return atoms, nil
+}
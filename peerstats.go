@@ -0,0 +1,115 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// peersFileName is the name of the file in the root directory where the
+// address manager saves the peer addresses it knows.
+const peersFileName = "peers.json"
+
+// SpvPeerStats describes the peer addresses known to the SPV address manager.
+// The address counts are read from the saved peers file, which the address
+// manager updates periodically while syncing and when the sync stops.
+type SpvPeerStats struct {
+	KnownAddresses int `json:"known_addresses"`
+	// GoodAddresses is the number of known addresses that have been
+	// connected to successfully.
+	GoodAddresses int `json:"good_addresses"`
+	// LastDNSSeed is the unix timestamp of the last time peer addresses
+	// were requested from the DNS seeders, or 0 if they never were.
+	LastDNSSeed     int64    `json:"last_dns_seed"`
+	PersistentPeers []string `json:"persistent_peers"`
+	ConnectedPeers  int32    `json:"connected_peers"`
+}
+
+// savedAddrManager is the part of the address manager's peers file needed
+// to count the known addresses.
+type savedAddrManager struct {
+	Addresses    []json.RawMessage
+	TriedBuckets [][]string
+}
+
+// SpvPeerStats returns the json encoded SpvPeerStats. A running sync is not
+// required, ConnectedPeers is 0 when not connected.
+func (mw *MultiWallet) SpvPeerStats() (string, error) {
+	stats, err := mw.SpvPeerStatsRaw()
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(stats)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+func (mw *MultiWallet) SpvPeerStatsRaw() (*SpvPeerStats, error) {
+	stats := &SpvPeerStats{
+		LastDNSSeed:     mw.ReadLongConfigValueForKey(SpvLastDNSSeedConfigKey, 0),
+		PersistentPeers: make([]string, 0),
+		ConnectedPeers:  mw.ConnectedPeers(),
+	}
+
+	peerAddresses := mw.ReadStringConfigValueForKey(SpvPersistentPeerAddressesConfigKey)
+	for _, address := range strings.Split(peerAddresses, ";") {
+		if address = strings.TrimSpace(address); address != "" {
+			stats.PersistentPeers = append(stats.PersistentPeers, address)
+		}
+	}
+
+	peersFile, err := ioutil.ReadFile(filepath.Join(mw.rootDir, peersFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return stats, nil
+		}
+		return nil, err
+	}
+
+	var saved savedAddrManager
+	err = json.Unmarshal(peersFile, &saved)
+	if err != nil {
+		return nil, fmt.Errorf("invalid peers file: %v", err)
+	}
+
+	stats.KnownAddresses = len(saved.Addresses)
+	for _, bucket := range saved.TriedBuckets {
+		stats.GoodAddresses += len(bucket)
+	}
+
+	return stats, nil
+}
+
+// ResetPeerDatabase deletes all the peer addresses known to the SPV address
+// manager, none are kept. New addresses are requested from the DNS seeders on
+// the next sync. A running sync is restarted.
+func (mw *MultiWallet) ResetPeerDatabase() error {
+	// The address manager saves the addresses it knows when the sync stops.
+	restartSync := mw.IsConnectedToDecredNetwork()
+	if restartSync {
+		mw.cancelSync(true)
+	}
+
+	err := os.Remove(filepath.Join(mw.rootDir, peersFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	log.Info("Peer database reset")
+
+	if restartSync {
+		return mw.SpvSync()
+	}
+	return nil
+}
+
+func (mw *MultiWallet) peersSeeded() {
+	mw.SetLongConfigValueForKey(SpvLastDNSSeedConfigKey, time.Now().Unix())
+}
@@ -17,6 +17,7 @@ type Politeia struct {
 	mu                      sync.RWMutex
 	ctx                     context.Context
 	cancelSync              context.CancelFunc
+	cancelScheduledSync     context.CancelFunc
 	client                  *politeiaClient
 	notificationListenersMu sync.RWMutex
 	notificationListeners   map[string]ProposalNotificationListener
@@ -206,7 +207,7 @@ func (p *Politeia) Overview() (*ProposalOverview, error) {
 		return nil, err
 	}
 
-	abandoned, err := p.Count(ProposalCategoryApproved)
+	abandoned, err := p.Count(ProposalCategoryAbandoned)
 	if err != nil {
 		return nil, err
 	}
@@ -331,21 +331,32 @@ func (c *politeiaClient) batchVoteSummary(tokens []string) (map[string]www.VoteS
 }
 
 func (c *politeiaClient) sendVotes(votes []tkv1.CastVote) error {
-	b, err := json.Marshal(&tkv1.CastBallot{Votes: votes})
-	if err != nil {
-		return err
-	}
-
-	var reply tkv1.CastBallotReply
-	err = c.makeRequest(http.MethodPost, ticketVoteApi, tkv1.RouteCastBallot, b, &reply)
+	receipts, err := c.castBallot(votes)
 	if err != nil {
 		return err
 	}
 
-	for _, receipt := range reply.Receipts {
+	for _, receipt := range receipts {
 		if receipt.ErrorContext != "" {
 			return fmt.Errorf(receipt.ErrorContext)
 		}
 	}
 	return nil
 }
+
+// castBallot submits votes and returns the receipt of each vote, votes that
+// were rejected have the ErrorContext of their receipt set.
+func (c *politeiaClient) castBallot(votes []tkv1.CastVote) ([]tkv1.CastVoteReply, error) {
+	b, err := json.Marshal(&tkv1.CastBallot{Votes: votes})
+	if err != nil {
+		return nil, err
+	}
+
+	var reply tkv1.CastBallotReply
+	err = c.makeRequest(http.MethodPost, ticketVoteApi, tkv1.RouteCastBallot, b, &reply)
+	if err != nil {
+		return nil, err
+	}
+
+	return reply.Receipts, nil
+}
@@ -15,7 +15,12 @@ import (
 )
 
 const (
-	retryInterval = 15 // 15 seconds
+	retryInterval    = 15  // 15 seconds
+	maxRetryInterval = 600 // 10 minutes
+
+	// proposalVoteBatchSize is the maximum number of votes submitted in a
+	// single ballot by CastProposalVote.
+	proposalVoteBatchSize = 50
 
 	// VoteBitYes is the string value for identifying "yes" vote bits
 	VoteBitYes = tkv1.VoteOptionIDApprove
@@ -38,17 +43,37 @@ func (p *Politeia) Sync() error {
 	p.ctx, p.cancelSync = p.mwRef.contextWithShutdownCancel()
 	defer p.resetSyncData()
 
+	ctx := p.ctx
 	p.mu.Unlock()
 
+	// Failed requests are retried with an increasing delay to avoid
+	// flooding the server while it is unavailable.
+	retryDelay := retryInterval * time.Second
+	waitToRetry := func() error {
+		select {
+		case <-ctx.Done():
+			return errors.New(ErrContextCanceled)
+		case <-time.After(retryDelay):
+		}
+
+		retryDelay *= 2
+		if retryDelay > maxRetryInterval*time.Second {
+			retryDelay = maxRetryInterval * time.Second
+		}
+		return nil
+	}
+
 	for {
 		_, err := p.getClient()
 		if err != nil {
 			log.Errorf("Error fetching for politeia server policy: %v", err)
-			time.Sleep(retryInterval * time.Second)
+			if err = waitToRetry(); err != nil {
+				return err
+			}
 			continue
 		}
 
-		if done(p.ctx) {
+		if done(ctx) {
 			return errors.New(ErrContextCanceled)
 		}
 
@@ -57,7 +82,9 @@ func (p *Politeia) Sync() error {
 		err = p.checkForUpdates()
 		if err != nil {
 			log.Errorf("Error checking for politeia updates: %v", err)
-			time.Sleep(retryInterval * time.Second)
+			if err = waitToRetry(); err != nil {
+				return err
+			}
 			continue
 		}
 
@@ -83,8 +110,49 @@ func (p *Politeia) resetSyncData() {
 	p.cancelSync = nil
 }
 
+// SyncEvery syncs proposals now and then every interval minutes, until
+// StopSync is called. The proposal notification listeners are notified of
+// changes found by each sync.
+func (p *Politeia) SyncEvery(minutes int32) error {
+	if minutes <= 0 {
+		return errors.New(ErrInvalid)
+	}
+
+	p.mu.Lock()
+	if p.cancelScheduledSync != nil {
+		p.cancelScheduledSync()
+	}
+	ctx, cancel := p.mwRef.contextWithShutdownCancel()
+	p.cancelScheduledSync = cancel
+	p.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(minutes) * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			err := p.Sync()
+			if err != nil && err.Error() != ErrSyncAlreadyInProgress && !done(ctx) {
+				log.Errorf("Scheduled politeia sync failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
 func (p *Politeia) StopSync() {
 	p.mu.Lock()
+	if p.cancelScheduledSync != nil {
+		p.cancelScheduledSync()
+		p.cancelScheduledSync = nil
+	}
 	if p.cancelSync != nil {
 		p.cancelSync()
 		p.resetSyncData()
@@ -93,27 +161,34 @@ func (p *Politeia) StopSync() {
 	log.Info("Politeia sync: stopped")
 }
 
+// checkForUpdates refreshes the saved proposals whose vote has not finished
+// and fetches the proposals in the token inventory that are not saved yet.
+// Proposals that were approved, rejected or abandoned cannot change status
+// and are not fetched again.
 func (p *Politeia) checkForUpdates() error {
-	offset := 0
 	p.mu.RLock()
 	limit := int32(p.client.policy.ProposalListPageSize)
 	p.mu.RUnlock()
 
-	for {
-		proposals, err := p.getProposalsRaw(ProposalCategoryAll, int32(offset), limit, true, true)
+	for _, category := range []int32{ProposalCategoryPre, ProposalCategoryActive} {
+		// Updating proposals may change their category, read all proposals
+		// in the category before updating them in batches.
+		proposals, err := p.getProposalsRaw(category, 0, 0, true, true)
 		if err != nil && err != storm.ErrNotFound {
 			return err
 		}
 
-		if len(proposals) == 0 {
-			break
-		}
-
-		offset += len(proposals)
+		for len(proposals) > 0 {
+			batchSize := len(proposals)
+			if limit > 0 && batchSize > int(limit) {
+				batchSize = int(limit)
+			}
 
-		err = p.handleProposalsUpdate(proposals)
-		if err != nil {
-			return err
+			err = p.handleProposalsUpdate(proposals[:batchSize])
+			if err != nil {
+				return err
+			}
+			proposals = proposals[batchSize:]
 		}
 	}
 
@@ -497,13 +572,111 @@ func (p *Politeia) CastVotes(walletID int, eligibleTickets []*ProposalVote, toke
 		return err
 	}
 
-	err = wal.UnlockWallet([]byte(passphrase))
+	relock, err := wal.unlockTemporarily([]byte(passphrase))
 	if err != nil {
 		return translateError(err)
 	}
-	defer wal.LockWallet()
+	defer relock()
+
+	votes, err := signVotes(wal, detailsReply, token, eligibleTickets)
+	if err != nil {
+		return err
+	}
+
+	return client.sendVotes(votes)
+}
+
+// CastProposalVote votes voteBit, VoteBitYes or VoteBitNo, on the proposal
+// with the provided token with all the wallet's tickets that are eligible and
+// have not voted yet. The votes are submitted in batches and a JSON array of
+// ProposalVoteResult is returned with the result of each ticket's vote. Votes
+// of tickets that failed can be cast again by calling CastProposalVote again.
+func (p *Politeia) CastProposalVote(walletID int, privPass []byte, token, voteBit string) (string, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wal := p.mwRef.WalletWithID(walletID)
+	if wal == nil {
+		return "", fmt.Errorf(ErrWalletNotFound)
+	}
+
+	if voteBit != VoteBitYes && voteBit != VoteBitNo {
+		return "", errors.New(ErrInvalid)
+	}
+
+	voteDetails, err := p.ProposalVoteDetailsRaw(walletID, token)
+	if err != nil {
+		return "", err
+	}
+	if len(voteDetails.EligibleTickets) == 0 {
+		return "", fmt.Errorf("%s: no eligible tickets", ErrNotExist)
+	}
+
+	client, err := p.getClient()
+	if err != nil {
+		return "", err
+	}
+
+	detailsReply, err := client.voteDetails(token)
+	if err != nil {
+		return "", err
+	}
+
+	relock, err := wal.unlockTemporarily(privPass)
+	if err != nil {
+		return "", translateError(err)
+	}
+	defer relock()
+
+	proposalVotes := make([]*ProposalVote, len(voteDetails.EligibleTickets))
+	for i, ticket := range voteDetails.EligibleTickets {
+		proposalVotes[i] = &ProposalVote{Ticket: ticket, Bit: voteBit}
+	}
+
+	votes, err := signVotes(wal, detailsReply, token, proposalVotes)
+	if err != nil {
+		return "", err
+	}
+
+	results := make([]*ProposalVoteResult, 0, len(votes))
+	for len(votes) > 0 {
+		batchSize := len(votes)
+		if batchSize > proposalVoteBatchSize {
+			batchSize = proposalVoteBatchSize
+		}
+		batch := votes[:batchSize]
+		votes = votes[batchSize:]
 
-	votes := make([]tkv1.CastVote, 0)
+		receipts, err := client.castBallot(batch)
+		if err != nil {
+			// Report the whole batch as failed, the votes can be cast
+			// again later.
+			for _, vote := range batch {
+				results = append(results, &ProposalVoteResult{Ticket: vote.Ticket, Error: err.Error()})
+			}
+			continue
+		}
+
+		for _, receipt := range receipts {
+			results = append(results, &ProposalVoteResult{
+				Ticket:  receipt.Ticket,
+				Success: receipt.ErrorContext == "",
+				Error:   receipt.ErrorContext,
+			})
+		}
+	}
+
+	result, _ := json.Marshal(results)
+	return string(result), nil
+}
+
+// signVotes returns the votes for eligibleTickets signed with the commitment
+// address of each ticket. The wallet must be unlocked.
+func signVotes(wal *Wallet, detailsReply *tkv1.DetailsReply, token string, eligibleTickets []*ProposalVote) ([]tkv1.CastVote, error) {
+	votes := make([]tkv1.CastVote, 0, len(eligibleTickets))
 	for _, eligibleTicket := range eligibleTickets {
 		var voteBitHex string
 		// Verify vote bit
@@ -515,7 +688,7 @@ func (p *Politeia) CastVotes(walletID int, eligibleTickets []*ProposalVote, toke
 		}
 
 		if voteBitHex == "" {
-			return errors.New(ErrInvalid)
+			return nil, errors.New(ErrInvalid)
 		}
 
 		ticket := eligibleTicket.Ticket
@@ -524,7 +697,7 @@ func (p *Politeia) CastVotes(walletID int, eligibleTickets []*ProposalVote, toke
 
 		signature, err := wal.signMessage(ticket.Address, msg)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		sigHex := hex.EncodeToString(signature)
@@ -538,7 +711,7 @@ func (p *Politeia) CastVotes(walletID int, eligibleTickets []*ProposalVote, toke
 		votes = append(votes, singleVote)
 	}
 
-	return client.sendVotes(votes)
+	return votes, nil
 }
 
 func (p *Politeia) AddNotificationListener(notificationListener ProposalNotificationListener, uniqueIdentifier string) error {
@@ -0,0 +1,348 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"decred.org/dcrwallet/v2/errors"
+	w "decred.org/dcrwallet/v2/wallet"
+	"decred.org/dcrwallet/v2/wallet/txrules"
+	"decred.org/dcrwallet/v2/wallet/txsizes"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/txscript/v4"
+	"github.com/decred/dcrd/txscript/v4/sign"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	"github.com/decred/dcrd/wire"
+)
+
+// SweepResult describes the transaction published by SweepPrivateKey.
+type SweepResult struct {
+	TxHash string `json:"tx_hash"`
+	// Amount is the value received by the destination account, the total
+	// value of the swept outputs less Fee.
+	Amount int64 `json:"amount"`
+	Fee    int64 `json:"fee"`
+}
+
+// sweepFetchBatchSize is the number of blocks fetched from peers at a time
+// while scanning for the outputs of a swept key.
+const sweepFetchBatchSize = 100
+
+// ImportPrivateKey imports the WIF encoded private key into the imported
+// account of the wallet with the provided id. If rescan is true, the blocks
+// are rescanned from the genesis block to find the transactions of the key's
+// address.
+func (mw *MultiWallet) ImportPrivateKey(walletID int, privPass []byte, wif string, rescan bool) error {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	key, err := decodeWIF(wif, wallet.chainParams)
+	if err != nil {
+		return err
+	}
+
+	// Fail before importing the key if the rescan cannot be started.
+	if rescan && (!mw.IsSynced() || mw.IsRescanning()) {
+		return errors.New(ErrNotConnected)
+	}
+
+	_, err = wallet.importPrivateKey(privPass, key)
+	if err != nil {
+		return err
+	}
+
+	if rescan {
+		return mw.RescanBlocks(walletID)
+	}
+	return nil
+}
+
+// SweepPrivateKey scans the blocks from startHeight for unspent outputs
+// paying to the address of the WIF encoded private key and publishes a
+// transaction that sends all of them to a new address of destAccount. If
+// startHeight is -1, blocks are scanned from the wallet's birthday. The key is
+// not imported into the wallet. The scan is reported by IsRescanning and can
+// be stopped with CancelRescan. ErrNoFundsToSweep is returned if the key's
+// address has no unspent outputs.
+func (mw *MultiWallet) SweepPrivateKey(walletID int, wif string, destAccount int32, startHeight int32) (*SweepResult, error) {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return nil, errors.New(ErrNotExist)
+	}
+
+	key, err := decodeWIF(wif, wallet.chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	if !mw.IsSynced() {
+		return nil, errors.New(ErrNotConnected)
+	}
+
+	if startHeight == -1 {
+		startHeight, err = wallet.birthdayBlockHeight()
+		if err != nil {
+			return nil, translateError(err)
+		}
+	} else if startHeight < 0 || startHeight > wallet.GetBestBlock() {
+		return nil, fmt.Errorf("%s: invalid start height %d", ErrInvalid, startHeight)
+	}
+
+	netBackend, err := wallet.Internal().NetworkBackend()
+	if err != nil {
+		return nil, errors.New(ErrNotConnected)
+	}
+
+	_, err = wallet.GetAccount(destAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := wifAddress(key, wallet.chainParams)
+	if err != nil {
+		return nil, err
+	}
+	_, pkScript := address.PaymentScript()
+
+	ctx, cancel := wallet.shutdownContextWithCancel()
+	defer cancel()
+
+	endRescan, ok := mw.beginRescan(walletID, startHeight, startHeight, false, cancel)
+	if !ok {
+		return nil, errors.New(ErrNotConnected)
+	}
+	outputs, err := wallet.findUnspentOutputs(ctx, netBackend, pkScript, startHeight)
+	endRescan()
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if len(outputs) == 0 {
+		return nil, errors.New(ErrNoFundsToSweep)
+	}
+
+	destAddress, err := wallet.NextAddress(destAccount)
+	if err != nil {
+		return nil, err
+	}
+	destAddr, err := stdaddr.DecodeAddress(destAddress, wallet.chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	sweepTx := wire.NewMsgTx()
+	var total int64
+	for i := range outputs {
+		sweepTx.AddTxIn(wire.NewTxIn(&outputs[i].outPoint, outputs[i].value, nil))
+		total += outputs[i].value
+	}
+
+	scriptVersion, destScript := destAddr.PaymentScript()
+	txOut := &wire.TxOut{Version: scriptVersion, PkScript: destScript}
+	sweepTx.AddTxOut(txOut)
+
+	sigScriptSizes := make([]int, len(outputs))
+	for i := range sigScriptSizes {
+		sigScriptSizes[i] = txsizes.RedeemP2PKHSigScriptSize
+	}
+	feeRate := dcrutil.Amount(mw.TransactionFeeRate())
+	size := txsizes.EstimateSerializeSize(sigScriptSizes, sweepTx.TxOut, 0)
+	fee := txrules.FeeForSerializeSize(feeRate, size)
+	txOut.Value = total - int64(fee)
+	if txrules.IsDustOutput(txOut, feeRate) {
+		return nil, errors.New(ErrOutputIsDust)
+	}
+
+	for i := range sweepTx.TxIn {
+		sigScript, err := sign.SignatureScript(sweepTx, i, pkScript, txscript.SigHashAll,
+			key.PrivKey(), dcrec.STEcdsaSecp256k1, true)
+		if err != nil {
+			return nil, err
+		}
+		sweepTx.TxIn[i].SignatureScript = sigScript
+	}
+
+	hash, err := wallet.Internal().PublishTransaction(ctx, sweepTx, netBackend)
+	if err != nil {
+		return nil, translatePublishError(err)
+	}
+	mw.indexSentTransaction(wallet, hash)
+
+	return &SweepResult{
+		TxHash: hash.String(),
+		Amount: txOut.Value,
+		Fee:    int64(fee),
+	}, nil
+}
+
+// sweepOutput is an unspent output found by findUnspentOutputs.
+type sweepOutput struct {
+	outPoint wire.OutPoint
+	value    int64
+}
+
+// findUnspentOutputs scans the main chain blocks from startHeight for regular
+// transaction outputs paying to pkScript that are not spent by a later
+// transaction. The blocks are matched against their cfilters and only the
+// matching blocks are fetched from peers.
+func (wallet *Wallet) findUnspentOutputs(ctx context.Context, netBackend w.NetworkBackend, pkScript []byte, startHeight int32) ([]sweepOutput, error) {
+	var matches []*chainhash.Hash
+	_, tipHeight := wallet.Internal().MainChainTip(ctx)
+	for height := startHeight; height <= tipHeight; height++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		info, err := wallet.Internal().BlockInfo(ctx, w.NewBlockIdentifierFromHeight(height))
+		if err != nil {
+			return nil, err
+		}
+		key, filter, err := wallet.Internal().CFilterV2(ctx, &info.Hash)
+		if err != nil {
+			return nil, err
+		}
+		// The filters include the scripts of the outputs spent by the
+		// block's transactions, so blocks spending outputs that pay to
+		// pkScript match too.
+		if filter.Match(key, pkScript) {
+			matches = append(matches, &info.Hash)
+		}
+	}
+
+	var outputs []sweepOutput
+	spent := make(map[wire.OutPoint]bool)
+	for len(matches) > 0 {
+		batch := matches
+		if len(batch) > sweepFetchBatchSize {
+			batch = batch[:sweepFetchBatchSize]
+		}
+		matches = matches[len(batch):]
+
+		blocks, err := netBackend.Blocks(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			for i, tx := range block.Transactions {
+				for _, in := range tx.TxIn {
+					spent[in.PreviousOutPoint] = true
+				}
+				// Coinbase outputs are skipped, they may not be
+				// mature yet.
+				if i == 0 {
+					continue
+				}
+				txHash := tx.TxHash()
+				for index, out := range tx.TxOut {
+					if out.Version != 0 || !bytes.Equal(out.PkScript, pkScript) {
+						continue
+					}
+					outputs = append(outputs, sweepOutput{
+						outPoint: *wire.NewOutPoint(&txHash, uint32(index), wire.TxTreeRegular),
+						value:    out.Value,
+					})
+				}
+			}
+			for _, tx := range block.STransactions {
+				for _, in := range tx.TxIn {
+					spent[in.PreviousOutPoint] = true
+				}
+			}
+		}
+	}
+
+	unspent := outputs[:0]
+	for _, output := range outputs {
+		if !spent[output.outPoint] {
+			unspent = append(unspent, output)
+		}
+	}
+	return unspent, nil
+}
+
+// importPrivateKey unlocks the wallet to import key into the imported account
+// and returns the key's address. ErrExist is returned if the key was imported
+// before.
+func (wallet *Wallet) importPrivateKey(privPass []byte, key *dcrutil.WIF) (string, error) {
+	relock, err := wallet.unlockTemporarily(privPass)
+	if err != nil {
+		return "", err
+	}
+	defer relock()
+
+	address, err := wallet.Internal().ImportPrivateKey(wallet.shutdownContext(), key)
+	if err != nil {
+		return "", translateError(err)
+	}
+
+	return address, nil
+}
+
+// decodeWIF decodes wif and checks that it is a private key for the network
+// described by chainParams.
+func decodeWIF(wif string, chainParams *chaincfg.Params) (*dcrutil.WIF, error) {
+	key, err := dcrutil.DecodeWIF(wif, chainParams.PrivateKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid private key for %s: %v", ErrInvalid, chainParams.Name, err)
+	}
+	return key, nil
+}
+
+// wifAddress returns the P2PKH address of the public key of key.
+func wifAddress(key *dcrutil.WIF, chainParams *chaincfg.Params) (stdaddr.Address, error) {
+	pkHash := dcrutil.Hash160(key.PubKey())
+	return stdaddr.NewAddressPubKeyHashEcdsaSecp256k1V0(pkHash, chainParams)
+}
+
+// DumpPrivateKey returns the WIF encoded private key of address, which may be
+// derived from the wallet's HD keys or imported. ErrNotExist is returned if
+// the address does not belong to the wallet.
+func (wallet *Wallet) DumpPrivateKey(privPass []byte, address string) (string, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	if wallet.IsWatchingOnlyWallet() {
+		return "", errors.New(ErrWalletIsWatchOnly)
+	}
+
+	addr, err := stdaddr.DecodeAddress(address, wallet.chainParams)
+	if err != nil {
+		return "", errors.New(ErrInvalidAddress)
+	}
+
+	ctx := wallet.shutdownContext()
+	have, err := wallet.Internal().HaveAddress(ctx, addr)
+	if err != nil {
+		return "", translateError(err)
+	}
+	if !have {
+		return "", errors.New(ErrNotExist)
+	}
+
+	relock, err := wallet.unlockTemporarily(privPass)
+	if err != nil {
+		return "", err
+	}
+	defer relock()
+
+	wif, err := wallet.Internal().DumpWIFPrivateKey(ctx, addr)
+	if err != nil {
+		return "", translateError(err)
+	}
+
+	return wif, nil
+}
@@ -13,7 +13,19 @@ func (mw *MultiWallet) RescanBlocks(walletID int) error {
 	return mw.RescanBlocksFromHeight(walletID, 0)
 }
 
+// RescanBlocksFromHeight rescans blocks from startHeight for transactions
+// relevant to the wallet. If startHeight is -1, blocks are rescanned from the
+// wallet's birthday.
 func (mw *MultiWallet) RescanBlocksFromHeight(walletID int, startHeight int32) error {
+	return mw.rescanBlocks(walletID, startHeight, -1)
+}
+
+// rescanBlocks rescans blocks from scanFromHeight and indexes the wallet's
+// transactions from startHeight. scanFromHeight is higher than startHeight
+// when a paused rescan is resumed, the transactions found before the pause
+// are indexed along with the others when the rescan completes. If
+// scanFromHeight is -1, blocks are rescanned from startHeight.
+func (mw *MultiWallet) rescanBlocks(walletID int, startHeight, scanFromHeight int32) error {
 
 	wallet := mw.WalletWithID(walletID)
 	if wallet == nil {
@@ -25,31 +37,49 @@ func (mw *MultiWallet) RescanBlocksFromHeight(walletID int, startHeight int32) e
 		return errors.E(ErrNotConnected)
 	}
 
-	if mw.IsRescanning() || !mw.IsSynced() {
+	if !mw.IsSynced() {
+		return errors.E(ErrInvalid)
+	}
+
+	if startHeight == -1 {
+		startHeight, err = wallet.birthdayBlockHeight()
+		if err != nil {
+			return translateError(err)
+		}
+	} else if startHeight < 0 {
+		return errors.E(ErrInvalid)
+	}
+
+	if scanFromHeight < startHeight {
+		scanFromHeight = startHeight
+	}
+
+	ctx, cancel := wallet.shutdownContextWithCancel()
+	endRescan, ok := mw.beginRescan(walletID, startHeight, scanFromHeight, true, cancel)
+	if !ok {
+		cancel()
 		return errors.E(ErrInvalid)
 	}
 
 	go func() {
-		defer func() {
-			mw.syncData.mu.Lock()
-			mw.syncData.rescanning = false
-			mw.syncData.cancelRescan = nil
-			mw.syncData.mu.Unlock()
-		}()
+		// Set if a restored wallet completes its first full rescan, to
+		// start the rescan for other restored wallets, if any.
+		var rescannedRestoredWallet bool
 
-		ctx, cancel := wallet.shutdownContextWithCancel()
+		defer func() {
+			endRescan()
 
-		mw.syncData.mu.Lock()
-		mw.syncData.rescanning = true
-		mw.syncData.cancelRescan = cancel
-		mw.syncData.mu.Unlock()
+			if rescannedRestoredWallet {
+				mw.rescanRestoredWallets()
+			}
+		}()
 
 		if mw.blocksRescanProgressListener != nil {
 			mw.blocksRescanProgressListener.OnBlocksRescanStarted(walletID)
 		}
 
 		progress := make(chan w.RescanProgress, 1)
-		go wallet.Internal().RescanProgressFromHeight(ctx, netBackend, startHeight, progress)
+		go wallet.Internal().RescanProgressFromHeight(ctx, netBackend, scanFromHeight, progress)
 
 		rescanStartTime := time.Now().Unix()
 
@@ -62,6 +92,10 @@ func (mw *MultiWallet) RescanBlocksFromHeight(walletID int, startHeight int32) e
 				return
 			}
 
+			mw.syncData.mu.Lock()
+			mw.syncData.rescannedThrough = p.ScannedThrough
+			mw.syncData.mu.Unlock()
+
 			rescanProgressReport := &HeadersRescanProgressReport{
 				CurrentRescanHeight: p.ScannedThrough,
 				TotalHeadersToScan:  wallet.GetBestBlock(),
@@ -116,6 +150,10 @@ func (mw *MultiWallet) RescanBlocksFromHeight(walletID int, startHeight int32) e
 
 			err = wallet.IndexTransactions()
 		}
+		if err == nil && wallet.RescanOnFirstSync {
+			err = mw.markWalletAsRescanned(walletID)
+			rescannedRestoredWallet = err == nil
+		}
 		if mw.blocksRescanProgressListener != nil {
 			mw.blocksRescanProgressListener.OnBlocksRescanEnded(walletID, err)
 		}
@@ -124,6 +162,60 @@ func (mw *MultiWallet) RescanBlocksFromHeight(walletID int, startHeight int32) e
 	return nil
 }
 
+// beginRescan marks a rescan of the wallet with walletID from startHeight,
+// which starts scanning at scanFromHeight, as in progress, so that
+// IsRescanning returns true and CancelRescan calls cancel, and returns the
+// func that marks the rescan as ended. A resumable rescan is started again if it is paused by the
+// loss of the network. False is returned if another rescan is in progress.
+func (mw *MultiWallet) beginRescan(walletID int, startHeight, scanFromHeight int32, resumable bool, cancel context.CancelFunc) (func(), bool) {
+	mw.syncData.mu.Lock()
+	defer mw.syncData.mu.Unlock()
+
+	if mw.syncData.rescanning {
+		return nil, false
+	}
+
+	rescanDone := make(chan struct{})
+	mw.syncData.rescanning = true
+	mw.syncData.rescanWalletID = walletID
+	mw.syncData.rescanStartHeight = startHeight
+	mw.syncData.rescannedThrough = scanFromHeight - 1
+	mw.syncData.rescanResumable = resumable
+	mw.syncData.cancelRescan = cancel
+	mw.syncData.rescanDone = rescanDone
+
+	return func() {
+		mw.syncData.mu.Lock()
+		mw.syncData.rescanning = false
+		mw.syncData.cancelRescan = nil
+		mw.syncData.rescanDone = nil
+		mw.syncData.mu.Unlock()
+		close(rescanDone)
+	}, true
+}
+
+// rescanRestoredWallets starts a full rescan for the first restored wallet
+// that hasn't been rescanned since it was restored. The next restored wallet
+// is rescanned after the current rescan completes.
+func (mw *MultiWallet) rescanRestoredWallets() {
+	if mw.IsRescanning() {
+		return
+	}
+
+	for _, wallet := range mw.wallets {
+		if !wallet.RescanOnFirstSync || !wallet.WalletOpened() {
+			continue
+		}
+
+		log.Infof("[%d] Rescanning blocks for restored wallet", wallet.ID)
+		err := mw.RescanBlocksFromHeight(wallet.ID, -1)
+		if err != nil {
+			log.Errorf("[%d] Error rescanning blocks for restored wallet: %v", wallet.ID, err)
+		}
+		return
+	}
+}
+
 func (mw *MultiWallet) CancelRescan() {
 	mw.syncData.mu.Lock()
 	defer mw.syncData.mu.Unlock()
@@ -0,0 +1,169 @@
+package dcrlibwallet
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"decred.org/dcrwallet/v2/errors"
+	"github.com/kevinburke/nacl"
+	"github.com/kevinburke/nacl/secretbox"
+)
+
+// A secret stream is data encrypted in chunks sealed with secretbox, so that
+// data of any size can be encrypted and decrypted without holding all of it
+// in memory. The stream starts with a random nonce prefix. Each chunk is
+// sealed with a nonce made of the prefix, the index of the chunk and whether
+// it is the last chunk, so reordered, dropped and truncated chunks fail to
+// open. Every chunk but the last holds secretStreamChunkSize bytes of data,
+// the last chunk holds less.
+const (
+	secretStreamChunkSize  = 64 * 1024
+	secretStreamPrefixSize = nacl.NonceSize - 8 - 1
+)
+
+// errSecretStreamCorrupt is returned when a chunk of a secret stream fails
+// to open, because the key is wrong or the stream was modified or truncated.
+var errSecretStreamCorrupt = errors.New("encrypted stream is corrupt or truncated")
+
+func secretStreamNonce(prefix []byte, chunk uint64, last bool) nacl.Nonce {
+	nonce := new([nacl.NonceSize]byte)
+	copy(nonce[:], prefix)
+	binary.BigEndian.PutUint64(nonce[secretStreamPrefixSize:], chunk)
+	if last {
+		nonce[nacl.NonceSize-1] = 1
+	}
+	return nonce
+}
+
+// secretStreamWriter encrypts the data written to it to a secret stream.
+// Close must be called to write the last chunk.
+type secretStreamWriter struct {
+	w      io.Writer
+	key    nacl.Key
+	prefix [secretStreamPrefixSize]byte
+	chunk  uint64
+	data   []byte
+	box    []byte
+}
+
+func newSecretStreamWriter(w io.Writer, key nacl.Key) (*secretStreamWriter, error) {
+	s := &secretStreamWriter{
+		w:    w,
+		key:  key,
+		data: make([]byte, 0, secretStreamChunkSize),
+		box:  make([]byte, 0, secretStreamChunkSize+secretbox.Overhead),
+	}
+
+	_, err := rand.Read(s.prefix[:])
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(s.prefix[:])
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *secretStreamWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		n := copy(s.data[len(s.data):cap(s.data)], p)
+		s.data = s.data[:len(s.data)+n]
+		p = p[n:]
+		written += n
+
+		// A full chunk is sealed right away, so the last chunk, sealed by
+		// Close, is always shorter than the others.
+		if len(s.data) == secretStreamChunkSize {
+			if err := s.seal(false); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close writes the last chunk of the stream. It does not close the
+// underlying writer.
+func (s *secretStreamWriter) Close() error {
+	return s.seal(true)
+}
+
+func (s *secretStreamWriter) seal(last bool) error {
+	s.box = secretbox.Seal(s.box[:0], s.data, secretStreamNonce(s.prefix[:], s.chunk, last), s.key)
+	s.data = s.data[:0]
+	s.chunk++
+
+	_, err := s.w.Write(s.box)
+	return err
+}
+
+// secretStreamReader decrypts a secret stream. It returns
+// errSecretStreamCorrupt if a chunk fails to open.
+type secretStreamReader struct {
+	r      io.Reader
+	key    nacl.Key
+	prefix [secretStreamPrefixSize]byte
+	chunk  uint64
+	last   bool
+	plain  []byte
+	data   []byte // the unread part of plain
+	box    []byte
+}
+
+func newSecretStreamReader(r io.Reader, key nacl.Key) (*secretStreamReader, error) {
+	s := &secretStreamReader{
+		r:     r,
+		key:   key,
+		plain: make([]byte, 0, secretStreamChunkSize),
+		box:   make([]byte, secretStreamChunkSize+secretbox.Overhead),
+	}
+
+	_, err := io.ReadFull(r, s.prefix[:])
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, errSecretStreamCorrupt
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *secretStreamReader) Read(p []byte) (int, error) {
+	for len(s.data) == 0 {
+		if s.last {
+			return 0, io.EOF
+		}
+		if err := s.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, s.data)
+	s.data = s.data[n:]
+	return n, nil
+}
+
+func (s *secretStreamReader) open() error {
+	n, err := io.ReadFull(s.r, s.box)
+	switch err {
+	case nil:
+	case io.ErrUnexpectedEOF:
+		// Only the last chunk is shorter than a full chunk.
+		s.last = true
+	case io.EOF:
+		return errSecretStreamCorrupt
+	default:
+		return err
+	}
+
+	plain, ok := secretbox.Open(s.plain[:0], s.box[:n], secretStreamNonce(s.prefix[:], s.chunk, s.last), s.key)
+	if !ok {
+		return errSecretStreamCorrupt
+	}
+	s.data = plain
+	s.chunk++
+	return nil
+}
@@ -0,0 +1,110 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"fmt"
+
+	"decred.org/dcrwallet/v2/errors"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript/v4"
+	"github.com/decred/dcrd/wire"
+)
+
+// SignedTransaction is the result of SignTransaction.
+type SignedTransaction struct {
+	SerializedTx []byte `json:"serialized_tx"`
+	// UnsignedInputs are the indexes of the inputs that the wallet could
+	// not sign, the transaction can only be broadcast if it is empty.
+	UnsignedInputs []int32 `json:"unsigned_inputs"`
+}
+
+// Complete returns true if all the inputs of the transaction are signed.
+func (tx *SignedTransaction) Complete() bool {
+	return len(tx.UnsignedInputs) == 0
+}
+
+// SignTransaction signs the inputs of the serialized transaction that spend
+// outputs of the wallet. The signature scripts of the other inputs, including
+// those spending outputs of other parties, are not changed and their indexes
+// are returned in the result's UnsignedInputs.
+func (wallet *Wallet) SignTransaction(privPass []byte, serializedTx []byte) (*SignedTransaction, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	var msgTx wire.MsgTx
+	err := msgTx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid transaction: %v", ErrInvalid, err)
+	}
+	if len(msgTx.TxIn) == 0 {
+		return nil, fmt.Errorf("%s: transaction has no inputs", ErrInvalid)
+	}
+
+	if wallet.IsWatchingOnlyWallet() {
+		return nil, errors.New(ErrWalletIsWatchOnly)
+	}
+
+	relock, err := wallet.unlockTemporarily(privPass)
+	if err != nil {
+		return nil, err
+	}
+	defer relock()
+
+	// Inputs the wallet cannot sign keep their original signature scripts.
+	sigScripts := make([][]byte, len(msgTx.TxIn))
+	for i, txIn := range msgTx.TxIn {
+		sigScripts[i] = txIn.SignatureScript
+	}
+
+	foreignPrevScripts, err := wallet.foreignPrevScripts(&msgTx)
+	if err != nil {
+		return nil, err
+	}
+
+	sigErrs, err := wallet.Internal().SignTransaction(wallet.shutdownContext(), &msgTx, txscript.SigHashAll, foreignPrevScripts, nil, nil)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	unsignedInputs := make([]int32, 0, len(sigErrs))
+	for _, sigErr := range sigErrs {
+		log.Debugf("[%d] Input %d not signed: %v", wallet.ID, sigErr.InputIndex, sigErr.Error)
+		msgTx.TxIn[sigErr.InputIndex].SignatureScript = sigScripts[sigErr.InputIndex]
+		unsignedInputs = append(unsignedInputs, int32(sigErr.InputIndex))
+	}
+
+	var txBuf bytes.Buffer
+	txBuf.Grow(msgTx.SerializeSize())
+	err = msgTx.Serialize(&txBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedTransaction{
+		SerializedTx:   txBuf.Bytes(),
+		UnsignedInputs: unsignedInputs,
+	}, nil
+}
+
+// foreignPrevScripts returns empty previous output scripts for the inputs of
+// tx that spend outputs of transactions not in the wallet. dcrwallet fails to
+// sign the whole transaction when it cannot find the previous output of an
+// input, while an empty script only fails to sign that input.
+func (wallet *Wallet) foreignPrevScripts(tx *wire.MsgTx) (map[wire.OutPoint][]byte, error) {
+	ctx := wallet.shutdownContext()
+	prevScripts := make(map[wire.OutPoint][]byte)
+	for _, txIn := range tx.TxIn {
+		prevOut := txIn.PreviousOutPoint
+		prevTxs, _, err := wallet.Internal().GetTransactionsByHashes(ctx, []*chainhash.Hash{&prevOut.Hash})
+		if err != nil && !errors.Is(err, errors.NotExist) {
+			return nil, translateError(err)
+		}
+		if len(prevTxs) == 0 || int(prevOut.Index) >= len(prevTxs[0].TxOut) {
+			prevScripts[prevOut] = []byte{}
+		}
+	}
+	return prevScripts, nil
+}
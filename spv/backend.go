@@ -49,6 +49,7 @@ func (wb *WalletBackend) Blocks(ctx context.Context, blockHashes []*chainhash.Ha
 		if err != nil {
 			continue
 		}
+		wb.addBlocksDataUsage(blocks)
 		return blocks, nil
 	}
 }
@@ -75,6 +76,7 @@ func (wb *WalletBackend) CFiltersV2(ctx context.Context, blockHashes []*chainhas
 		if err != nil {
 			continue
 		}
+		wb.addCFiltersDataUsage(fs)
 		return fs, nil
 	}
 }
@@ -93,6 +95,7 @@ func (wb *WalletBackend) Headers(ctx context.Context, blockLocators []*chainhash
 		if err != nil {
 			continue
 		}
+		wb.addHeadersDataUsage(len(blockLocators), hs)
 		return hs, nil
 	}
 }
@@ -259,6 +262,7 @@ FilterLoop:
 					rp = nil
 					continue PickPeer
 				}
+				wb.addBlocksDataUsage(blocks)
 
 				for j, b := range blocks {
 					// Validate fetched blocks before rescanning transactions.  PoW
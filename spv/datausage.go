@@ -0,0 +1,169 @@
+package spv
+
+import (
+	"context"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+)
+
+// Sync stages for which the data exchanged with peers is counted.
+const (
+	DataStageHeaders = iota
+	DataStageCFilters
+	DataStageBlocks
+	DataStageTransactions
+	numDataStages
+)
+
+const (
+	// msgHeaderSize is the size of the header of each wire message.
+	msgHeaderSize = 24
+
+	// hashSize is the serialized size of a hash in inventory vectors and
+	// block locators.
+	hashSize = chainhash.HashSize
+
+	// invVectSize is the serialized size of an inventory vector.
+	invVectSize = 4 + hashSize
+
+	// varIntSize is the maximum serialized size of a length prefix.
+	varIntSize = 9
+
+	// dataUsageNotifyInterval is the minimum time between calls of the
+	// DataUsageUpdated callback.
+	dataUsageNotifyInterval = time.Second
+)
+
+// DataUsage is the number of bytes received from and sent to peers in each
+// sync stage since the syncer was created. The counts are estimates computed
+// from the serialized size of the wire messages, not the bytes actually
+// transferred, and do not include the transport overhead.
+type DataUsage struct {
+	Received [numDataStages]int64
+	Sent     [numDataStages]int64
+}
+
+// TotalReceived returns the number of bytes received in all stages.
+func (u *DataUsage) TotalReceived() int64 {
+	var total int64
+	for _, n := range u.Received {
+		total += n
+	}
+	return total
+}
+
+// TotalSent returns the number of bytes sent in all stages.
+func (u *DataUsage) TotalSent() int64 {
+	var total int64
+	for _, n := range u.Sent {
+		total += n
+	}
+	return total
+}
+
+// DataUsage returns the number of bytes exchanged with peers so far.
+func (s *Syncer) DataUsage() DataUsage {
+	s.dataUsageMu.Lock()
+	defer s.dataUsageMu.Unlock()
+	return s.dataUsage
+}
+
+// addDataUsage adds received and sent bytes to the counts of stage. The
+// DataUsageUpdated callback is notified of the new counts by
+// notifyDataUsage.
+func (s *Syncer) addDataUsage(stage int, received, sent int) {
+	s.dataUsageMu.Lock()
+	s.dataUsage.Received[stage] += int64(received)
+	s.dataUsage.Sent[stage] += int64(sent)
+	s.dataUsageMu.Unlock()
+}
+
+// notifyDataUsage calls the DataUsageUpdated callback with the counts of the
+// data exchanged with peers at most once every dataUsageNotifyInterval, when
+// they have changed, and once more when ctx is canceled. The callback is only
+// called from this goroutine so the counts it receives never decrease.
+func (s *Syncer) notifyDataUsage(ctx context.Context) {
+	if s.notifications == nil || s.notifications.DataUsageUpdated == nil {
+		return
+	}
+
+	var notified DataUsage
+	notify := func() {
+		usage := s.DataUsage()
+		if usage != notified {
+			notified = usage
+			s.notifications.DataUsageUpdated(usage)
+		}
+	}
+
+	ticker := time.NewTicker(dataUsageNotifyInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			notify()
+		case <-ctx.Done():
+			notify()
+			return
+		}
+	}
+}
+
+// getDataSize returns the size of a getdata message requesting n items.
+func getDataSize(n int) int {
+	return msgHeaderSize + varIntSize + n*invVectSize
+}
+
+func (s *Syncer) addHeadersDataUsage(locators int, headers []*wire.BlockHeader) {
+	received := msgHeaderSize + varIntSize + len(headers)*(wire.MaxBlockHeaderPayload+1)
+	sent := msgHeaderSize + 4 + varIntSize + (locators+1)*hashSize
+	s.addDataUsage(DataStageHeaders, received, sent)
+}
+
+// cfilterSize returns the size of a cfilter message with filter and proof.
+func cfilterSize(filter []byte, proof []chainhash.Hash) int {
+	return msgHeaderSize + hashSize + 4 + 2*varIntSize + len(filter) + len(proof)*hashSize
+}
+
+// addCFilterDataUsage counts n cfilter messages of the received total size,
+// each requested with a separate getcfilter message.
+func (s *Syncer) addCFilterDataUsage(received, n int) {
+	s.addDataUsage(DataStageCFilters, received, n*(msgHeaderSize+hashSize))
+}
+
+func (s *Syncer) addCFiltersDataUsage(filters []filterProof) {
+	received := msgHeaderSize + varIntSize
+	for _, f := range filters {
+		received += hashSize + 4 + 2*varIntSize + len(f.Filter.Bytes()) + len(f.Proof)*hashSize
+	}
+	s.addDataUsage(DataStageCFilters, received, getDataSize(len(filters)))
+}
+
+func (s *Syncer) addBlocksDataUsage(blocks []*wire.MsgBlock) {
+	received := 0
+	for _, b := range blocks {
+		received += msgHeaderSize + b.SerializeSize()
+	}
+	s.addDataUsage(DataStageBlocks, received, getDataSize(len(blocks)))
+}
+
+func (s *Syncer) addTransactionsDataUsage(txs []*wire.MsgTx, requested int) {
+	received := 0
+	for _, tx := range txs {
+		if tx != nil {
+			received += msgHeaderSize + tx.SerializeSize()
+		}
+	}
+	s.addDataUsage(DataStageTransactions, received, getDataSize(requested))
+}
+
+// addPublishedDataUsage counts the transactions sent to a peer.
+func (s *Syncer) addPublishedDataUsage(txs []*wire.MsgTx) {
+	sent := 0
+	for _, tx := range txs {
+		sent += msgHeaderSize + tx.SerializeSize()
+	}
+	s.addDataUsage(DataStageTransactions, 0, sent)
+}
@@ -42,12 +42,27 @@ type Syncer struct {
 	// Protected by atomicCatchUpTryLock
 	loadedFilters map[int]bool
 
+	// fastSyncHeights returns the heights from which the wallets set with
+	// SetFastInitialSync are rescanned. Protected by atomicCatchUpTryLock
+	// once the syncer is running.
+	fastSyncHeights map[int]func() (int32, error)
+
 	persistentPeers []string
 
+	// runCtx is the context passed to Run. It is used to start connections
+	// to persistent peers that are added while the syncer is running.
+	runCtx           context.Context
+	persistentCancel map[string]context.CancelFunc
+	persistentMu     sync.Mutex
+
 	connectingRemotes map[string]struct{}
 	remotes           map[string]*p2p.RemotePeer
 	remotesMu         sync.Mutex
 
+	// lastAnnouncedHeights records the height of the last block header
+	// announced by each remote peer, keyed by the peer's address.
+	lastAnnouncedHeights map[string]int32
+
 	// Data filters
 	//
 	// TODO: Replace precise rescan filter with wallet db accesses to avoid
@@ -73,6 +88,10 @@ type Syncer struct {
 	// Holds all potential callbacks used to notify clients
 	notifications *Notifications
 
+	// dataUsage counts the bytes exchanged with peers in each sync stage.
+	dataUsage   DataUsage
+	dataUsageMu sync.Mutex
+
 	// Mempool for non-wallet-relevant transactions.
 	mempool     sync.Map // k=chainhash.Hash v=*wire.MsgTx
 	mempoolAdds chan *chainhash.Hash
@@ -96,6 +115,14 @@ type Notifications struct {
 	RescanProgress               func(walletID int, rescannedThrough int32)
 	RescanFinished               func(walletID int)
 
+	// PeersSeeded is called when peer addresses are requested from the DNS
+	// seeders.
+	PeersSeeded func()
+
+	// DataUsageUpdated is called with the estimated total data exchanged
+	// with peers, at most once a second while the counts change.
+	DataUsageUpdated func(usage DataUsage)
+
 	// MempoolTxs is called whenever new relevant unmined transactions are
 	// observed and saved.
 	MempoolTxs func(walletID int, txs []*wire.MsgTx)
@@ -125,17 +152,20 @@ func NewSyncer(wallets map[int]*wallet.Wallet, lp *p2p.LocalPeer) *Syncer {
 	}
 
 	return &Syncer{
-		atomicWalletsSynced: atomicWalletsSynced,
-		wallets:             wallets,
-		loadedFilters:       make(map[int]bool, len(wallets)),
-		connectingRemotes:   make(map[string]struct{}),
-		remotes:             make(map[string]*p2p.RemotePeer),
-		rescanFilter:        rescanFilter,
-		filterData:          filterData,
-		seenTxs:             lru.NewCache(2000),
-		sidechains:          sidechains,
-		lp:                  lp,
-		mempoolAdds:         make(chan *chainhash.Hash),
+		atomicWalletsSynced:  atomicWalletsSynced,
+		wallets:              wallets,
+		loadedFilters:        make(map[int]bool, len(wallets)),
+		fastSyncHeights:      make(map[int]func() (int32, error)),
+		persistentCancel:     make(map[string]context.CancelFunc),
+		connectingRemotes:    make(map[string]struct{}),
+		remotes:              make(map[string]*p2p.RemotePeer),
+		lastAnnouncedHeights: make(map[string]int32),
+		rescanFilter:         rescanFilter,
+		filterData:           filterData,
+		seenTxs:              lru.NewCache(2000),
+		sidechains:           sidechains,
+		lp:                   lp,
+		mempoolAdds:          make(chan *chainhash.Hash),
 	}
 }
 
@@ -145,6 +175,15 @@ func (s *Syncer) SetPersistentPeers(peers []string) {
 	s.persistentPeers = peers
 }
 
+// SetFastInitialSync skips address discovery during the initial sync of the
+// wallet with the provided id and only rescans the blocks from the height
+// returned by fromHeight, which is called after the headers are fetched.
+// This must only be used for new wallets whose addresses have not been used
+// before that height and must be called before Run.
+func (s *Syncer) SetFastInitialSync(walletID int, fromHeight func() (int32, error)) {
+	s.fastSyncHeights[walletID] = fromHeight
+}
+
 // SetNotifications sets the possible various callbacks that are used
 // to notify interested parties to the syncing progress.
 func (s *Syncer) SetNotifications(ntfns *Notifications) {
@@ -197,6 +236,75 @@ func (s *Syncer) GetRemotePeers() map[string]*p2p.RemotePeer {
 	return remotes
 }
 
+// LastAnnouncedHeight returns the height of the last block header announced
+// by rp, or its initial height if the peer has not announced any headers yet.
+func (s *Syncer) LastAnnouncedHeight(rp *p2p.RemotePeer) int32 {
+	s.remotesMu.Lock()
+	defer s.remotesMu.Unlock()
+
+	if height, ok := s.lastAnnouncedHeights[rp.String()]; ok {
+		return height
+	}
+	return rp.InitialHeight()
+}
+
+// AddPersistentPeer connects to the peer at raddr and keeps reconnecting to
+// it whenever the connection is lost, until the peer is removed with
+// RemovePeer or the syncer stops running.
+func (s *Syncer) AddPersistentPeer(raddr string) error {
+	s.persistentMu.Lock()
+	defer s.persistentMu.Unlock()
+
+	if s.runCtx == nil || s.runCtx.Err() != nil {
+		return errors.E(errors.Invalid, "syncer is not running")
+	}
+	if _, ok := s.persistentCancel[raddr]; ok {
+		return errors.E(errors.Exist, "peer is already a persistent peer")
+	}
+
+	ctx, cancel := context.WithCancel(s.runCtx)
+	s.persistentCancel[raddr] = cancel
+	s.persistentPeers = append(s.persistentPeers, raddr)
+	go s.connectToPersistent(ctx, raddr)
+	return nil
+}
+
+// RemovePeer stops reconnecting to the persistent peer at raddr, if any, and
+// disconnects the remote peer connected at that address.
+func (s *Syncer) RemovePeer(raddr string) error {
+	s.persistentMu.Lock()
+	cancel, isPersistent := s.persistentCancel[raddr]
+	if isPersistent {
+		cancel()
+		delete(s.persistentCancel, raddr)
+		for i, peer := range s.persistentPeers {
+			if peer == raddr {
+				s.persistentPeers = append(s.persistentPeers[:i], s.persistentPeers[i+1:]...)
+				break
+			}
+		}
+	}
+	s.persistentMu.Unlock()
+
+	s.remotesMu.Lock()
+	var matches []*p2p.RemotePeer
+	for k, rp := range s.remotes {
+		if k == raddr || rp.String() == raddr {
+			matches = append(matches, rp)
+		}
+	}
+	s.remotesMu.Unlock()
+
+	if !isPersistent && len(matches) == 0 {
+		return errors.E(errors.NotExist, "peer is not connected")
+	}
+
+	for _, rp := range matches {
+		rp.Disconnect(errors.E("peer removed"))
+	}
+	return nil
+}
+
 // unsynced checks the atomic that controls wallet syncness and if previously
 // synced, updates to unsynced and notifies the callback, if set.
 func (s *Syncer) unsynced(walletID int) {
@@ -393,6 +501,9 @@ func (s *Syncer) Run(ctx context.Context) error {
 	// Seed peers over DNS when not disabled by persistent peers.
 	if len(s.persistentPeers) == 0 {
 		s.lp.SeedPeers(ctx, wire.SFNodeNetwork|wire.SFNodeCF)
+		if s.notifications != nil && s.notifications.PeersSeeded != nil {
+			s.notifications.PeersSeeded()
+		}
 	}
 
 	// Start background handlers to read received messages from remote peers
@@ -402,16 +513,32 @@ func (s *Syncer) Run(ctx context.Context) error {
 	g.Go(func() error { return s.receiveHeadersAnnouncements(ctx) })
 	s.lp.AddHandledMessages(p2p.MaskGetData | p2p.MaskInv)
 
+	s.persistentMu.Lock()
+	s.runCtx = ctx
 	if len(s.persistentPeers) != 0 {
 		for i := range s.persistentPeers {
 			raddr := s.persistentPeers[i]
-			g.Go(func() error { return s.connectToPersistent(ctx, raddr) })
+			peerCtx, cancel := context.WithCancel(ctx)
+			s.persistentCancel[raddr] = cancel
+			g.Go(func() error {
+				err := s.connectToPersistent(peerCtx, raddr)
+				if ctx.Err() == nil {
+					// The peer was removed, the syncer is still running.
+					return nil
+				}
+				return err
+			})
 		}
 	} else {
 		g.Go(func() error { return s.connectToCandidates(ctx) })
 	}
+	s.persistentMu.Unlock()
 
 	g.Go(func() error { return s.handleMempool(ctx) })
+	g.Go(func() error {
+		s.notifyDataUsage(ctx)
+		return nil
+	})
 
 	for walletID, w := range s.wallets {
 		walletBackend := &WalletBackend{
@@ -501,6 +628,7 @@ func (s *Syncer) connectToPersistent(ctx context.Context, raddr string) error {
 			err = rp.Err()
 			s.remotesMu.Lock()
 			delete(s.remotes, k)
+			delete(s.lastAnnouncedHeights, rp.String())
 			n = len(s.remotes)
 			s.remotesMu.Unlock()
 			s.peerDisconnected(n, k)
@@ -591,6 +719,7 @@ func (s *Syncer) connectToCandidates(ctx context.Context) error {
 			<-wait
 			s.remotesMu.Lock()
 			delete(s.remotes, raddr)
+			delete(s.lastAnnouncedHeights, rp.String())
 			n = len(s.remotes)
 			s.remotesMu.Unlock()
 			s.peerDisconnected(n, raddr)
@@ -721,6 +850,7 @@ func (s *Syncer) receiveGetData(ctx context.Context) error {
 			}
 
 			// Send all found transactions
+			s.addPublishedDataUsage(foundTxs)
 			for _, tx := range foundTxs {
 				err := rp.SendMessage(ctx, tx)
 				if ctx.Err() != nil {
@@ -821,6 +951,7 @@ func (s *Syncer) handleBlockInvs(ctx context.Context, rp *p2p.RemotePeer, hashes
 		op := errors.Opf(opf, rp)
 		return errors.E(op, err)
 	}
+	s.addBlocksDataUsage(blocks)
 	headers := make([]*wire.BlockHeader, len(blocks))
 	bmap := make(map[chainhash.Hash]*wire.MsgBlock)
 	for i, block := range blocks {
@@ -870,6 +1001,7 @@ func (s *Syncer) handleTxInvsForWallet(ctx context.Context, rp *p2p.RemotePeer,
 	}
 
 	txs, err := rp.Transactions(ctx, unseen)
+	s.addTransactionsDataUsage(txs, len(unseen))
 	if errors.Is(err, errors.NotExist) {
 		err = nil
 		// Remove notfound txs.
@@ -922,6 +1054,12 @@ func (s *Syncer) receiveHeadersAnnouncements(ctx context.Context) error {
 			return err
 		}
 
+		if len(headers) != 0 {
+			s.remotesMu.Lock()
+			s.lastAnnouncedHeights[rp.String()] = int32(headers[len(headers)-1].Height)
+			s.remotesMu.Unlock()
+		}
+
 		go func() {
 			err := s.handleBlockAnnouncements(ctx, rp, headers, nil)
 			if err != nil {
@@ -1010,6 +1148,7 @@ FilterLoop:
 			if err != nil {
 				return nil, err
 			}
+			s.addBlocksDataUsage(blocks)
 			for j, b := range blocks {
 				i := fmatchidx[j]
 
@@ -1196,6 +1335,7 @@ func (s *Syncer) handleBlockAnnouncementsForWallet(ctx context.Context, walletID
 			}
 			return err
 		}
+		s.addCFiltersDataUsage(filters)
 
 		for i, cf := range filters {
 			filter, proofIndex, proof := cf.Filter, cf.ProofIndex, cf.Proof
@@ -1302,6 +1442,7 @@ func (s *Syncer) getHeaders(ctx context.Context, rp *p2p.RemotePeer) error {
 		if err != nil {
 			return err
 		}
+		s.addHeadersDataUsage(len(locators), headers)
 
 		if len(headers) == 0 {
 			// Ensure that the peer provided headers through the height
@@ -1323,6 +1464,7 @@ func (s *Syncer) getHeaders(ctx context.Context, rp *p2p.RemotePeer) error {
 		lastHeight = int32(headers[len(headers)-1].Height)
 
 		nodes := make([]*wallet.BlockNode, len(headers))
+		cfilterSizes := make([]int, len(headers))
 		g, ctx := errgroup.WithContext(ctx)
 		for i := range headers {
 			i := i
@@ -1333,6 +1475,7 @@ func (s *Syncer) getHeaders(ctx context.Context, rp *p2p.RemotePeer) error {
 				if err != nil {
 					return err
 				}
+				cfilterSizes[i] = cfilterSize(filter.Bytes(), proof)
 
 				err = validate.CFilterV2HeaderCommitment(cnet, header,
 					filter, proofIndex, proof)
@@ -1345,6 +1488,18 @@ func (s *Syncer) getHeaders(ctx context.Context, rp *p2p.RemotePeer) error {
 			})
 		}
 		err = g.Wait()
+
+		// The cfilters are counted once per batch of headers, including
+		// those received before an error.
+		var cfiltersReceived, cfiltersRequested int
+		for _, size := range cfilterSizes {
+			if size > 0 {
+				cfiltersReceived += size
+				cfiltersRequested++
+			}
+		}
+		s.addCFilterDataUsage(cfiltersReceived, cfiltersRequested)
+
 		if err != nil {
 			return err
 		}
@@ -1486,13 +1641,16 @@ func (s *Syncer) startupSync(ctx context.Context, rp *p2p.RemotePeer) error {
 				// check to see if it was previously synced
 				s.unsynced(walletID)
 
-				s.discoverAddressesStart(walletID)
-				err = w.DiscoverActiveAddresses(ctx, rp, rescanPoint, !w.Locked(), w.GapLimit())
-				if err != nil {
-					return err
-				}
+				fastSyncHeightFn, fastSync := s.fastSyncHeights[walletID]
+				if !fastSync {
+					s.discoverAddressesStart(walletID)
+					err = w.DiscoverActiveAddresses(ctx, rp, rescanPoint, !w.Locked(), w.GapLimit())
+					if err != nil {
+						return err
+					}
 
-				s.discoverAddressesFinished(walletID)
+					s.discoverAddressesFinished(walletID)
+				}
 
 				err = w.LoadActiveDataFilters(ctx, walletBackend, true)
 				if err != nil {
@@ -1506,8 +1664,24 @@ func (s *Syncer) startupSync(ctx context.Context, rp *p2p.RemotePeer) error {
 				if err != nil {
 					return err
 				}
+				rescanHeight := int32(rescanBlock.Height)
+				if fastSync {
+					fastSyncHeight, err := fastSyncHeightFn()
+					if err != nil {
+						return err
+					}
+					// Blocks before fastSyncHeight cannot have transactions
+					// of the wallet, only rescan the blocks after it.
+					_, tipHeight := w.MainChainTip(ctx)
+					if fastSyncHeight > tipHeight {
+						fastSyncHeight = tipHeight
+					}
+					if fastSyncHeight > rescanHeight {
+						rescanHeight = fastSyncHeight
+					}
+				}
 				progress := make(chan wallet.RescanProgress, 1)
-				go w.RescanProgressFromHeight(ctx, walletBackend, int32(rescanBlock.Height), progress)
+				go w.RescanProgressFromHeight(ctx, walletBackend, rescanHeight, progress)
 
 				for p := range progress {
 					if p.Err != nil {
@@ -1516,6 +1690,7 @@ func (s *Syncer) startupSync(ctx context.Context, rp *p2p.RemotePeer) error {
 					s.rescanProgress(walletID, p.ScannedThrough)
 				}
 				s.rescanFinished(walletID)
+				delete(s.fastSyncHeights, walletID)
 
 				s.synced(walletID)
 
@@ -1539,6 +1714,7 @@ func (s *Syncer) startupSync(ctx context.Context, rp *p2p.RemotePeer) error {
 			continue
 		}
 		err = rp.PublishTransactions(ctx, unminedTxs...)
+		s.addPublishedDataUsage(unminedTxs)
 		if err != nil {
 			// TODO: Transactions should be removed if this is a double spend.
 			log.Errorf("Failed to resent one or more unmined transactions: %v", err)
@@ -0,0 +1,514 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"decred.org/dcrwallet/v2/errors"
+	"decred.org/dcrwallet/v2/wallet/txrules"
+	"decred.org/dcrwallet/v2/wallet/txsizes"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/txscript/v4"
+	"github.com/decred/dcrd/txscript/v4/sign"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	"github.com/decred/dcrd/wire"
+)
+
+const (
+	// swapSecretSize is the size of the secret, and of its SHA256 hash, used
+	// by swap contracts.
+	swapSecretSize = 32
+
+	// redeemSwapSigScriptSize and refundSwapSigScriptSize are the worst case
+	// sizes of the signature scripts redeeming and refunding a swap contract,
+	// excluding the push of the contract.
+	redeemSwapSigScriptSize = 1 + 73 + 1 + 33 + 1 + 32 + 1
+	refundSwapSigScriptSize = 1 + 73 + 1 + 33 + 1
+)
+
+// swapContractPushes holds the data pushed by a swap contract.
+type swapContractPushes struct {
+	recipientHash160 []byte
+	refundHash160    []byte
+	secretHash       []byte
+	lockTime         int64
+}
+
+// InitiateSwap publishes a transaction that pays amount from account to a
+// swap contract. The contract can be redeemed by counterpartyAddress with the
+// secret whose SHA256 hash is secretHash, or refunded to the wallet after
+// lockTime, a block height or a unix timestamp. A JSON encoded SwapTransaction
+// describing the contract and the published transaction is returned.
+func (mw *MultiWallet) InitiateSwap(walletID int, privPass []byte, account int32, counterpartyAddress string,
+	amount int64, secretHash []byte, lockTime int64) (string, error) {
+
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return "", errors.New(ErrNotExist)
+	}
+
+	if len(secretHash) != swapSecretSize {
+		return "", fmt.Errorf("%s: secret hash must be %d bytes", ErrInvalid, swapSecretSize)
+	}
+	if lockTime <= 0 || lockTime > math.MaxUint32 {
+		return "", fmt.Errorf("%s: invalid lock time %d", ErrInvalid, lockTime)
+	}
+
+	recipientHash160, err := addressHash160(counterpartyAddress, wallet.chainParams)
+	if err != nil {
+		return "", err
+	}
+
+	refundAddr, err := wallet.Internal().NewChangeAddress(wallet.shutdownContext(), uint32(account))
+	if err != nil {
+		return "", translateError(err)
+	}
+	refundHash160, err := addressHash160(refundAddr.String(), wallet.chainParams)
+	if err != nil {
+		return "", err
+	}
+
+	contract, err := swapContract(recipientHash160, refundHash160, secretHash, lockTime)
+	if err != nil {
+		return "", err
+	}
+	contractAddr, err := stdaddr.NewAddressScriptHashV0(contract, wallet.chainParams)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := mw.NewUnsignedTx(walletID, account)
+	if err != nil {
+		return "", err
+	}
+	err = tx.AddSendDestination(contractAddr.String(), amount, false)
+	if err != nil {
+		return "", err
+	}
+
+	msgTx, err := mw.broadcastAndIndexTx(tx, privPass)
+	if err != nil {
+		return "", err
+	}
+
+	details, err := mw.auditSwapContract(contract, msgTx)
+	if err != nil {
+		return "", err
+	}
+
+	fee := int64(tx.unsignedTx.TotalInput)
+	for _, txOut := range msgTx.TxOut {
+		fee -= txOut.Value
+	}
+
+	return swapTransactionJSON(msgTx, fee, details)
+}
+
+// AuditSwapContract checks that contractHex is a swap contract and that
+// contractTxHex pays to it. A JSON encoded SwapContract describing the
+// contract and the output paying to it is returned.
+func (mw *MultiWallet) AuditSwapContract(contractHex, contractTxHex string) (string, error) {
+	contract, err := hex.DecodeString(contractHex)
+	if err != nil {
+		return "", fmt.Errorf("%s: invalid contract: %v", ErrInvalid, err)
+	}
+
+	contractTxBytes, err := hex.DecodeString(contractTxHex)
+	if err != nil {
+		return "", fmt.Errorf("%s: invalid transaction: %v", ErrInvalid, err)
+	}
+
+	contractTx, err := deserializeSwapTx(contractTxBytes)
+	if err != nil {
+		return "", err
+	}
+
+	details, err := mw.auditSwapContract(contract, contractTx)
+	if err != nil {
+		return "", err
+	}
+
+	result, _ := json.Marshal(details)
+	return string(result), nil
+}
+
+// RedeemSwap publishes a transaction that spends the output of contractTx
+// paying to contract, using secret, to an address of the wallet's account that
+// owns the contract's recipient address. A JSON encoded SwapTransaction is
+// returned.
+func (mw *MultiWallet) RedeemSwap(walletID int, privPass []byte, contract, contractTx, secret []byte) (string, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return "", errors.New(ErrNotExist)
+	}
+
+	tx, err := deserializeSwapTx(contractTx)
+	if err != nil {
+		return "", err
+	}
+
+	details, err := mw.auditSwapContract(contract, tx)
+	if err != nil {
+		return "", err
+	}
+
+	secretHash := sha256.Sum256(secret)
+	if len(secret) != swapSecretSize || hex.EncodeToString(secretHash[:]) != details.SecretHash {
+		return "", fmt.Errorf("%s: secret does not match the contract's secret hash", ErrInvalid)
+	}
+
+	return mw.spendSwapContract(wallet, privPass, contract, details, details.RecipientAddress, secret)
+}
+
+// RefundSwap publishes a transaction that spends the output of contractTx
+// paying to contract back to an address of the wallet's account that owns the
+// contract's refund address. The transaction is only accepted by the network
+// after the contract's lock time. A JSON encoded SwapTransaction is returned.
+func (mw *MultiWallet) RefundSwap(walletID int, privPass []byte, contract, contractTx []byte) (string, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return "", errors.New(ErrNotExist)
+	}
+
+	tx, err := deserializeSwapTx(contractTx)
+	if err != nil {
+		return "", err
+	}
+
+	details, err := mw.auditSwapContract(contract, tx)
+	if err != nil {
+		return "", err
+	}
+
+	return mw.spendSwapContract(wallet, privPass, contract, details, details.RefundAddress, nil)
+}
+
+// auditSwapContract parses contract and finds the output of contractTx that
+// pays to it.
+func (mw *MultiWallet) auditSwapContract(contract []byte, contractTx *wire.MsgTx) (*SwapContract, error) {
+	pushes, err := extractSwapContract(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	contractAddr, err := stdaddr.NewAddressScriptHashV0(contract, mw.chainParams)
+	if err != nil {
+		return nil, err
+	}
+	_, contractPkScript := contractAddr.PaymentScript()
+
+	outputIndex := -1
+	for i, txOut := range contractTx.TxOut {
+		if bytes.Equal(txOut.PkScript, contractPkScript) {
+			outputIndex = i
+			break
+		}
+	}
+	if outputIndex == -1 {
+		return nil, fmt.Errorf("%s: transaction does not pay to the contract", ErrInvalid)
+	}
+
+	recipientAddr, err := stdaddr.NewAddressPubKeyHashEcdsaSecp256k1V0(pushes.recipientHash160, mw.chainParams)
+	if err != nil {
+		return nil, err
+	}
+	refundAddr, err := stdaddr.NewAddressPubKeyHashEcdsaSecp256k1V0(pushes.refundHash160, mw.chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SwapContract{
+		Contract:         hex.EncodeToString(contract),
+		ContractAddress:  contractAddr.String(),
+		RecipientAddress: recipientAddr.String(),
+		RefundAddress:    refundAddr.String(),
+		SecretHash:       hex.EncodeToString(pushes.secretHash),
+		LockTime:         pushes.lockTime,
+		TxHash:           contractTx.TxHash().String(),
+		OutputIndex:      uint32(outputIndex),
+		Amount:           contractTx.TxOut[outputIndex].Value,
+	}, nil
+}
+
+// spendSwapContract publishes a transaction that spends the contract output
+// described by details, signed with the key of ownerAddress. The contract is
+// redeemed if secret is set and refunded otherwise.
+func (mw *MultiWallet) spendSwapContract(wallet *Wallet, privPass []byte, contract []byte, details *SwapContract,
+	ownerAddress string, secret []byte) (string, error) {
+
+	netBackend, err := wallet.Internal().NetworkBackend()
+	if err != nil {
+		return "", errors.New(ErrNotConnected)
+	}
+
+	addressInfo, err := wallet.AddressInfo(ownerAddress)
+	if err != nil {
+		return "", err
+	}
+	if !addressInfo.IsMine {
+		return "", errors.New(ErrAddressNotOwned)
+	}
+
+	// Addresses cannot be derived for the imported account.
+	account := addressInfo.AccountNumber
+	if account == ImportedAccountNumber {
+		account = DefaultAccountNum
+	}
+
+	ctx := wallet.shutdownContext()
+	destAddr, err := wallet.Internal().NewChangeAddress(ctx, account)
+	if err != nil {
+		return "", translateError(err)
+	}
+
+	txHash, err := chainhash.NewHashFromStr(details.TxHash)
+	if err != nil {
+		return "", err
+	}
+
+	spendTx := wire.NewMsgTx()
+	outPoint := wire.NewOutPoint(txHash, details.OutputIndex, wire.TxTreeRegular)
+	spendTx.AddTxIn(wire.NewTxIn(outPoint, details.Amount, nil))
+
+	sigScriptSize := redeemSwapSigScriptSize
+	if secret == nil {
+		// The lock time is only enforced if the input is not final.
+		spendTx.LockTime = uint32(details.LockTime)
+		spendTx.TxIn[0].Sequence = 0
+		sigScriptSize = refundSwapSigScriptSize
+	}
+	sigScriptSize += len(contract) + 2
+
+	scriptVersion, pkScript := destAddr.PaymentScript()
+	txOut := &wire.TxOut{Version: scriptVersion, PkScript: pkScript}
+	spendTx.AddTxOut(txOut)
+
+	feeRate := dcrutil.Amount(mw.TransactionFeeRate())
+	size := txsizes.EstimateSerializeSize([]int{sigScriptSize}, spendTx.TxOut, 0)
+	fee := txrules.FeeForSerializeSize(feeRate, size)
+	txOut.Value = details.Amount - int64(fee)
+	if txrules.IsDustOutput(txOut, feeRate) {
+		return "", errors.New(ErrOutputIsDust)
+	}
+
+	relock, err := wallet.unlockTemporarily(privPass)
+	if err != nil {
+		return "", err
+	}
+	defer relock()
+
+	owner, err := stdaddr.DecodeAddress(ownerAddress, wallet.chainParams)
+	if err != nil {
+		return "", err
+	}
+	wif, err := wallet.Internal().DumpWIFPrivateKey(ctx, owner)
+	if err != nil {
+		return "", translateError(err)
+	}
+	key, err := dcrutil.DecodeWIF(wif, wallet.chainParams.PrivateKeyID)
+	if err != nil {
+		return "", err
+	}
+
+	sig, err := sign.RawTxInSignature(spendTx, 0, contract, txscript.SigHashAll, key.PrivKey(), dcrec.STEcdsaSecp256k1)
+	if err != nil {
+		return "", err
+	}
+
+	builder := txscript.NewScriptBuilder().AddData(sig).AddData(key.PubKey())
+	if secret != nil {
+		builder.AddData(secret).AddInt64(1)
+	} else {
+		builder.AddInt64(0)
+	}
+	sigScript, err := builder.AddData(contract).Script()
+	if err != nil {
+		return "", err
+	}
+	spendTx.TxIn[0].SignatureScript = sigScript
+
+	hash, err := wallet.Internal().PublishTransaction(ctx, spendTx, netBackend)
+	if err != nil {
+		return "", translatePublishError(err)
+	}
+	mw.indexSentTransaction(wallet, hash)
+
+	return swapTransactionJSON(spendTx, int64(fee), nil)
+}
+
+// swapContract returns the standard atomic swap contract script. The output
+// paying to it can be spent with the secret whose hash is secretHash and the
+// recipient's signature, or with the refund signature after lockTime.
+func swapContract(recipientHash160, refundHash160, secretHash []byte, lockTime int64) ([]byte, error) {
+	return txscript.NewScriptBuilder().
+		AddOp(txscript.OP_IF). // Redeem path.
+		AddOp(txscript.OP_SIZE).
+		AddInt64(swapSecretSize).
+		AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_SHA256).
+		AddData(secretHash).
+		AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_DUP).
+		AddOp(txscript.OP_HASH160).
+		AddData(recipientHash160).
+		AddOp(txscript.OP_ELSE). // Refund path.
+		AddInt64(lockTime).
+		AddOp(txscript.OP_CHECKLOCKTIMEVERIFY).
+		AddOp(txscript.OP_DROP).
+		AddOp(txscript.OP_DUP).
+		AddOp(txscript.OP_HASH160).
+		AddData(refundHash160).
+		AddOp(txscript.OP_ENDIF).
+		AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_CHECKSIG).
+		Script()
+}
+
+// extractSwapContract returns the data pushed by contract or an error if
+// contract is not a standard atomic swap contract.
+func extractSwapContract(contract []byte) (*swapContractPushes, error) {
+	type token struct {
+		op   byte
+		data []byte
+	}
+
+	var tokens []token
+	tokenizer := txscript.MakeScriptTokenizer(0, contract)
+	for tokenizer.Next() {
+		tokens = append(tokens, token{tokenizer.Opcode(), tokenizer.Data()})
+	}
+	if tokenizer.Err() != nil || len(tokens) != 20 {
+		return nil, fmt.Errorf("%s: not a swap contract", ErrInvalid)
+	}
+
+	expectedOps := map[int]byte{
+		0:  txscript.OP_IF,
+		1:  txscript.OP_SIZE,
+		2:  txscript.OP_DATA_1,
+		3:  txscript.OP_EQUALVERIFY,
+		4:  txscript.OP_SHA256,
+		5:  txscript.OP_DATA_32,
+		6:  txscript.OP_EQUALVERIFY,
+		7:  txscript.OP_DUP,
+		8:  txscript.OP_HASH160,
+		9:  txscript.OP_DATA_20,
+		10: txscript.OP_ELSE,
+		12: txscript.OP_CHECKLOCKTIMEVERIFY,
+		13: txscript.OP_DROP,
+		14: txscript.OP_DUP,
+		15: txscript.OP_HASH160,
+		16: txscript.OP_DATA_20,
+		17: txscript.OP_ENDIF,
+		18: txscript.OP_EQUALVERIFY,
+		19: txscript.OP_CHECKSIG,
+	}
+	for i, op := range expectedOps {
+		if tokens[i].op != op {
+			return nil, fmt.Errorf("%s: not a swap contract", ErrInvalid)
+		}
+	}
+	if tokens[2].data[0] != swapSecretSize {
+		return nil, fmt.Errorf("%s: unexpected secret size %d", ErrInvalid, tokens[2].data[0])
+	}
+
+	lockTime, ok := scriptNumInt64(tokens[11].op, tokens[11].data)
+	if !ok || lockTime <= 0 {
+		return nil, fmt.Errorf("%s: invalid contract lock time", ErrInvalid)
+	}
+
+	return &swapContractPushes{
+		recipientHash160: tokens[9].data,
+		refundHash160:    tokens[16].data,
+		secretHash:       tokens[5].data,
+		lockTime:         lockTime,
+	}, nil
+}
+
+// scriptNumInt64 decodes a number pushed to a script by the opcode op with
+// data, as encoded by txscript.ScriptBuilder.AddInt64. Only numbers of up to
+// 5 bytes, which covers all lock times, are decoded.
+func scriptNumInt64(op byte, data []byte) (int64, bool) {
+	switch {
+	case op == txscript.OP_0:
+		return 0, true
+	case op >= txscript.OP_1 && op <= txscript.OP_16:
+		return int64(op-txscript.OP_1) + 1, true
+	case len(data) == 0 || len(data) > 5:
+		return 0, false
+	}
+
+	var n int64
+	for i, b := range data {
+		n |= int64(b) << uint(8*i)
+	}
+
+	// The most significant bit of the last byte is the sign bit.
+	signBit := int64(0x80) << uint(8*(len(data)-1))
+	if n&signBit != 0 {
+		n = -(n &^ signBit)
+	}
+	return n, true
+}
+
+// addressHash160 returns the public key hash of a P2PKH address.
+func addressHash160(address string, chainParams *chaincfg.Params) ([]byte, error) {
+	addr, err := stdaddr.DecodeAddress(address, chainParams)
+	if err != nil {
+		return nil, errors.New(ErrInvalidAddress)
+	}
+
+	pkhAddr, ok := addr.(*stdaddr.AddressPubKeyHashEcdsaSecp256k1V0)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s is not a P2PKH address", ErrInvalidAddress, address)
+	}
+	return pkhAddr.Hash160()[:], nil
+}
+
+func deserializeSwapTx(txBytes []byte) (*wire.MsgTx, error) {
+	tx := new(wire.MsgTx)
+	err := tx.Deserialize(bytes.NewReader(txBytes))
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid transaction: %v", ErrInvalid, err)
+	}
+	return tx, nil
+}
+
+func swapTransactionJSON(tx *wire.MsgTx, fee int64, contract *SwapContract) (string, error) {
+	txBytes, err := tx.Bytes()
+	if err != nil {
+		return "", err
+	}
+
+	result, _ := json.Marshal(&SwapTransaction{
+		TxHash:   tx.TxHash().String(),
+		Tx:       hex.EncodeToString(txBytes),
+		Fee:      fee,
+		Contract: contract,
+	})
+	return string(result), nil
+}
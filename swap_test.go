@@ -0,0 +1,95 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+
+	"github.com/decred/dcrd/txscript/v4"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Swap", func() {
+	recipientHash160 := bytes.Repeat([]byte{1}, 20)
+	refundHash160 := bytes.Repeat([]byte{2}, 20)
+	secretHash := bytes.Repeat([]byte{3}, swapSecretSize)
+
+	Context("swapContract and extractSwapContract", func() {
+		It("extracts the pushes of a contract", func() {
+			for _, lockTime := range []int64{1, 16, 17, 127, 128, 255, 256, 500000, 1600000000, 1 << 32} {
+				contract, err := swapContract(recipientHash160, refundHash160, secretHash, lockTime)
+				Expect(err).To(BeNil())
+
+				pushes, err := extractSwapContract(contract)
+				Expect(err).To(BeNil())
+				Expect(pushes).To(Equal(&swapContractPushes{
+					recipientHash160: recipientHash160,
+					refundHash160:    refundHash160,
+					secretHash:       secretHash,
+					lockTime:         lockTime,
+				}))
+			}
+		})
+
+		It("rejects malformed contracts", func() {
+			contract, err := swapContract(recipientHash160, refundHash160, secretHash, 1600000000)
+			Expect(err).To(BeNil())
+
+			_, err = extractSwapContract(contract[:len(contract)-1])
+			Expect(err).ToNot(BeNil())
+
+			_, err = extractSwapContract(append(contract, txscript.OP_NOP))
+			Expect(err).ToNot(BeNil())
+
+			By("Rejecting a contract with another opcode")
+			modified := append([]byte(nil), contract...)
+			modified[len(modified)-1] = txscript.OP_CHECKSIGVERIFY
+			_, err = extractSwapContract(modified)
+			Expect(err).ToNot(BeNil())
+
+			By("Rejecting a contract with another secret size")
+			modified = append([]byte(nil), contract...)
+			Expect(modified[2]).To(Equal(byte(txscript.OP_DATA_1)))
+			modified[3] = swapSecretSize - 1
+			_, err = extractSwapContract(modified)
+			Expect(err).ToNot(BeNil())
+
+			By("Rejecting contracts without a positive lock time")
+			for _, lockTime := range []int64{0, -1, -1600000000} {
+				contract, err := swapContract(recipientHash160, refundHash160, secretHash, lockTime)
+				Expect(err).To(BeNil())
+				_, err = extractSwapContract(contract)
+				Expect(err).ToNot(BeNil())
+			}
+
+			_, err = extractSwapContract(nil)
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Context("scriptNumInt64", func() {
+		It("decodes script numbers of up to 5 bytes", func() {
+			for _, test := range []struct {
+				op   byte
+				data []byte
+				n    int64
+			}{
+				{txscript.OP_0, nil, 0},
+				{txscript.OP_1, nil, 1},
+				{txscript.OP_16, nil, 16},
+				{txscript.OP_DATA_1, []byte{0x81}, -1},
+				{txscript.OP_DATA_2, []byte{0xff, 0x00}, 255},
+				{txscript.OP_DATA_2, []byte{0xff, 0x80}, -255},
+				{txscript.OP_DATA_5, []byte{0x00, 0x00, 0x00, 0x00, 0x01}, 1 << 32},
+			} {
+				n, ok := scriptNumInt64(test.op, test.data)
+				Expect(ok).To(BeTrue())
+				Expect(n).To(Equal(test.n))
+			}
+
+			_, ok := scriptNumInt64(txscript.OP_DATA_6, make([]byte, 6))
+			Expect(ok).To(BeFalse())
+			_, ok = scriptNumInt64(txscript.OP_1NEGATE, nil)
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
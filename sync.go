@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"decred.org/dcrwallet/v2/errors"
 	"decred.org/dcrwallet/v2/p2p"
@@ -29,12 +30,36 @@ type syncData struct {
 	cancelRescan context.CancelFunc
 	syncCanceled chan struct{}
 
+	// rescanDone is closed when the rescan in progress, if any, returns.
+	rescanDone chan struct{}
+
 	// Flag to notify syncCanceled callback if the sync was canceled so as to be restarted.
 	restartSyncRequested bool
 
 	rescanning     bool
 	connectedPeers int32
 
+	// rescanWalletID and rescanStartHeight identify the rescan in
+	// progress, if rescanning is true, and rescannedThrough is the height
+	// it has scanned through. rescanResumable is false for the scan of
+	// SweepPrivateKey, which is not started again after a pause.
+	rescanWalletID    int
+	rescanStartHeight int32
+	rescannedThrough  int32
+	rescanResumable   bool
+
+	// bestBlockOnNetwork is the highest block height reported by connected
+	// peers during the current or most recent sync, -1 if no peer has
+	// reported a height yet.
+	bestBlockOnNetwork int32
+
+	// dataUsage is the data exchanged with peers during the current or
+	// most recent sync. persistedDataUsage is the part of it that was added
+	// to the daily totals saved to the config db.
+	dataUsage            spv.DataUsage
+	persistedDataUsage   int64
+	dataUsagePersistedAt time.Time
+
 	*activeSyncData
 }
 
@@ -54,6 +79,15 @@ type activeSyncData struct {
 	rescanStartTime int64
 
 	totalInactiveSeconds int64
+
+	// Statistics used to build the SyncSummary reported when sync completes.
+	syncStartTime                 int64
+	headersFetched                int32
+	rescanStartHeights            map[int]int32
+	rescanEndHeights              map[int]int32
+	peersUsed                     map[string]struct{}
+	addressUsageBeforeDiscovery   map[int]uint32
+	addressDiscoveryFoundActivity bool
 }
 
 const (
@@ -91,6 +125,7 @@ func (mw *MultiWallet) initActiveSyncData() {
 	headersRescanProgress.GeneralSyncProgress = &GeneralSyncProgress{}
 
 	mw.syncData.mu.Lock()
+	mw.syncData.bestBlockOnNetwork = -1
 	mw.syncData.activeSyncData = &activeSyncData{
 		syncStage: InvalidSyncStage,
 
@@ -98,6 +133,12 @@ func (mw *MultiWallet) initActiveSyncData() {
 		headersFetchProgress:     headersFetchProgress,
 		addressDiscoveryProgress: addressDiscoveryProgress,
 		headersRescanProgress:    headersRescanProgress,
+
+		syncStartTime:               time.Now().Unix(),
+		rescanStartHeights:          make(map[int]int32),
+		rescanEndHeights:            make(map[int]int32),
+		peersUsed:                   make(map[string]struct{}),
+		addressUsageBeforeDiscovery: make(map[int]uint32),
 	}
 	mw.syncData.mu.Unlock()
 }
@@ -191,6 +232,11 @@ func (mw *MultiWallet) SpvSync() error {
 		return errors.New(ErrSyncAlreadyInProgress)
 	}
 
+	if mw.pauseSyncIfNetworkUnusable() {
+		log.Info("Network is unavailable or metered, sync will start when a usable network is available.")
+		return nil
+	}
+
 	addr := &net.TCPAddr{IP: net.ParseIP("::1"), Port: 0}
 	addrManager := addrmgr.New(mw.rootDir, net.LookupIP) // TODO: be mindful of tor
 	lp := p2p.NewLocalPeer(mw.chainParams, addr, addrManager)
@@ -202,7 +248,7 @@ func (mw *MultiWallet) SpvSync() error {
 		for _, address := range addresses {
 			peerAddress, err := NormalizeAddress(address, mw.chainParams.DefaultPort)
 			if err != nil {
-				log.Errorf("SPV peer address(%s) is invalid: %v", peerAddress, err)
+				log.Errorf("SPV peer address(%s) is invalid: %v", address, err)
 			} else {
 				validPeerAddresses = append(validPeerAddresses, peerAddress)
 			}
@@ -216,9 +262,14 @@ func (mw *MultiWallet) SpvSync() error {
 	// init activeSyncData to be used to hold data used
 	// to calculate sync estimates only during sync
 	mw.initActiveSyncData()
+	mw.resetSyncDataUsage()
 
 	wallets := make(map[int]*w.Wallet)
 	for id, wallet := range mw.wallets {
+		if !wallet.WalletOpened() {
+			continue
+		}
+
 		wallets[id] = wallet.Internal()
 		wallet.waitingForHeaders = true
 		wallet.syncing = true
@@ -226,6 +277,12 @@ func (mw *MultiWallet) SpvSync() error {
 
 	syncer := spv.NewSyncer(wallets, lp)
 	syncer.SetNotifications(mw.spvSyncNotificationCallbacks())
+	for id := range wallets {
+		if wallet := mw.wallets[id]; wallet.canFastSync() {
+			log.Infof("[%d] New wallet, skipping address discovery and rescan of blocks before its birthday", id)
+			syncer.SetFastInitialSync(id, wallet.birthdayBlockHeight)
+		}
+	}
 	if len(validPeerAddresses) > 0 {
 		syncer.SetPersistentPeers(validPeerAddresses)
 	}
@@ -254,9 +311,8 @@ func (mw *MultiWallet) SpvSync() error {
 		syncError := syncer.Run(ctx)
 		//sync has ended or errored
 		if syncError != nil {
-			if syncError == context.DeadlineExceeded {
-				mw.notifySyncError(errors.Errorf("SPV synchronization deadline exceeded: %v", syncError))
-			} else if syncError == context.Canceled {
+			// The syncer may wrap the context error.
+			if errors.Is(syncError, context.Canceled) {
 				close(mw.syncData.syncCanceled)
 				mw.notifySyncCanceled()
 			} else {
@@ -264,25 +320,79 @@ func (mw *MultiWallet) SpvSync() error {
 			}
 		}
 
+		mw.persistSyncDataUsage()
+
 		//reset sync variables
 		mw.resetSyncData()
 	}()
 	return nil
 }
 
-func (mw *MultiWallet) RestartSpvSync() error {
-	mw.syncData.mu.Lock()
-	mw.syncData.restartSyncRequested = true
-	mw.syncData.mu.Unlock()
+// ForceFullSync disables the fast initial sync of a new wallet, address
+// discovery and a rescan of all blocks are done the next time the wallet
+// is synced. If the wallet is already synced, the blocks are rescanned
+// immediately.
+func (mw *MultiWallet) ForceFullSync(walletID int) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	wallet.SetBoolConfigValueForKey(ForceFullSyncConfigKey, true)
 
-	mw.CancelSync() // necessary to unset the network backend.
+	if mw.IsSynced() {
+		return mw.RescanBlocks(walletID)
+	}
+	if mw.IsSyncing() {
+		return mw.RestartSpvSync()
+	}
+	return nil
+}
+
+// canFastSync returns true if the wallet was created by this library rather
+// than restored and has no transactions, so no addresses of the wallet can
+// have been used before its birthday.
+func (wallet *Wallet) canFastSync() bool {
+	if wallet.IsRestored || wallet.CreatedAt.IsZero() || wallet.IsWatchingOnlyWallet() {
+		return false
+	}
+	if wallet.ReadBoolConfigValueForKey(ForceFullSyncConfigKey, false) {
+		return false
+	}
+
+	count, err := wallet.CountTransactions(TxFilterAll)
+	return err == nil && count == 0
+}
+
+func (mw *MultiWallet) RestartSpvSync() error {
+	mw.cancelSync(true) // necessary to unset the network backend.
 	return mw.SpvSync()
 }
 
+// CancelSync stops the sync, the sync progress listeners are notified with
+// OnSyncCanceled once the sync has stopped. A sync paused because the network
+// is unusable, and the rescan paused with it, are not started when a usable
+// network is available.
 func (mw *MultiWallet) CancelSync() {
-	mw.syncData.mu.RLock()
+	mw.networkMu.Lock()
+	mw.syncPaused = false
+	mw.pausedRescan = nil
+	mw.networkMu.Unlock()
+
+	mw.cancelSync(false)
+}
+
+// cancelSync stops the sync. willRestart is passed to the OnSyncCanceled
+// callback of the sync progress listeners, it must only be true if a new sync
+// is started after this returns, either by the caller or, for a sync paused
+// because the network is unusable, when a usable network is available.
+func (mw *MultiWallet) cancelSync(willRestart bool) {
+	mw.syncData.mu.Lock()
 	cancelSync := mw.syncData.cancelSync
-	mw.syncData.mu.RUnlock()
+	if cancelSync != nil || willRestart {
+		mw.syncData.restartSyncRequested = willRestart
+	}
+	mw.syncData.mu.Unlock()
 
 	if cancelSync != nil {
 		log.Info("Canceling sync. May take a while for sync to fully cancel.")
@@ -394,6 +504,7 @@ func (mw *MultiWallet) PeerInfoRaw() ([]PeerInfo, error) {
 			Version:        rp.Pver(),
 			SubVer:         rp.UA(),
 			StartingHeight: int64(rp.InitialHeight()),
+			LastBlock:      int64(syncer.LastAnnouncedHeight(rp)),
 			BanScore:       int32(rp.BanScore()),
 		}
 
@@ -417,6 +528,93 @@ func (mw *MultiWallet) PeerInfo() (string, error) {
 	return string(result), nil
 }
 
+// AddPeer connects the running SPV syncer to the peer at the provided address
+// and keeps it connected as a persistent peer until sync is canceled or the
+// peer is removed with RemovePeer.
+func (mw *MultiWallet) AddPeer(address string) error {
+	syncer, err := mw.activeSyncer()
+	if err != nil {
+		return err
+	}
+
+	peerAddress, err := NormalizeAddress(address, mw.chainParams.DefaultPort)
+	if err != nil {
+		log.Errorf("Invalid peer address (%s): %v", address, err)
+		return err
+	}
+
+	return translateError(syncer.AddPersistentPeer(peerAddress))
+}
+
+// RemovePeer disconnects the running SPV syncer from the peer at the provided
+// address. If the peer was added as a persistent peer, no further attempts
+// are made to reconnect to it.
+func (mw *MultiWallet) RemovePeer(address string) error {
+	syncer, err := mw.activeSyncer()
+	if err != nil {
+		return err
+	}
+
+	peerAddress, err := NormalizeAddress(address, mw.chainParams.DefaultPort)
+	if err != nil {
+		log.Errorf("Invalid peer address (%s): %v", address, err)
+		return err
+	}
+
+	return translateError(syncer.RemovePeer(peerAddress))
+}
+
+func (mw *MultiWallet) activeSyncer() (*spv.Syncer, error) {
+	if !mw.IsConnectedToDecredNetwork() {
+		return nil, errors.New(ErrNotConnected)
+	}
+
+	mw.syncData.mu.RLock()
+	defer mw.syncData.mu.RUnlock()
+
+	if mw.syncData.activeSyncData == nil || mw.syncData.syncer == nil {
+		return nil, errors.New(ErrNotConnected)
+	}
+	return mw.syncData.syncer, nil
+}
+
+// GetBestBlockOnNetwork returns the highest block height reported by the
+// peers connected during the current or most recent sync, or -1 if no peer
+// has reported a height yet.
+func (mw *MultiWallet) GetBestBlockOnNetwork() int32 {
+	mw.syncData.mu.RLock()
+	defer mw.syncData.mu.RUnlock()
+	return mw.syncData.bestBlockOnNetwork
+}
+
+// syncSummary builds a summary of the statistics accumulated during the
+// current sync. mw.syncData.mu must be held for reads.
+func (mw *MultiWallet) syncSummary() *SyncSummary {
+	summary := &SyncSummary{}
+	if mw.syncData.activeSyncData == nil {
+		return summary
+	}
+
+	summary.DurationSeconds = time.Now().Unix() - mw.syncData.syncStartTime
+	summary.HeadersFetched = mw.syncData.headersFetched
+	summary.PeersUsed = int32(len(mw.syncData.peersUsed))
+	summary.AddressDiscoveryFoundActivity = mw.syncData.addressDiscoveryFoundActivity
+	for walletID, endHeight := range mw.syncData.rescanEndHeights {
+		summary.BlocksRescanned += endHeight - mw.syncData.rescanStartHeights[walletID]
+	}
+
+	return summary
+}
+
+// updateBestBlockOnNetwork records height as the network's best block height
+// if it is higher than the previously recorded best block height.
+// mw.syncData.mu must be held for writes.
+func (mw *MultiWallet) updateBestBlockOnNetwork(height int32) {
+	if height > mw.syncData.bestBlockOnNetwork {
+		mw.syncData.bestBlockOnNetwork = height
+	}
+}
+
 func (mw *MultiWallet) GetBestBlock() *BlockInfo {
 	var bestBlock int32 = -1
 	var blockInfo *BlockInfo
@@ -425,10 +623,14 @@ func (mw *MultiWallet) GetBestBlock() *BlockInfo {
 			continue
 		}
 
-		walletBestBLock := wallet.GetBestBlock()
-		if walletBestBLock > bestBlock || bestBlock == -1 {
-			bestBlock = walletBestBLock
-			blockInfo = &BlockInfo{Height: bestBlock, Timestamp: wallet.GetBestBlockTimeStamp()}
+		walletBestBlock, err := wallet.bestBlockInfo()
+		if err != nil {
+			log.Errorf("[%d] Error reading best block: %v", wallet.ID, err)
+			continue
+		}
+		if walletBestBlock.Height > bestBlock || bestBlock == -1 {
+			bestBlock = walletBestBlock.Height
+			blockInfo = walletBestBlock
 		}
 	}
 
@@ -442,10 +644,14 @@ func (mw *MultiWallet) GetLowestBlock() *BlockInfo {
 		if !wallet.WalletOpened() {
 			continue
 		}
-		walletBestBLock := wallet.GetBestBlock()
-		if walletBestBLock < lowestBlock || lowestBlock == -1 {
-			lowestBlock = walletBestBLock
-			blockInfo = &BlockInfo{Height: lowestBlock, Timestamp: wallet.GetBestBlockTimeStamp()}
+		walletBestBlock, err := wallet.bestBlockInfo()
+		if err != nil {
+			log.Errorf("[%d] Error reading best block: %v", wallet.ID, err)
+			continue
+		}
+		if walletBestBlock.Height < lowestBlock || lowestBlock == -1 {
+			lowestBlock = walletBestBlock.Height
+			blockInfo = walletBestBlock
 		}
 	}
 
@@ -470,10 +676,7 @@ func (wallet *Wallet) GetBestBlockTimeStamp() int64 {
 		return 0
 	}
 
-	ctx := wallet.shutdownContext()
-	_, height := wallet.Internal().MainChainTip(ctx)
-	identifier := w.NewBlockIdentifierFromHeight(height)
-	info, err := wallet.Internal().BlockInfo(ctx, identifier)
+	info, err := wallet.bestBlockInfo()
 	if err != nil {
 		log.Error(err)
 		return 0
@@ -0,0 +1,168 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"time"
+
+	"decred.org/dcrwallet/v2/errors"
+	"github.com/planetdecred/dcrlibwallet/spv"
+)
+
+const (
+	// syncDataUsagePersistInterval is the minimum time between updates of
+	// the daily data usage totals saved to the config db during a sync.
+	syncDataUsagePersistInterval = 30 * time.Second
+
+	// syncDataUsageDateFormat is the format of the dates of the daily data
+	// usage totals.
+	syncDataUsageDateFormat = "2006-01-02"
+)
+
+// SyncDataUsage is the number of bytes exchanged with peers during the
+// current or most recent sync, by sync stage. The numbers are estimated from
+// the size of the wire messages, they are not the bytes actually transferred.
+type SyncDataUsage struct {
+	HeadersReceived      int64 `json:"headers_received"`
+	CFiltersReceived     int64 `json:"cfilters_received"`
+	BlocksReceived       int64 `json:"blocks_received"`
+	TransactionsReceived int64 `json:"transactions_received"`
+	TotalReceived        int64 `json:"total_received"`
+	TotalSent            int64 `json:"total_sent"`
+}
+
+func newSyncDataUsage(usage spv.DataUsage) *SyncDataUsage {
+	return &SyncDataUsage{
+		HeadersReceived:      usage.Received[spv.DataStageHeaders],
+		CFiltersReceived:     usage.Received[spv.DataStageCFilters],
+		BlocksReceived:       usage.Received[spv.DataStageBlocks],
+		TransactionsReceived: usage.Received[spv.DataStageTransactions],
+		TotalReceived:        usage.TotalReceived(),
+		TotalSent:            usage.TotalSent(),
+	}
+}
+
+func (mw *MultiWallet) AddSyncDataListener(listener SyncDataListener, uniqueIdentifier string) error {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	if _, ok := mw.syncDataListeners[uniqueIdentifier]; ok {
+		return errors.New(ErrListenerAlreadyExist)
+	}
+
+	mw.syncDataListeners[uniqueIdentifier] = listener
+	return nil
+}
+
+func (mw *MultiWallet) RemoveSyncDataListener(uniqueIdentifier string) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	delete(mw.syncDataListeners, uniqueIdentifier)
+}
+
+// GetSyncData returns the json encoded SyncDataUsage of the current or most
+// recent sync. The counts are reset when a sync is started.
+func (mw *MultiWallet) GetSyncData() (string, error) {
+	result, err := json.Marshal(mw.GetSyncDataRaw())
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+func (mw *MultiWallet) GetSyncDataRaw() *SyncDataUsage {
+	mw.syncData.mu.RLock()
+	defer mw.syncData.mu.RUnlock()
+	return newSyncDataUsage(mw.syncData.dataUsage)
+}
+
+// SyncDataUsageHistory returns a json object mapping dates, formatted as
+// YYYY-MM-DD in local time, to the number of bytes sent and received while
+// syncing on each date. Totals are kept across syncs.
+func (mw *MultiWallet) SyncDataUsageHistory() (string, error) {
+	result, err := json.Marshal(mw.syncDataUsageHistory())
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// SyncDataUsageSince returns the number of bytes sent and received while
+// syncing from the day of the provided unix timestamp.
+func (mw *MultiWallet) SyncDataUsageSince(timestamp int64) int64 {
+	fromDate := time.Unix(timestamp, 0).Format(syncDataUsageDateFormat)
+
+	var total int64
+	for date, bytes := range mw.syncDataUsageHistory() {
+		// Dates in this format are ordered like strings.
+		if date >= fromDate {
+			total += bytes
+		}
+	}
+	return total
+}
+
+func (mw *MultiWallet) syncDataUsageHistory() map[string]int64 {
+	var history map[string]int64
+	_ = mw.ReadUserConfigValue(SyncDataUsageConfigKey, &history) // errors are logged
+	if history == nil {
+		history = make(map[string]int64)
+	}
+	return history
+}
+
+func (mw *MultiWallet) resetSyncDataUsage() {
+	mw.syncData.mu.Lock()
+	mw.syncData.dataUsage = spv.DataUsage{}
+	mw.syncData.persistedDataUsage = 0
+	mw.syncData.dataUsagePersistedAt = time.Now()
+	mw.syncData.mu.Unlock()
+}
+
+func (mw *MultiWallet) syncDataUsageUpdated(usage spv.DataUsage) {
+	mw.syncData.mu.Lock()
+	mw.syncData.dataUsage = usage
+	persist := time.Since(mw.syncData.dataUsagePersistedAt) >= syncDataUsagePersistInterval
+	mw.syncData.mu.Unlock()
+
+	if persist {
+		mw.persistSyncDataUsage()
+	}
+
+	syncDataUsage := newSyncDataUsage(usage)
+
+	// The listeners are called from separate goroutines so that they do not
+	// hold up the peers reporting the data usage.
+	mw.notificationListenersMu.RLock()
+	listeners := make([]SyncDataListener, 0, len(mw.syncDataListeners))
+	for _, listener := range mw.syncDataListeners {
+		listeners = append(listeners, listener)
+	}
+	mw.notificationListenersMu.RUnlock()
+
+	for _, listener := range listeners {
+		go listener.OnSyncDataUpdated(syncDataUsage)
+	}
+}
+
+// persistSyncDataUsage adds the data exchanged since the last call to the
+// total saved for the current date.
+func (mw *MultiWallet) persistSyncDataUsage() {
+	mw.syncData.mu.Lock()
+	total := mw.syncData.dataUsage.TotalReceived() + mw.syncData.dataUsage.TotalSent()
+	unsaved := total - mw.syncData.persistedDataUsage
+	mw.syncData.persistedDataUsage = total
+	mw.syncData.dataUsagePersistedAt = time.Now()
+	mw.syncData.mu.Unlock()
+
+	if unsaved <= 0 {
+		return
+	}
+
+	mw.syncDataUsageMu.Lock()
+	defer mw.syncDataUsageMu.Unlock()
+
+	history := mw.syncDataUsageHistory()
+	history[time.Now().Format(syncDataUsageDateFormat)] += unsaved
+	mw.SaveUserConfigValue(SyncDataUsageConfigKey, history)
+}
@@ -4,6 +4,7 @@ import (
 	"math"
 	"time"
 
+	"decred.org/dcrwallet/v2/errors"
 	"github.com/planetdecred/dcrlibwallet/spv"
 	"golang.org/x/sync/errgroup"
 )
@@ -11,6 +12,7 @@ import (
 func (mw *MultiWallet) spvSyncNotificationCallbacks() *spv.Notifications {
 	return &spv.Notifications{
 		PeerConnected: func(peerCount int32, addr string) {
+			mw.peerConnected(addr)
 			mw.handlePeerCountUpdate(peerCount)
 		},
 		PeerDisconnected: func(peerCount int32, addr string) {
@@ -28,6 +30,8 @@ func (mw *MultiWallet) spvSyncNotificationCallbacks() *spv.Notifications {
 		RescanStarted:                mw.rescanStarted,
 		RescanProgress:               mw.rescanProgress,
 		RescanFinished:               mw.rescanFinished,
+		PeersSeeded:                  mw.peersSeeded,
+		DataUsageUpdated:             mw.syncDataUsageUpdated,
 	}
 }
 
@@ -50,6 +54,25 @@ func (mw *MultiWallet) handlePeerCountUpdate(peerCount int32) {
 	}
 }
 
+// peerConnected records the newly connected peer for the sync summary and
+// the highest block height announced by the currently connected peers as
+// the network's best block height.
+func (mw *MultiWallet) peerConnected(addr string) {
+	mw.syncData.mu.Lock()
+	defer mw.syncData.mu.Unlock()
+
+	if mw.syncData.activeSyncData == nil || mw.syncData.syncer == nil {
+		return
+	}
+
+	mw.syncData.activeSyncData.peersUsed[addr] = struct{}{}
+
+	syncer := mw.syncData.syncer
+	for _, rp := range syncer.GetRemotePeers() {
+		mw.updateBestBlockOnNetwork(syncer.LastAnnouncedHeight(rp))
+	}
+}
+
 // Fetch CFilters Callbacks
 
 func (mw *MultiWallet) fetchCFiltersStarted(walletID int) {
@@ -155,10 +178,11 @@ func (mw *MultiWallet) fetchHeadersStarted(peerInitialHeight int32) {
 		return
 	}
 
-	mw.syncData.mu.RLock()
+	mw.syncData.mu.Lock()
+	mw.updateBestBlockOnNetwork(peerInitialHeight)
 	headersFetchingStarted := mw.syncData.headersFetchProgress.beginFetchTimeStamp != -1
 	showLogs := mw.syncData.showLogs
-	mw.syncData.mu.RUnlock()
+	mw.syncData.mu.Unlock()
 
 	if headersFetchingStarted {
 		// This function gets called for each newly connected peer so
@@ -213,8 +237,15 @@ func (mw *MultiWallet) fetchHeadersProgress(lastFetchedHeaderHeight int32, lastF
 		mw.syncData.activeSyncData.headersFetchProgress.totalFetchedHeadersCount = lastFetchedHeaderHeight - mw.syncData.activeSyncData.headersFetchProgress.startHeaderHeight
 	}
 
+	mw.updateBestBlockOnNetwork(lastFetchedHeaderHeight)
+
 	headersLeftToFetch := mw.estimateBlockHeadersCountAfter(lastFetchedHeaderTime)
 	totalHeadersToFetch := lastFetchedHeaderHeight + headersLeftToFetch
+	if mw.syncData.bestBlockOnNetwork > totalHeadersToFetch {
+		// Peers have reported a higher height than estimated from the
+		// target time per block, use the reported height instead.
+		totalHeadersToFetch = mw.syncData.bestBlockOnNetwork
+	}
 	headersFetchProgress := float64(mw.syncData.activeSyncData.headersFetchProgress.totalFetchedHeadersCount) / float64(totalHeadersToFetch)
 
 	// If there was some period of inactivity,
@@ -251,6 +282,7 @@ func (mw *MultiWallet) fetchHeadersProgress(lastFetchedHeaderHeight int32, lastF
 	mw.syncData.activeSyncData.headersFetchProgress.CurrentHeaderHeight = lastFetchedHeaderHeight
 	mw.syncData.activeSyncData.headersFetchProgress.CurrentHeaderTimestamp = lastFetchedHeaderTime
 	mw.syncData.activeSyncData.headersFetchProgress.HeadersFetchProgress = roundUp(headersFetchProgress * 100.0)
+	mw.syncData.activeSyncData.headersFetchProgress.BestBlockOnNetwork = mw.syncData.bestBlockOnNetwork
 	mw.syncData.activeSyncData.headersFetchProgress.TotalSyncProgress = roundUp(totalSyncProgress * 100.0)
 	mw.syncData.activeSyncData.headersFetchProgress.TotalTimeRemainingSeconds = totalTimeRemainingSeconds
 
@@ -288,6 +320,7 @@ func (mw *MultiWallet) fetchHeadersFinished() {
 		return
 	}
 
+	mw.syncData.activeSyncData.headersFetched += mw.syncData.headersFetchProgress.totalFetchedHeadersCount
 	mw.syncData.activeSyncData.headersFetchProgress.startHeaderHeight = -1
 	mw.syncData.headersFetchProgress.totalFetchedHeadersCount = 0
 	mw.syncData.activeSyncData.headersFetchProgress.headersFetchTimeSpent = time.Now().Unix() - mw.syncData.headersFetchProgress.beginFetchTimeStamp
@@ -314,6 +347,15 @@ func (mw *MultiWallet) discoverAddressesStarted(walletID int) {
 		return
 	}
 
+	addressUsage, err := mw.wallets[walletID].addressUsage()
+	if err != nil {
+		log.Errorf("[%d] Error reading address usage: %v", walletID, err)
+	} else {
+		mw.syncData.mu.Lock()
+		mw.syncData.activeSyncData.addressUsageBeforeDiscovery[walletID] = addressUsage
+		mw.syncData.mu.Unlock()
+	}
+
 	mw.syncData.mu.RLock()
 	addressDiscoveryAlreadyStarted := mw.syncData.activeSyncData.addressDiscoveryProgress.addressDiscoveryStartTime != -1
 	totalHeadersFetchTime := float64(mw.syncData.activeSyncData.headersFetchProgress.headersFetchTimeSpent)
@@ -439,6 +481,18 @@ func (mw *MultiWallet) discoverAddressesFinished(walletID int) {
 		return
 	}
 
+	addressUsage, err := mw.wallets[walletID].addressUsage()
+	if err != nil {
+		log.Errorf("[%d] Error reading address usage: %v", walletID, err)
+	} else {
+		mw.syncData.mu.Lock()
+		usageBeforeDiscovery, ok := mw.syncData.activeSyncData.addressUsageBeforeDiscovery[walletID]
+		if ok && usageBeforeDiscovery != addressUsage {
+			mw.syncData.activeSyncData.addressDiscoveryFoundActivity = true
+		}
+		mw.syncData.mu.Unlock()
+	}
+
 	mw.stopUpdatingAddressDiscoveryProgress()
 }
 
@@ -503,6 +557,11 @@ func (mw *MultiWallet) rescanProgress(walletID int, rescannedThrough int32) {
 	totalElapsedTime := mw.syncData.activeSyncData.cfiltersFetchProgress.cfiltersFetchTimeSpent + mw.syncData.activeSyncData.headersFetchProgress.headersFetchTimeSpent +
 		mw.syncData.activeSyncData.addressDiscoveryProgress.totalDiscoveryTimeSpent + elapsedRescanTime
 
+	if _, ok := mw.syncData.activeSyncData.rescanStartHeights[walletID]; !ok {
+		mw.syncData.activeSyncData.rescanStartHeights[walletID] = rescannedThrough
+	}
+	mw.syncData.activeSyncData.rescanEndHeights[walletID] = rescannedThrough
+
 	mw.syncData.activeSyncData.headersRescanProgress.WalletID = walletID
 	mw.syncData.activeSyncData.headersRescanProgress.TotalHeadersToScan = totalHeadersToScan
 	mw.syncData.activeSyncData.headersRescanProgress.RescanProgress = int32(math.Round(rescanRate * 100))
@@ -572,6 +631,22 @@ func (mw *MultiWallet) rescanFinished(walletID int) {
 	mw.publishHeadersRescanProgress()
 }
 
+// addressUsage returns a value derived from the number of accounts and the
+// last used address indexes of each account in the wallet. The value changes
+// whenever new accounts or addresses are found to have been used.
+func (wallet *Wallet) addressUsage() (uint32, error) {
+	resp, err := wallet.Internal().Accounts(wallet.shutdownContext())
+	if err != nil {
+		return 0, err
+	}
+
+	var usage uint32
+	for _, a := range resp.Accounts {
+		usage += a.LastUsedExternalIndex + a.LastUsedInternalIndex + 1
+	}
+	return usage, nil
+}
+
 func (mw *MultiWallet) publishDebugInfo(debugInfo *DebugInfo) {
 	for _, syncProgressListener := range mw.syncProgressListeners() {
 		syncProgressListener.Debug(debugInfo)
@@ -592,8 +667,17 @@ func (mw *MultiWallet) estimateBlockHeadersCountAfter(lastHeaderTime int64) int3
 }
 
 func (mw *MultiWallet) notifySyncError(err error) {
+	syncError := &SyncError{
+		Code:    ErrorCode(err),
+		Message: translateError(err).Error(),
+		err:     err,
+	}
+	if kind := errorKind(err); kind != errors.Other {
+		syncError.Kind = kind.String()
+	}
+
 	for _, syncProgressListener := range mw.syncProgressListeners() {
-		syncProgressListener.OnSyncEndedWithError(err)
+		syncProgressListener.OnSyncEndedWithError(syncError)
 	}
 }
 
@@ -631,7 +715,7 @@ func (mw *MultiWallet) synced(walletID int, synced bool) {
 
 	indexTransactions := func() {
 		// begin indexing transactions after sync is completed,
-		// syncProgressListeners.OnSynced() will be invoked after transactions are indexed
+		// syncProgressListeners.OnSyncCompleted() will be invoked after transactions are indexed
 		var txIndexing errgroup.Group
 		for _, wallet := range mw.wallets {
 			txIndexing.Go(wallet.IndexTransactions)
@@ -643,12 +727,20 @@ func (mw *MultiWallet) synced(walletID int, synced bool) {
 				log.Errorf("Tx Index Error: %v", err)
 			}
 
-			for _, syncProgressListener := range mw.syncProgressListeners() {
-				if synced {
-					syncProgressListener.OnSyncCompleted()
-				} else {
-					syncProgressListener.OnSyncCanceled(false)
+			mw.syncData.mu.RLock()
+			summary := mw.syncSummary()
+			mw.syncData.mu.RUnlock()
+
+			if synced {
+				for _, syncProgressListener := range mw.syncProgressListeners() {
+					syncProgressListener.OnSyncCompleted(summary)
+				}
+
+				for _, wallet := range mw.wallets {
+					mw.checkBalanceChanges(wallet)
 				}
+				mw.resumePausedRescan()
+				mw.rescanRestoredWallets()
 			}
 		}()
 	}
@@ -2,6 +2,7 @@ package dcrlibwallet
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"runtime/trace"
 	"sync"
@@ -9,17 +10,44 @@ import (
 
 	"decred.org/dcrwallet/v2/errors"
 	w "decred.org/dcrwallet/v2/wallet"
+	"decred.org/dcrwallet/v2/wallet/txrules"
+	"decred.org/dcrwallet/v2/wallet/txsizes"
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrd/wire"
 	"github.com/planetdecred/dcrlibwallet/internal/vsp"
 	"github.com/planetdecred/dcrlibwallet/utils"
+	"github.com/planetdecred/dcrlibwallet/walletdata"
 )
 
 func (wallet *Wallet) TotalStakingRewards() (int64, error) {
-	voteTransactions, err := wallet.GetTransactionsRaw(0, 0, TxFilterVoted, true)
+	return wallet.StakingRewardsInRange(0, 0)
+}
+
+// StakingRewardsInRange returns the sum of the rewards of the wallet's votes
+// with a timestamp between startTimestamp and endTimestamp, inclusive. A
+// timestamp of 0 leaves that end of the range open.
+func (wallet *Wallet) StakingRewardsInRange(startTimestamp, endTimestamp int64) (int64, error) {
+	if !wallet.WalletOpened() {
+		return 0, errors.New(ErrWalletNotLoaded)
+	}
+
+	if startTimestamp < 0 || endTimestamp < 0 || (endTimestamp > 0 && endTimestamp < startTimestamp) {
+		return 0, fmt.Errorf("%s: invalid time range", ErrInvalid)
+	}
+
+	txQuery := &walletdata.TxQuery{
+		Direction:    TxDirectionInvalid,
+		Type:         TxTypeVote,
+		MinTimestamp: startTimestamp,
+		MaxTimestamp: endTimestamp,
+	}
+
+	var voteTransactions []Transaction
+	err := wallet.walletDataDB.ReadWithQuery(txQuery, 0, 0, true, &voteTransactions)
 	if err != nil {
-		return 0, err
+		return 0, translateError(err)
 	}
 
 	var totalRewards int64
@@ -31,9 +59,19 @@ func (wallet *Wallet) TotalStakingRewards() (int64, error) {
 }
 
 func (mw *MultiWallet) TotalStakingRewards() (int64, error) {
+	return mw.StakingRewardsInRange(0, 0)
+}
+
+// StakingRewardsInRange returns the sum of the rewards of the votes of all
+// wallets with a timestamp between startTimestamp and endTimestamp.
+func (mw *MultiWallet) StakingRewardsInRange(startTimestamp, endTimestamp int64) (int64, error) {
 	var totalRewards int64
 	for _, wal := range mw.wallets {
-		walletTotalRewards, err := wal.TotalStakingRewards()
+		if !wal.WalletOpened() {
+			continue
+		}
+
+		walletTotalRewards, err := wal.StakingRewardsInRange(startTimestamp, endTimestamp)
 		if err != nil {
 			return 0, err
 		}
@@ -95,6 +133,10 @@ func (mw *MultiWallet) StakingOverview() (stOverview *StakingOverview, err error
 	stOverview = &StakingOverview{}
 
 	for _, wallet := range mw.wallets {
+		if !wallet.WalletOpened() {
+			continue
+		}
+
 		st, err := wallet.StakingOverview()
 		if err != nil {
 			return nil, err
@@ -114,9 +156,162 @@ func (mw *MultiWallet) StakingOverview() (stOverview *StakingOverview, err error
 	return stOverview, nil
 }
 
+// StakeInfo returns a JSON summary of the wallet's tickets, see StakeInfoRaw.
+func (wallet *Wallet) StakeInfo() (string, error) {
+	stakeInfo, err := wallet.StakeInfoRaw()
+	if err != nil {
+		return "", err
+	}
+
+	result, _ := json.Marshal(stakeInfo)
+	return string(result), nil
+}
+
+// StakeInfoRaw returns the number of the wallet's tickets in each state,
+// including its unmined tickets, and the total rewards earned from votes. The
+// result is cached until the next block is attached or a new transaction is
+// received.
+func (wallet *Wallet) StakeInfoRaw() (*StakeInfo, error) {
+	ctx := wallet.shutdownContext()
+	_, tipHeight := wallet.Internal().MainChainTip(ctx)
+
+	wallet.stakeInfoMu.Lock()
+	defer wallet.stakeInfoMu.Unlock()
+
+	if wallet.stakeInfo != nil && wallet.stakeInfo.Height == tipHeight {
+		return wallet.stakeInfo, nil
+	}
+
+	data, err := wallet.Internal().StakeInfo(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	totalRewards, err := wallet.TotalStakingRewards()
+	if err != nil {
+		return nil, err
+	}
+
+	wallet.stakeInfo = &StakeInfo{
+		Height:       int32(data.BlockHeight),
+		Immature:     int32(data.Immature),
+		Live:         int32(data.Live),
+		Voted:        int32(data.Voted),
+		Missed:       int32(data.Missed),
+		Expired:      int32(data.Expired),
+		Revoked:      int32(data.Revoked),
+		Unmined:      int32(data.OwnMempoolTix),
+		TotalRewards: totalRewards,
+		// The ticket pool and the mempool of other peers are not known
+		// to SPV wallets.
+		PoolSize:      -1,
+		AllMempoolTix: -1,
+	}
+	return wallet.stakeInfo, nil
+}
+
+func (wallet *Wallet) clearStakeInfoCache() {
+	wallet.stakeInfoMu.Lock()
+	wallet.stakeInfo = nil
+	wallet.stakeInfoMu.Unlock()
+}
+
+// GetTickets returns a JSON array of the wallet's tickets, see GetTicketsRaw.
+func (wallet *Wallet) GetTickets(offset, limit, txFilter int32) (string, error) {
+	tickets, err := wallet.GetTicketsRaw(offset, limit, txFilter)
+	if err != nil {
+		return "", err
+	}
+
+	result, _ := json.Marshal(tickets)
+	return string(result), nil
+}
+
+// GetTicketsRaw returns the wallet's tickets, newest first, matching one of
+// the ticket filters TxFilterTickets, TxFilterUnmined, TxFilterImmature,
+// TxFilterLive, TxFilterExpired, TxFilterVoted or TxFilterRevoked. Missed
+// tickets cannot be detected over SPV and are reported as live or expired.
+func (wallet *Wallet) GetTicketsRaw(offset, limit, txFilter int32) ([]*Ticket, error) {
+	switch txFilter {
+	case TxFilterVoted, TxFilterRevoked:
+		// Page through the spenders, the ticket of each spender is looked up
+		// below.
+		spenders, err := wallet.GetTransactionsRaw(offset, limit, txFilter, true)
+		if err != nil {
+			return nil, err
+		}
+
+		tickets := make([]*Ticket, 0, len(spenders))
+		for i := range spenders {
+			ticketTx, err := wallet.GetTransactionRaw(spenders[i].TicketSpentHash)
+			if err != nil {
+				return nil, err
+			}
+			tickets = append(tickets, wallet.ticketInfo(ticketTx, &spenders[i]))
+		}
+		return tickets, nil
+
+	case TxFilterTickets, TxFilterUnmined, TxFilterImmature, TxFilterLive, TxFilterExpired:
+		ticketTxs, err := wallet.GetTransactionsRaw(offset, limit, txFilter, true)
+		if err != nil {
+			return nil, err
+		}
+
+		tickets := make([]*Ticket, 0, len(ticketTxs))
+		for i := range ticketTxs {
+			spender, err := wallet.TicketSpender(ticketTxs[i].Hash)
+			if err != nil {
+				return nil, err
+			}
+			tickets = append(tickets, wallet.ticketInfo(&ticketTxs[i], spender))
+		}
+		return tickets, nil
+	}
+
+	return nil, errors.New(ErrInvalid)
+}
+
+// ticketInfo returns the Ticket for ticketTx, spender is nil if the ticket
+// has not been spent.
+func (wallet *Wallet) ticketInfo(ticketTx, spender *Transaction) *Ticket {
+	ticket := &Ticket{
+		Hash:              ticketTx.Hash,
+		PurchaseHeight:    ticketTx.BlockHeight,
+		PurchaseTimestamp: ticketTx.Timestamp,
+	}
+
+	// The first output of a ticket is the stake submission paying the price.
+	if len(ticketTx.Outputs) > 0 {
+		ticket.Price = ticketTx.Outputs[0].Amount
+	}
+
+	if spender != nil {
+		ticket.SpenderHash = spender.Hash
+		ticket.Reward = spender.VoteReward
+		if spender.Type == TxTypeVote {
+			ticket.Status = TicketStatusVoted
+		} else {
+			ticket.Status = TicketStatusRevoked
+		}
+	} else {
+		ticket.Status = ticketTx.TicketStatus(int32(wallet.chainParams.TicketMaturity),
+			int32(wallet.chainParams.TicketExpiry), wallet.GetBestBlock())
+	}
+
+	// Tickets not bought through a VSP have no stored VSP info.
+	if hash, err := chainhash.NewHashFromStr(ticketTx.Hash); err == nil {
+		if vspInfo, err := wallet.Internal().VSPTicketInfo(wallet.shutdownContext(), hash); err == nil {
+			ticket.VSPHost = vspInfo.Host
+		}
+	}
+
+	return ticket
+}
+
 // TicketPrice returns the price of a ticket for the next block, also known as
 // the stake difficulty. May be incorrect if blockchain sync is ongoing or if
-// blockchain is not up-to-date.
+// blockchain is not up-to-date. If the wallet is not connected to the network,
+// the price derived from the best known block is returned and marked Stale.
 func (wallet *Wallet) TicketPrice() (*TicketPriceResponse, error) {
 	ctx := wallet.shutdownContext()
 	sdiff, err := wallet.Internal().NextStakeDifficulty(ctx)
@@ -125,15 +320,22 @@ func (wallet *Wallet) TicketPrice() (*TicketPriceResponse, error) {
 	}
 
 	_, tipHeight := wallet.Internal().MainChainTip(ctx)
+	_, err = wallet.Internal().NetworkBackend()
 	resp := &TicketPriceResponse{
 		TicketPrice: int64(sdiff),
 		Height:      tipHeight,
+		Stale:       err != nil || wallet.IsSyncing(),
 	}
+	resp.ActivationHeight, resp.SecondsToNextPrice = nextTicketPriceChange(wallet.chainParams, tipHeight)
 	return resp, nil
 }
 
+// TicketPrice returns the ticket price from the first wallet synced to the
+// best block. If no wallet is at the best block, the price from the wallet
+// with the highest tip is returned and marked Stale.
 func (mw *MultiWallet) TicketPrice() (*TicketPriceResponse, error) {
 	bestBlock := mw.GetBestBlock()
+	var bestResp *TicketPriceResponse
 	for _, wal := range mw.wallets {
 		resp, err := wal.TicketPrice()
 		if err != nil {
@@ -143,9 +345,28 @@ func (mw *MultiWallet) TicketPrice() (*TicketPriceResponse, error) {
 		if resp.Height == bestBlock.Height {
 			return resp, nil
 		}
+
+		if bestResp == nil || resp.Height > bestResp.Height {
+			bestResp = resp
+		}
+	}
+
+	if bestResp == nil {
+		return nil, errors.New(ErrWalletNotFound)
 	}
 
-	return nil, errors.New(ErrWalletNotFound)
+	bestResp.Stale = true
+	return bestResp, nil
+}
+
+// nextTicketPriceChange returns the height at which the stake difficulty
+// window following tipHeight starts and the estimated seconds until then.
+func nextTicketPriceChange(params *chaincfg.Params, tipHeight int32) (activationHeight int32, secs int64) {
+	windowSize := int32(params.StakeDiffWindowSize)
+	activationHeight = (tipHeight/windowSize + 1) * windowSize
+	blocksRemaining := activationHeight - tipHeight - 1
+	secs = int64(time.Duration(blocksRemaining) * params.TargetTimePerBlock / time.Second)
+	return
 }
 
 // PurchaseTickets purchases tickets from the wallet.
@@ -161,11 +382,11 @@ func (wallet *Wallet) PurchaseTickets(account, numTickets int32, vspHost string,
 		return nil, err
 	}
 
-	err = wallet.UnlockWallet(passphrase)
+	relock, err := wallet.unlockTemporarily(passphrase)
 	if err != nil {
 		return nil, translateError(err)
 	}
-	defer wallet.LockWallet()
+	defer relock()
 
 	// Use the user-specified instructions for processing fee payments
 	// for this ticket, rather than some default policy.
@@ -203,6 +424,252 @@ func (wallet *Wallet) PurchaseTickets(account, numTickets int32, vspHost string,
 	return ticketsResponse.TicketHashes, err
 }
 
+// PurchaseTickets buys numTickets solo tickets from the specified account of
+// the wallet with walletID, spending only outputs with at least requiredConfs
+// confirmations. The tickets expire if not mined by the expiry height, a 0
+// expiry means no expiry. The purchased tickets are indexed and published to
+// the tx notification listeners. Returns the hashes of the purchased tickets.
+func (mw *MultiWallet) PurchaseTickets(walletID int, privPass []byte, account, numTickets, requiredConfs,
+	expiry int32) ([]string, error) {
+
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return nil, errors.New(ErrNotExist)
+	}
+
+	if numTickets < 1 {
+		return nil, errors.E(errors.Invalid, "number of tickets must be at least 1")
+	}
+
+	networkBackend, err := wallet.Internal().NetworkBackend()
+	if err != nil {
+		return nil, errors.New(ErrNotConnected)
+	}
+
+	if err := wallet.checkTicketsAffordable(account, numTickets, requiredConfs, 0); err != nil {
+		return nil, err
+	}
+
+	relock, err := wallet.unlockTemporarily(privPass)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer relock()
+
+	request := &w.PurchaseTicketsRequest{
+		Count:         int(numTickets),
+		SourceAccount: uint32(account),
+		MinConf:       requiredConfs,
+		Expiry:        expiry,
+	}
+
+	// Mixed split buying through CoinShuffle++, if configured.
+	if csppCfg := wallet.readCSPPConfig(); csppCfg != nil {
+		request.CSPPServer = csppCfg.CSPPServer
+		request.DialCSPPServer = csppCfg.DialCSPPServer
+		request.MixedAccount = csppCfg.MixedAccount
+		request.MixedAccountBranch = csppCfg.MixedAccountBranch
+		request.ChangeAccount = csppCfg.ChangeAccount
+		request.MixedSplitAccount = csppCfg.TicketSplitAccount
+	}
+
+	ctx := wallet.shutdownContext()
+	ticketsResponse, err := wallet.Internal().PurchaseTickets(ctx, networkBackend, request)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	ticketHashes := make([]string, len(ticketsResponse.TicketHashes))
+	for i, hash := range ticketsResponse.TicketHashes {
+		ticketHashes[i] = hash.String()
+
+		// The tickets have been published at this point, failing to index
+		// them only delays them showing up until the mempool notification
+		// is received.
+		ticket, overwritten, err := wallet.indexTransaction(hash)
+		if err != nil {
+			log.Errorf("[%d] Error indexing purchased ticket %s: %v", wallet.ID, hash, err)
+			continue
+		}
+		if overwritten {
+			continue
+		}
+
+		mw.publishTicketNotification(wallet, ticket)
+
+		result, err := json.Marshal(ticket)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		mw.mempoolTransactionNotification(string(result))
+	}
+
+	return ticketHashes, nil
+}
+
+// EstimateTicketPurchase returns the estimated cost of buying numTickets
+// tickets from account, see EstimateTicketPurchaseRaw.
+func (wallet *Wallet) EstimateTicketPurchase(account, numTickets, requiredConfs int32) (string, error) {
+	estimate, err := wallet.EstimateTicketPurchaseRaw(account, numTickets, requiredConfs)
+	if err != nil {
+		return "", err
+	}
+
+	result, _ := json.Marshal(estimate)
+	return string(result), nil
+}
+
+// EstimateTicketPurchaseRaw returns the estimated cost of buying numTickets
+// tickets from account and whether the outputs of the account with at least
+// requiredConfs confirmations can pay for them. The VSP fee is included if a
+// VSP is set for the wallet.
+func (wallet *Wallet) EstimateTicketPurchaseRaw(account, numTickets, requiredConfs int32) (*TicketPurchaseEstimate, error) {
+	if numTickets < 1 {
+		return nil, errors.E(errors.Invalid, "number of tickets must be at least 1")
+	}
+
+	vspFeePercentage, err := wallet.vspFeePercentage()
+	if err != nil {
+		return nil, err
+	}
+
+	estimate, _, err := wallet.estimateTicketPurchase(account, numTickets, requiredConfs, vspFeePercentage)
+	return estimate, err
+}
+
+// MaxTicketsAffordable returns the number of tickets that the spendable
+// balance of account can pay for at the current ticket price, including fees
+// and the VSP fee if a VSP is set for the wallet.
+func (wallet *Wallet) MaxTicketsAffordable(account int32) (int32, error) {
+	vspFeePercentage, err := wallet.vspFeePercentage()
+	if err != nil {
+		return 0, err
+	}
+
+	cost, err := wallet.ticketCost(vspFeePercentage)
+	if err != nil {
+		return 0, err
+	}
+
+	spendable, err := wallet.SpendableForAccount(account)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(dcrutil.Amount(spendable) / cost.total()), nil
+}
+
+// vspFeePercentage returns the fee percentage of the VSP set for the wallet,
+// or zero if no VSP is set.
+func (wallet *Wallet) vspFeePercentage() (float64, error) {
+	if wallet.ReadStringConfigValueForKey(VSPHostConfigKey, "") == "" {
+		return 0, nil
+	}
+
+	_, info, err := wallet.vspInfo()
+	if err != nil {
+		return 0, err
+	}
+	return info.FeePercentage, nil
+}
+
+// checkTicketsAffordable returns an ErrInsufficientBalance error if the
+// outputs of account with at least requiredConfs confirmations cannot pay
+// for numTickets tickets. It is the same check EstimateTicketPurchaseRaw
+// reports, so that an estimate marked affordable is not rejected on purchase.
+func (wallet *Wallet) checkTicketsAffordable(account, numTickets, requiredConfs int32, vspFeePercentage float64) error {
+	estimate, spendable, err := wallet.estimateTicketPurchase(account, numTickets, requiredConfs, vspFeePercentage)
+	if err != nil {
+		return err
+	}
+
+	if !estimate.Affordable {
+		return fmt.Errorf("%s: short by %v", ErrInsufficientBalance, dcrutil.Amount(estimate.TotalCost)-spendable)
+	}
+	return nil
+}
+
+// estimateTicketPurchase returns the estimated cost of buying numTickets
+// tickets from account and the spendable balance of the account with at
+// least requiredConfs confirmations. vspFeePercentage is zero for solo
+// tickets.
+func (wallet *Wallet) estimateTicketPurchase(account, numTickets, requiredConfs int32,
+	vspFeePercentage float64) (*TicketPurchaseEstimate, dcrutil.Amount, error) {
+
+	cost, err := wallet.ticketCost(vspFeePercentage)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	spendable, err := wallet.SpendableForAccountWithConfirmations(account, requiredConfs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	totalCost := cost.total() * dcrutil.Amount(numTickets)
+	return &TicketPurchaseEstimate{
+		TicketPrice:     int64(cost.price),
+		FeePerTicket:    int64(cost.txFee),
+		VSPFeePerTicket: int64(cost.vspFee),
+		TotalCost:       int64(totalCost),
+		Affordable:      dcrutil.Amount(spendable) >= totalCost,
+	}, dcrutil.Amount(spendable), nil
+}
+
+// ticketPurchaseCost is the cost of buying a single ticket.
+type ticketPurchaseCost struct {
+	price  dcrutil.Amount
+	txFee  dcrutil.Amount
+	vspFee dcrutil.Amount
+}
+
+func (cost *ticketPurchaseCost) total() dcrutil.Amount {
+	return cost.price + cost.txFee + cost.vspFee
+}
+
+// ticketCost returns the cost of buying a ticket in the next block. The fees
+// are calculated the same way as the wallet does when purchasing tickets. If
+// vspFeePercentage is not zero, the fee paid to the VSP is included.
+func (wallet *Wallet) ticketCost(vspFeePercentage float64) (*ticketPurchaseCost, error) {
+	ctx := wallet.shutdownContext()
+	ticketPrice, err := wallet.Internal().NextStakeDifficulty(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// A solo ticket has one P2PKH input, an OP_SSTX tagged P2PKH output, a
+	// commitment output and an OP_SSTXCHANGE tagged P2PKH output. The
+	// P2PKH output of the split transaction that funds the ticket is
+	// included as well.
+	inSizes := []int{txsizes.RedeemP2PKHSigScriptSize}
+	outSizes := []int{txsizes.P2PKHPkScriptSize + 1, txsizes.TicketCommitmentScriptSize,
+		txsizes.P2PKHPkScriptSize + 1}
+	ticketSize := txsizes.EstimateSerializeSizeFromScriptSizes(inSizes, outSizes, 0)
+
+	relayFee := wallet.Internal().RelayFee()
+	ticketFee := txrules.FeeForSerializeSize(relayFee, ticketSize)
+	cost := &ticketPurchaseCost{
+		price: ticketPrice,
+		txFee: ticketFee + txrules.FeeForSerializeSize(relayFee, txsizes.P2PKHOutputSize),
+	}
+
+	if vspFeePercentage != 0 {
+		// The wallet assumes DCP0010 is active in SPV mode.
+		_, tipHeight := wallet.Internal().MainChainTip(ctx)
+		cost.vspFee = txrules.StakePoolTicketFee(ticketPrice, ticketFee, tipHeight, vspFeePercentage,
+			wallet.chainParams, true)
+	}
+
+	return cost, nil
+}
+
 // VSPTicketInfo returns vsp-related info for a given ticket. Returns an error
 // if the ticket is not yet assigned to a VSP.
 func (mw *MultiWallet) VSPTicketInfo(walletID int, hash string) (*VSPTicketInfo, error) {
@@ -277,6 +744,71 @@ func (mw *MultiWallet) VSPTicketInfo(walletID int, hash string) (*VSPTicketInfo,
 	return ticketInfo, nil
 }
 
+// HasRevocableTickets returns true if the wallet has expired tickets that
+// have not been revoked.
+func (wallet *Wallet) HasRevocableTickets() bool {
+	count, err := wallet.CountTransactions(TxFilterExpired)
+	if err != nil {
+		log.Errorf("[%d] Error counting expired tickets: %v", wallet.ID, err)
+		return false
+	}
+	return count > 0
+}
+
+// RevokeTickets creates and publishes revocations for the expired tickets of
+// the wallet with walletID and returns the number of tickets revoked. Missed
+// tickets cannot be detected over SPV and are revoked once they expire. The
+// revocations are indexed and published to the tx notification listeners when
+// the wallet is notified of them.
+func (mw *MultiWallet) RevokeTickets(walletID int, privPass []byte) (int32, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return 0, errors.New(ErrNotExist)
+	}
+
+	networkBackend, err := wallet.Internal().NetworkBackend()
+	if err != nil {
+		return 0, errors.New(ErrNotConnected)
+	}
+
+	expiredTickets, err := wallet.GetTransactionsRaw(0, 0, TxFilterExpired, true)
+	if err != nil {
+		return 0, err
+	}
+	if len(expiredTickets) == 0 {
+		return 0, nil
+	}
+
+	relock, err := wallet.unlockTemporarily(privPass)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	defer relock()
+
+	ctx := wallet.shutdownContext()
+	var revoked int32
+	for _, ticket := range expiredTickets {
+		ticketHash, err := chainhash.NewHashFromStr(ticket.Hash)
+		if err != nil {
+			return revoked, err
+		}
+
+		err = wallet.Internal().RevokeTicket(ctx, ticketHash, networkBackend)
+		if err != nil {
+			return revoked, translateError(err)
+		}
+		revoked++
+	}
+
+	return revoked, nil
+}
+
 // StartTicketBuyer starts the automatic ticket buyer. The wallet
 // should already be configured with the required parameters using
 // wallet.SetAutoTicketsBuyerConfig().
@@ -299,11 +831,30 @@ func (wallet *Wallet) StartTicketBuyer(passphrase []byte) error {
 	wallet.cancelAutoTicketBuyer = cancel
 	wallet.cancelAutoTicketBuyerMu.Unlock()
 
+	// The wallet is only unlocked while tickets are being purchased, keep a
+	// copy of the passphrase for the purchases.
+	passphrase = append([]byte(nil), passphrase...)
+
+	stopTicketBuyer := func() {
+		cancel()
+		wallet.cancelAutoTicketBuyerMu.Lock()
+		wallet.cancelAutoTicketBuyer = nil
+		wallet.cancelAutoTicketBuyerMu.Unlock()
+
+		for i := range passphrase {
+			passphrase[i] = 0
+		}
+	}
+
 	// Validate the passphrase.
-	if len(passphrase) > 0 && wallet.IsLocked() {
-		err := wallet.UnlockWallet(passphrase)
+	if len(passphrase) > 0 {
+		valid, err := wallet.VerifyPrivatePassphrase(append([]byte(nil), passphrase...))
+		if err == nil && !valid {
+			err = errors.New(ErrInvalidPassphrase)
+		}
 		if err != nil {
-			return translateError(err)
+			stopTicketBuyer()
+			return err
 		}
 	}
 
@@ -313,11 +864,17 @@ func (wallet *Wallet) StartTicketBuyer(passphrase []byte) error {
 		cfg.vspClient, err = wallet.VSPClient(cfg.VspHost, vspInfo.PubKey)
 	}
 	if err != nil {
+		stopTicketBuyer()
 		return fmt.Errorf("error setting up vsp client: %v", err)
 	}
 
 	go func() {
 		log.Infof("[%d] Running ticket buyer", wallet.ID)
+		defer func() {
+			for i := range passphrase {
+				passphrase[i] = 0
+			}
+		}()
 
 		err := wallet.runTicketBuyer(ctx, passphrase, cfg)
 		if err != nil {
@@ -340,13 +897,6 @@ func (wallet *Wallet) StartTicketBuyer(passphrase []byte) error {
 // incorrect, or ever becomes incorrect due to a wallet passphrase change,
 // runTicketBuyer exits with an errors.Passphrase error.
 func (wallet *Wallet) runTicketBuyer(ctx context.Context, passphrase []byte, cfg *TicketBuyerConfig) error {
-	if len(passphrase) > 0 && wallet.IsLocked() {
-		err := wallet.UnlockWallet(passphrase)
-		if err != nil {
-			return translateError(err)
-		}
-	}
-
 	c := wallet.Internal().NtfnServer.MainTipChangedNotifications()
 	defer c.Done()
 
@@ -386,6 +936,10 @@ func (wallet *Wallet) runTicketBuyer(ctx context.Context, passphrase []byte, cfg
 				log.Debugf("[%d] Skipping autobuyer actions: transactions are not synced", wallet.ID)
 				continue
 			}
+			if wallet.rescanning != nil && wallet.rescanning() {
+				log.Debugf("[%d] Skipping autobuyer actions: blocks rescan in progress", wallet.ID)
+				continue
+			}
 
 			tipHeader, err := w.BlockHeader(ctx, tip)
 			if err != nil {
@@ -436,7 +990,7 @@ func (wallet *Wallet) runTicketBuyer(ctx context.Context, passphrase []byte, cfg
 			spendable := bal.Spendable
 			if spendable < cfg.BalanceToMaintain {
 				log.Debugf("[%d] Skipping purchase: low available balance", wallet.ID)
-				return nil
+				continue
 			}
 
 			spendable -= cfg.BalanceToMaintain
@@ -448,7 +1002,7 @@ func (wallet *Wallet) runTicketBuyer(ctx context.Context, passphrase []byte, cfg
 			buy := int(dcrutil.Amount(spendable) / sdiff)
 			if buy == 0 {
 				log.Debugf("[%d] Skipping purchase: low available balance", wallet.ID)
-				return nil
+				continue
 			}
 
 			cancelCtx, cancel := context.WithCancel(ctx)
@@ -463,6 +1017,9 @@ func (wallet *Wallet) runTicketBuyer(ctx context.Context, passphrase []byte, cfg
 					case errors.Is(err, context.DeadlineExceeded):
 					default:
 						log.Errorf("[%d] Ticket purchasing failed: %v", wallet.ID, err)
+						if wallet.ticketPurchaseFailed != nil {
+							wallet.ticketPurchaseFailed(wallet.ID, err)
+						}
 					}
 					if errors.Is(err, errors.Passphrase) {
 						fatalMu.Lock()
@@ -487,12 +1044,11 @@ func (wallet *Wallet) buyTicket(ctx context.Context, passphrase []byte, sdiff dc
 	ctx, task := trace.NewTask(ctx, "ticketbuyer.buy")
 	defer task.End()
 
-	if len(passphrase) > 0 && wallet.IsLocked() {
-		err := wallet.UnlockWallet(passphrase)
-		if err != nil {
-			return translateError(err)
-		}
+	relock, err := wallet.unlockForTicketBuyer(passphrase)
+	if err != nil {
+		return err
 	}
+	defer relock()
 
 	networkBackend, err := wallet.Internal().NetworkBackend()
 	if err != nil {
@@ -537,6 +1093,36 @@ func (wallet *Wallet) buyTicket(ctx context.Context, passphrase []byte, sdiff dc
 	return err
 }
 
+// unlockForTicketBuyer unlocks the wallet for a purchase by the ticket buyer.
+// The returned function must be called once the purchase is done, it locks the
+// wallet when no other purchase is ongoing, unless the wallet was already
+// unlocked when the purchases started.
+func (wallet *Wallet) unlockForTicketBuyer(passphrase []byte) (func(), error) {
+	wallet.ticketBuyerUnlockMu.Lock()
+	defer wallet.ticketBuyerUnlockMu.Unlock()
+
+	if wallet.ticketBuyerPurchases == 0 {
+		wallet.ticketBuyerRelock = wallet.IsLocked()
+		if wallet.ticketBuyerRelock {
+			err := wallet.UnlockWallet(passphrase)
+			if err != nil {
+				return nil, translateError(err)
+			}
+		}
+	}
+	wallet.ticketBuyerPurchases++
+
+	return func() {
+		wallet.ticketBuyerUnlockMu.Lock()
+		defer wallet.ticketBuyerUnlockMu.Unlock()
+
+		wallet.ticketBuyerPurchases--
+		if wallet.ticketBuyerPurchases == 0 && wallet.ticketBuyerRelock {
+			wallet.LockWallet()
+		}
+	}, nil
+}
+
 // IsAutoTicketsPurchaseActive returns true if ticket buyer is active.
 func (wallet *Wallet) IsAutoTicketsPurchaseActive() bool {
 	wallet.cancelAutoTicketBuyerMu.Lock()
@@ -544,6 +1130,31 @@ func (wallet *Wallet) IsAutoTicketsPurchaseActive() bool {
 	return wallet.cancelAutoTicketBuyer != nil
 }
 
+// StartTicketBuyer saves the ticket buyer config in configJSON, a JSON
+// encoded TicketBuyerConfig, for the wallet with walletID and starts the
+// ticket buyer. Purchases and failures are reported to the ticket
+// notification listeners.
+func (mw *MultiWallet) StartTicketBuyer(walletID int, privPass []byte, configJSON string) error {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	var cfg TicketBuyerConfig
+	if err := json.Unmarshal([]byte(configJSON), &cfg); err != nil {
+		return errors.New(ErrInvalid)
+	}
+
+	wallet.SetAutoTicketsBuyerConfig(cfg.VspHost, cfg.PurchaseAccount, cfg.BalanceToMaintain)
+	return wallet.StartTicketBuyer(privPass)
+}
+
 // StopAutoTicketsPurchase stops the automatic ticket buyer.
 func (mw *MultiWallet) StopAutoTicketsPurchase(walletID int) error {
 	wallet := mw.WalletWithID(walletID)
@@ -0,0 +1,96 @@
+package dcrlibwallet
+
+import (
+	"decred.org/dcrwallet/v2/errors"
+)
+
+func (mw *MultiWallet) AddTicketNotificationListener(listener TicketNotificationListener, uniqueIdentifier string) error {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	if _, ok := mw.ticketNotificationListeners[uniqueIdentifier]; ok {
+		return errors.New(ErrListenerAlreadyExist)
+	}
+
+	mw.ticketNotificationListeners[uniqueIdentifier] = listener
+	return nil
+}
+
+func (mw *MultiWallet) RemoveTicketNotificationListener(uniqueIdentifier string) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	delete(mw.ticketNotificationListeners, uniqueIdentifier)
+}
+
+// publishTicketNotification notifies the ticket notification listeners if tx,
+// a transaction of wallet that was just indexed for the first time, is a
+// ticket purchase, vote or revocation. Votes and revocations are classified
+// using the hash of the spent ticket found in their inputs, so they are
+// reported even if the ticket has not been indexed yet, as happens when a
+// restored wallet is rescanning.
+func (mw *MultiWallet) publishTicketNotification(wallet *Wallet, tx *Transaction) {
+	switch tx.Type {
+	case TxTypeTicketPurchase:
+		mw.notifyTicketListeners(func(l TicketNotificationListener) {
+			l.OnTicketPurchased(wallet.ID, tx.Hash)
+		})
+
+	case TxTypeVote:
+		mw.notifyTicketListeners(func(l TicketNotificationListener) {
+			l.OnTicketVoted(wallet.ID, tx.TicketSpentHash, tx.Hash, tx.VoteReward)
+		})
+
+	case TxTypeRevocation:
+		missed := wallet.ticketMissed(tx)
+		mw.notifyTicketListeners(func(l TicketNotificationListener) {
+			l.OnTicketMissedOrExpired(wallet.ID, tx.TicketSpentHash, missed)
+		})
+	}
+}
+
+// publishTicketPurchaseFailed notifies the ticket notification listeners
+// that the ticket buyer of the wallet with walletID failed to buy a ticket.
+func (mw *MultiWallet) publishTicketPurchaseFailed(walletID int, err error) {
+	mw.notifyTicketListeners(func(l TicketNotificationListener) {
+		l.OnTicketPurchaseFailed(walletID, err.Error())
+	})
+}
+
+// notifyTicketListeners calls notify for each ticket notification listener
+// from a separate goroutine, so the listeners do not hold up the indexing of
+// transactions and can use the wallet.
+func (mw *MultiWallet) notifyTicketListeners(notify func(l TicketNotificationListener)) {
+	mw.notificationListenersMu.RLock()
+	listeners := make([]TicketNotificationListener, 0, len(mw.ticketNotificationListeners))
+	for _, listener := range mw.ticketNotificationListeners {
+		listeners = append(listeners, listener)
+	}
+	mw.notificationListenersMu.RUnlock()
+
+	for _, listener := range listeners {
+		go notify(listener)
+	}
+}
+
+// ticketMissed returns true if the ticket revoked by revocation was revoked
+// before it expired, i.e. it was called to vote but missed. The ticket is
+// looked up in the wallet if it is not indexed yet. If the ticket cannot be
+// found, it is assumed missed since tickets are far more likely to be missed
+// than to expire.
+func (wallet *Wallet) ticketMissed(revocation *Transaction) bool {
+	ticket, err := wallet.GetTransactionRaw(revocation.TicketSpentHash)
+	if err != nil || ticket.BlockHeight == BlockHeightInvalid {
+		log.Warnf("[%d] Unable to read ticket %s revoked by %s: %v", wallet.ID, revocation.TicketSpentHash,
+			revocation.Hash, err)
+		return true
+	}
+
+	revocationHeight := revocation.BlockHeight
+	if revocationHeight == BlockHeightInvalid {
+		revocationHeight = wallet.GetBestBlock()
+	}
+
+	expiryHeight := ticket.BlockHeight + int32(wallet.chainParams.TicketMaturity) + int32(wallet.chainParams.TicketExpiry)
+	return revocationHeight < expiryHeight
+}
@@ -1,11 +1,18 @@
 package dcrlibwallet
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"sort"
+	"strings"
 
+	"decred.org/dcrwallet/v2/errors"
 	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/wire"
 	"github.com/planetdecred/dcrlibwallet/txhelper"
 	"github.com/planetdecred/dcrlibwallet/walletdata"
 )
@@ -46,16 +53,82 @@ const (
 	TicketStatusLive           = "live"
 	TicketStatusVotedOrRevoked = "votedrevoked"
 	TicketStatusExpired        = "expired"
+	TicketStatusVoted          = "voted"
+	TicketStatusRevoked        = "revoked"
+
+	// Statuses of transactions other than tickets, tickets report one of
+	// the ticket statuses.
+	TxStatusUnmined   = "unmined"
+	TxStatusExpired   = "expired"
+	TxStatusImmature  = "immature"
+	TxStatusPending   = "pending"
+	TxStatusConfirmed = "confirmed"
+
+	// MaxTxNoteLength is the maximum length of a transaction note in bytes.
+	MaxTxNoteLength = 1000
 )
 
 func (wallet *Wallet) PublishUnminedTransactions() error {
 	n, err := wallet.Internal().NetworkBackend()
 	if err != nil {
 		log.Error(err)
-		return err
+		return errors.New(ErrNotConnected)
 	}
 
-	return wallet.Internal().PublishUnminedTransactions(wallet.shutdownContext(), n)
+	err = wallet.Internal().PublishUnminedTransactions(wallet.shutdownContext(), n)
+	if err != nil {
+		return translatePublishError(err)
+	}
+	return nil
+}
+
+// RebroadcastUnminedTransactions publishes the unmined transactions of all
+// opened wallets. It is useful after reconnecting to the network.
+func (mw *MultiWallet) RebroadcastUnminedTransactions() error {
+	for _, wallet := range mw.wallets {
+		if !wallet.WalletOpened() {
+			continue
+		}
+
+		if err := wallet.PublishUnminedTransactions(); err != nil {
+			log.Errorf("[%d] Error rebroadcasting unmined txs: %v", wallet.ID, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishTransaction publishes a serialized signed transaction that may have
+// been created outside of the wallet. The transaction is recorded by the
+// wallet and added to the tx index if it is relevant to the wallet.
+func (wallet *Wallet) PublishTransaction(serializedTx []byte) ([]byte, error) {
+	if !wallet.WalletOpened() {
+		return nil, errors.New(ErrWalletNotLoaded)
+	}
+
+	n, err := wallet.Internal().NetworkBackend()
+	if err != nil {
+		return nil, errors.New(ErrNotConnected)
+	}
+
+	var msgTx wire.MsgTx
+	err = msgTx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, errors.E(errors.Invalid, "invalid transaction")
+	}
+
+	txHash, err := wallet.Internal().PublishTransaction(wallet.shutdownContext(), &msgTx, n)
+	if err != nil {
+		return nil, translatePublishError(err)
+	}
+
+	// The wallet only records transactions that are relevant to it.
+	_, _, err = wallet.indexTransaction(txHash)
+	if err != nil && err.Error() != ErrNotExist {
+		log.Errorf("[%d] Error indexing published tx %s: %v", wallet.ID, txHash, err)
+	}
+
+	return txHash[:], nil
 }
 
 func (wallet *Wallet) GetTransaction(txHash string) (string, error) {
@@ -73,20 +146,42 @@ func (wallet *Wallet) GetTransaction(txHash string) (string, error) {
 	return string(result), nil
 }
 
+// GetTransactionRaw returns the details of the wallet transaction with the
+// provided hash. The tx index is checked first, falling back to the wallet
+// database for transactions that have not been indexed.
 func (wallet *Wallet) GetTransactionRaw(txHash string) (*Transaction, error) {
+	if !wallet.WalletOpened() {
+		return nil, errors.New(ErrWalletNotLoaded)
+	}
+
 	hash, err := chainhash.NewHashFromStr(txHash)
 	if err != nil {
 		log.Error(err)
-		return nil, err
+		return nil, errors.New(ErrInvalid)
+	}
+
+	var tx Transaction
+	err = wallet.walletDataDB.FindOne("Hash", hash.String(), &tx)
+	// Transactions indexed before block hashes were saved are read from
+	// the wallet database instead.
+	if err == nil && (tx.BlockHeight == BlockHeightInvalid || tx.BlockHash != "") {
+		wallet.setReadTimeFields(&tx, wallet.GetBestBlock())
+		return &tx, nil
 	}
 
 	txSummary, _, blockHash, err := wallet.Internal().TransactionSummary(wallet.shutdownContext(), hash)
 	if err != nil {
 		log.Error(err)
+		return nil, translateError(err)
+	}
+
+	decodedTx, err := wallet.decodeTransactionWithTxSummary(txSummary, blockHash)
+	if err != nil {
 		return nil, err
 	}
 
-	return wallet.decodeTransactionWithTxSummary(txSummary, blockHash)
+	wallet.setReadTimeFields(decodedTx, wallet.GetBestBlock())
+	return decodedTx, nil
 }
 
 func (wallet *Wallet) GetTransactions(offset, limit, txFilter int32, newestFirst bool) (string, error) {
@@ -104,8 +199,27 @@ func (wallet *Wallet) GetTransactions(offset, limit, txFilter int32, newestFirst
 }
 
 func (wallet *Wallet) GetTransactionsRaw(offset, limit, txFilter int32, newestFirst bool) (transactions []Transaction, err error) {
-	err = wallet.walletDataDB.Read(offset, limit, txFilter, newestFirst, wallet.RequiredConfirmations(), wallet.GetBestBlock(), &transactions)
-	return
+	return wallet.GetTransactionsRawContext(wallet.shutdownContext(), offset, limit, txFilter, newestFirst)
+}
+
+// GetTransactionsRawContext is like GetTransactionsRaw but stops reading the
+// tx index when ctx is canceled.
+func (wallet *Wallet) GetTransactionsRawContext(ctx context.Context, offset, limit, txFilter int32, newestFirst bool) ([]Transaction, error) {
+	if !wallet.WalletOpened() {
+		return nil, errors.New(ErrWalletNotLoaded)
+	}
+
+	var transactions []Transaction
+	bestBlock := wallet.GetBestBlock()
+	err := wallet.walletDataDB.ReadContext(ctx, offset, limit, txFilter, newestFirst, wallet.RequiredConfirmations(), bestBlock, &transactions)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	for i := range transactions {
+		wallet.setReadTimeFields(&transactions[i], bestBlock)
+	}
+	return transactions, nil
 }
 
 func (mw *MultiWallet) GetTransactions(offset, limit, txFilter int32, newestFirst bool) (string, error) {
@@ -124,9 +238,28 @@ func (mw *MultiWallet) GetTransactions(offset, limit, txFilter int32, newestFirs
 }
 
 func (mw *MultiWallet) GetTransactionsRaw(offset, limit, txFilter int32, newestFirst bool) ([]Transaction, error) {
+	ctx, cancel := mw.contextWithShutdownCancel()
+	defer cancel()
+	return mw.GetTransactionsRawContext(ctx, offset, limit, txFilter, newestFirst)
+}
+
+// GetTransactionsRawContext is like GetTransactionsRaw but stops reading the
+// tx indexes of the wallets when ctx is canceled.
+func (mw *MultiWallet) GetTransactionsRawContext(ctx context.Context, offset, limit, txFilter int32, newestFirst bool) ([]Transaction, error) {
+	// The requested page can contain transactions from any of the wallets,
+	// so read enough transactions from each wallet to fill it.
+	var walletLimit int32
+	if limit > 0 {
+		walletLimit = offset + limit
+	}
+
 	transactions := make([]Transaction, 0)
 	for _, wallet := range mw.wallets {
-		walletTransactions, err := wallet.GetTransactionsRaw(offset, limit, txFilter, newestFirst)
+		if !wallet.WalletOpened() {
+			continue
+		}
+
+		walletTransactions, err := wallet.GetTransactionsRawContext(ctx, 0, walletLimit, txFilter, newestFirst)
 		if err != nil {
 			return nil, err
 		}
@@ -134,14 +267,18 @@ func (mw *MultiWallet) GetTransactionsRaw(offset, limit, txFilter int32, newestF
 		transactions = append(transactions, walletTransactions...)
 	}
 
-	// sort transaction by timestamp in descending order
-	sort.Slice(transactions[:], func(i, j int) bool {
+	sort.SliceStable(transactions, func(i, j int) bool {
 		if newestFirst {
-			return transactions[i].Timestamp > transactions[j].Timestamp
+			return txIsNewer(&transactions[i], &transactions[j])
 		}
-		return transactions[i].Timestamp < transactions[j].Timestamp
+		return txIsNewer(&transactions[j], &transactions[i])
 	})
 
+	if int(offset) >= len(transactions) {
+		return make([]Transaction, 0), nil
+	}
+	transactions = transactions[offset:]
+
 	if len(transactions) > int(limit) && limit > 0 {
 		transactions = transactions[:limit]
 	}
@@ -149,11 +286,288 @@ func (mw *MultiWallet) GetTransactionsRaw(offset, limit, txFilter int32, newestF
 	return transactions, nil
 }
 
+// GetTransactionsForFilter returns the JSON encoded transactions that match
+// all the criteria in filterJSON, newest first. filterJSON is a JSON object
+// with any of the direction, type, min_height, max_height, min_timestamp and
+// max_timestamp fields.
+func (wallet *Wallet) GetTransactionsForFilter(filterJSON string, offset, limit int32) (string, error) {
+	transactions, err := wallet.GetTransactionsForFilterRaw(filterJSON, offset, limit)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(transactions)
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
+func (wallet *Wallet) GetTransactionsForFilterRaw(filterJSON string, offset, limit int32) ([]Transaction, error) {
+	if !wallet.WalletOpened() {
+		return nil, errors.New(ErrWalletNotLoaded)
+	}
+
+	txQuery := &walletdata.TxQuery{Direction: TxDirectionInvalid}
+	if err := json.Unmarshal([]byte(filterJSON), txQuery); err != nil {
+		return nil, errors.E(errors.Invalid, "invalid filter")
+	}
+
+	transactions := make([]Transaction, 0)
+	err := wallet.walletDataDB.ReadWithQuery(txQuery, offset, limit, true, &transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	bestBlock := wallet.GetBestBlock()
+	for i := range transactions {
+		wallet.setReadTimeFields(&transactions[i], bestBlock)
+	}
+
+	return transactions, nil
+}
+
+// GetTransactionsSinceHeight returns the JSON encoded transactions mined at
+// or after height along with all unmined transactions. Transactions that were
+// moved back to the mempool by a reorg are returned as unmined.
+func (wallet *Wallet) GetTransactionsSinceHeight(height int32) (string, error) {
+	return wallet.getTransactionsSince("BlockHeight", height)
+}
+
+// GetTransactionsSinceTimestamp is like GetTransactionsSinceHeight but uses
+// the transaction timestamp as the cutoff.
+func (wallet *Wallet) GetTransactionsSinceTimestamp(timestamp int64) (string, error) {
+	return wallet.getTransactionsSince("Timestamp", timestamp)
+}
+
+func (wallet *Wallet) getTransactionsSince(fieldName string, value interface{}) (string, error) {
+	if !wallet.WalletOpened() {
+		return "", errors.New(ErrWalletNotLoaded)
+	}
+
+	transactions := make([]Transaction, 0)
+	err := wallet.walletDataDB.ReadSince(fieldName, value, &transactions)
+	if err != nil {
+		return "", err
+	}
+
+	bestBlock := wallet.GetBestBlock()
+	for i := range transactions {
+		wallet.setReadTimeFields(&transactions[i], bestBlock)
+	}
+
+	result, err := json.Marshal(transactions)
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
+// SearchTransactions returns the JSON encoded transactions whose hash starts
+// with query or that pay to or spend from the address in query, newest first.
+// Transactions indexed before the addresses were kept are searched by the
+// addresses found by the database migration, RebuildTxIndex indexes them
+// again with the addresses read from the wallet.
+func (wallet *Wallet) SearchTransactions(query string, offset, limit int32) (string, error) {
+	if !wallet.WalletOpened() {
+		return "", errors.New(ErrWalletNotLoaded)
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", errors.New(ErrInvalid)
+	}
+
+	transactions := make([]Transaction, 0)
+	err := wallet.walletDataDB.Search(query, offset, limit, &transactions)
+	if err != nil {
+		return "", err
+	}
+
+	bestBlock := wallet.GetBestBlock()
+	for i := range transactions {
+		wallet.setReadTimeFields(&transactions[i], bestBlock)
+	}
+
+	result, err := json.Marshal(transactions)
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
+// PendingTransactions returns the JSON encoded unmined transactions of the
+// wallet, most recently seen first. Transactions that can no longer be mined
+// are returned with the expired field set.
+func (wallet *Wallet) PendingTransactions() (string, error) {
+	transactions, err := wallet.PendingTransactionsRaw()
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(transactions)
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
+func (wallet *Wallet) PendingTransactionsRaw() ([]Transaction, error) {
+	if !wallet.WalletOpened() {
+		return nil, errors.New(ErrWalletNotLoaded)
+	}
+
+	transactions := make([]Transaction, 0)
+	err := wallet.walletDataDB.Find(q.Eq("BlockHeight", BlockHeightInvalid), &transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	bestBlock := wallet.GetBestBlock()
+	for i := range transactions {
+		wallet.setReadTimeFields(&transactions[i], bestBlock)
+	}
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].FirstSeen > transactions[j].FirstSeen
+	})
+
+	return transactions, nil
+}
+
+// PendingTransactions is like Wallet.PendingTransactions but returns the
+// unmined transactions of all wallets.
+func (mw *MultiWallet) PendingTransactions() (string, error) {
+	transactions := make([]Transaction, 0)
+	for _, wallet := range mw.wallets {
+		if !wallet.WalletOpened() {
+			continue
+		}
+
+		walletTransactions, err := wallet.PendingTransactionsRaw()
+		if err != nil {
+			return "", err
+		}
+		transactions = append(transactions, walletTransactions...)
+	}
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].FirstSeen > transactions[j].FirstSeen
+	})
+
+	result, err := json.Marshal(transactions)
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
+// SetTransactionNote saves a note for the wallet transaction with the
+// provided hash. An empty note deletes the previously saved note. Notes are
+// saved separately from the indexed transactions so they are kept if the
+// transactions are reindexed.
+func (wallet *Wallet) SetTransactionNote(txHash string, note string) error {
+	if !wallet.WalletOpened() {
+		return errors.New(ErrWalletNotLoaded)
+	}
+
+	if len(note) > MaxTxNoteLength {
+		return errors.New(ErrTxNoteTooLong)
+	}
+
+	hash, err := chainhash.NewHashFromStr(txHash)
+	if err != nil {
+		return errors.New(ErrInvalid)
+	}
+
+	var tx Transaction
+	err = wallet.walletDataDB.FindOne("Hash", hash.String(), &tx)
+	if err != nil {
+		_, _, _, err = wallet.Internal().TransactionSummary(wallet.shutdownContext(), hash)
+		if err != nil {
+			return translateError(err)
+		}
+	}
+
+	return wallet.walletDataDB.SetTxNote(hash.String(), note)
+}
+
+// setReadTimeFields sets the transaction fields that are not saved to
+// the tx index.
+func (wallet *Wallet) setReadTimeFields(tx *Transaction, bestBlock int32) {
+	tx.setConfirmationFields(bestBlock, wallet.RequiredConfirmations(), wallet.chainParams)
+	if tx.FirstSeen == 0 {
+		// Transactions indexed before first seen times were saved.
+		tx.FirstSeen = tx.Timestamp
+	}
+
+	note, err := wallet.walletDataDB.TxNote(tx.Hash)
+	if err != nil {
+		log.Errorf("[%d] Error reading note for tx %s: %v", wallet.ID, tx.Hash, err)
+	}
+	tx.Note = note
+
+	if wallet.addressLabel == nil {
+		return
+	}
+	for _, output := range tx.Outputs {
+		if output.Address != "" {
+			output.Label = wallet.addressLabel(output.Address)
+		}
+	}
+}
+
+// CountTransactions returns the number of transactions in all wallets that
+// match txFilter.
+func (mw *MultiWallet) CountTransactions(txFilter int32) (int, error) {
+	var count int
+	for _, wallet := range mw.wallets {
+		if !wallet.WalletOpened() {
+			continue
+		}
+
+		walletCount, err := wallet.CountTransactions(txFilter)
+		if err != nil {
+			return 0, err
+		}
+		count += walletCount
+	}
+	return count, nil
+}
+
+// txIsNewer orders transactions the same way as the tx index, by block height
+// and then timestamp with unmined transactions being the newest.
+func txIsNewer(tx1, tx2 *Transaction) bool {
+	if tx1.BlockHeight != tx2.BlockHeight {
+		if tx1.BlockHeight == BlockHeightInvalid {
+			return true
+		}
+		if tx2.BlockHeight == BlockHeightInvalid {
+			return false
+		}
+		return tx1.BlockHeight > tx2.BlockHeight
+	}
+	return tx1.Timestamp > tx2.Timestamp
+}
+
 func (wallet *Wallet) CountTransactions(txFilter int32) (int, error) {
+	if !wallet.WalletOpened() {
+		return 0, errors.New(ErrWalletNotLoaded)
+	}
+
 	return wallet.walletDataDB.Count(txFilter, wallet.RequiredConfirmations(), wallet.GetBestBlock(), &Transaction{})
 }
 
 func (wallet *Wallet) TicketHasVotedOrRevoked(ticketHash string) (bool, error) {
+	if !wallet.WalletOpened() {
+		return false, errors.New(ErrWalletNotLoaded)
+	}
+
 	err := wallet.walletDataDB.FindOne("TicketSpentHash", ticketHash, &Transaction{})
 	if err != nil {
 		if err == storm.ErrNotFound {
@@ -166,6 +580,10 @@ func (wallet *Wallet) TicketHasVotedOrRevoked(ticketHash string) (bool, error) {
 }
 
 func (wallet *Wallet) TicketSpender(ticketHash string) (*Transaction, error) {
+	if !wallet.WalletOpened() {
+		return nil, errors.New(ErrWalletNotLoaded)
+	}
+
 	var spender Transaction
 	err := wallet.walletDataDB.FindOne("TicketSpentHash", ticketHash, &spender)
 	if err != nil {
@@ -218,6 +636,46 @@ func (wallet *Wallet) TransactionOverview() (txOverview *TransactionOverview, er
 	return txOverview, nil
 }
 
+// WalletActivitySummary returns the json encoded ActivitySummary of the
+// wallet's transactions. The summary is read from aggregates kept as
+// transactions are indexed, the transactions are not read.
+func (wallet *Wallet) WalletActivitySummary() (string, error) {
+	summary, err := wallet.WalletActivitySummaryRaw()
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(summary)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+func (wallet *Wallet) WalletActivitySummaryRaw() (*ActivitySummary, error) {
+	if !wallet.WalletOpened() {
+		return nil, errors.New(ErrWalletNotLoaded)
+	}
+
+	stats, err := wallet.walletDataDB.TxStats()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	firstTimestamp, lastTimestamp, err := wallet.walletDataDB.TimestampRange(&[]Transaction{})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &ActivitySummary{
+		TransactionCount: stats.Count,
+		CountByType:      stats.CountByType,
+		FirstTimestamp:   firstTimestamp,
+		LastTimestamp:    lastTimestamp,
+		ActiveMonths:     len(stats.CountByMonth),
+	}, nil
+}
+
 func (wallet *Wallet) TxMatchesFilter(tx *Transaction, txFilter int32) bool {
 	bestBlock := wallet.GetBestBlock()
 
@@ -292,6 +750,64 @@ func (wallet *Wallet) TxMatchesFilter2(direction, blockHeight int32, txType, tic
 	return wallet.TxMatchesFilter(&tx, txFilter)
 }
 
+// setConfirmationFields sets the fields of tx that depend on the number of
+// confirmations of tx at bestBlock.
+func (tx *Transaction) setConfirmationFields(bestBlock, requiredConfirmations int32, chainParams *chaincfg.Params) {
+	ticketMaturity := int32(chainParams.TicketMaturity)
+	ticketExpiry := int32(chainParams.TicketExpiry)
+
+	tx.ConfirmationCount = tx.Confirmations(bestBlock)
+	tx.Expired = tx.BlockHeight == BlockHeightInvalid && tx.Expiry != 0 && bestBlock+1 >= tx.Expiry
+
+	tx.BlocksUntilMature = blocksUntilMature(tx.Type, tx.ConfirmationCount, chainParams)
+	tx.Mature = tx.BlocksUntilMature == 0
+
+	tx.BlocksUntilExpiry = 0
+	if tx.Type == TxTypeTicketPurchase && tx.ConfirmationCount > 0 && tx.TicketSpender == "" {
+		// Like dcrwallet, tickets expire once the tip is more than
+		// TicketMaturity + TicketExpiry blocks past the ticket block.
+		if remaining := ticketMaturity + ticketExpiry + 2 - tx.ConfirmationCount; remaining > 0 {
+			tx.BlocksUntilExpiry = remaining
+		}
+	}
+
+	switch {
+	case tx.Expired:
+		tx.Status = TxStatusExpired
+	case tx.Type == TxTypeTicketPurchase:
+		tx.Status = tx.TicketStatus(ticketMaturity, ticketExpiry, bestBlock)
+	case tx.ConfirmationCount == 0:
+		tx.Status = TxStatusUnmined
+	case !tx.Mature:
+		tx.Status = TxStatusImmature
+	case tx.ConfirmationCount < requiredConfirmations:
+		tx.Status = TxStatusPending
+	default:
+		tx.Status = TxStatusConfirmed
+	}
+}
+
+// blocksUntilMature returns the number of blocks until a tx of txType with the
+// provided number of confirmations is mature, or 0 if it is. The rules match
+// dcrwallet's: the outputs of coinbases, votes and revocations mature with
+// more than CoinbaseMaturity confirmations and tickets once the tip is more
+// than TicketMaturity blocks past the ticket block. Other txs mature once
+// mined.
+func blocksUntilMature(txType string, confirmations int32, chainParams *chaincfg.Params) int32 {
+	var maturity int32 = 1
+	switch txType {
+	case TxTypeCoinBase, TxTypeVote, TxTypeRevocation:
+		maturity = int32(chainParams.CoinbaseMaturity) + 1
+	case TxTypeTicketPurchase:
+		maturity = int32(chainParams.TicketMaturity) + 2
+	}
+
+	if confirmations >= maturity {
+		return 0
+	}
+	return maturity - confirmations
+}
+
 func (tx Transaction) Confirmations(bestBlock int32) int32 {
 	if tx.BlockHeight == BlockHeightInvalid {
 		return 0
@@ -305,15 +821,19 @@ func (tx Transaction) TicketStatus(ticketMaturity, ticketExpiry, bestBlock int32
 		return ""
 	}
 
+	// A ticket is live once the tip is more than ticketMaturity blocks past
+	// the ticket block and expires once it is more than ticketMaturity +
+	// ticketExpiry blocks past it, as in dcrwallet. A spent ticket keeps its
+	// voted or revoked status after the expiry height.
 	confirmations := tx.Confirmations(bestBlock)
 	if confirmations == 0 {
 		return TicketStatusUnmined
-	} else if confirmations <= ticketMaturity {
-		return TicketStatusImmature
-	} else if confirmations > (ticketMaturity + ticketExpiry) {
-		return TicketStatusExpired
 	} else if tx.TicketSpender != "" {
 		return TicketStatusVotedOrRevoked
+	} else if confirmations <= ticketMaturity+1 {
+		return TicketStatusImmature
+	} else if confirmations > ticketMaturity+ticketExpiry+1 {
+		return TicketStatusExpired
 	}
 
 	return TicketStatusLive
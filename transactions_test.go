@@ -0,0 +1,104 @@
+package dcrlibwallet
+
+import (
+	"github.com/decred/dcrd/chaincfg/v3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transactions", func() {
+	Describe("setConfirmationFields", func() {
+		params := chaincfg.TestNet3Params()
+		coinbaseMaturity := int32(params.CoinbaseMaturity)
+		ticketMaturity := int32(params.TicketMaturity)
+		ticketExpiry := int32(params.TicketExpiry)
+
+		const txHeight int32 = 1000
+		const requiredConfirmations int32 = 2
+
+		newTx := func(txType string, blockHeight int32) *Transaction {
+			return &Transaction{Type: txType, BlockHeight: blockHeight}
+		}
+
+		It("counts one confirmation when the tip is the tx block", func() {
+			tx := newTx(TxTypeRegular, txHeight)
+			tx.setConfirmationFields(txHeight, requiredConfirmations, params)
+
+			Expect(tx.ConfirmationCount).To(Equal(int32(1)))
+			Expect(tx.Mature).To(BeTrue())
+			Expect(tx.Status).To(Equal(TxStatusPending))
+
+			tx.setConfirmationFields(txHeight+1, requiredConfirmations, params)
+			Expect(tx.ConfirmationCount).To(Equal(int32(2)))
+			Expect(tx.Status).To(Equal(TxStatusConfirmed))
+		})
+
+		It("reports unmined and expired transactions", func() {
+			tx := newTx(TxTypeRegular, BlockHeightInvalid)
+			tx.Expiry = txHeight + 2
+
+			tx.setConfirmationFields(txHeight, requiredConfirmations, params)
+			Expect(tx.ConfirmationCount).To(Equal(int32(0)))
+			Expect(tx.Mature).To(BeFalse())
+			Expect(tx.BlocksUntilMature).To(Equal(int32(1)))
+			Expect(tx.Status).To(Equal(TxStatusUnmined))
+
+			// The tx can't be mined in a block at its expiry height.
+			tx.setConfirmationFields(txHeight+1, requiredConfirmations, params)
+			Expect(tx.Expired).To(BeTrue())
+			Expect(tx.Status).To(Equal(TxStatusExpired))
+		})
+
+		It("matures coinbase and vote outputs with more than CoinbaseMaturity confirmations", func() {
+			for _, txType := range []string{TxTypeCoinBase, TxTypeVote, TxTypeRevocation} {
+				tx := newTx(txType, txHeight)
+
+				tx.setConfirmationFields(txHeight+coinbaseMaturity-1, requiredConfirmations, params)
+				Expect(tx.ConfirmationCount).To(Equal(coinbaseMaturity))
+				Expect(tx.Mature).To(BeFalse())
+				Expect(tx.BlocksUntilMature).To(Equal(int32(1)))
+				Expect(tx.Status).To(Equal(TxStatusImmature))
+
+				tx.setConfirmationFields(txHeight+coinbaseMaturity, requiredConfirmations, params)
+				Expect(tx.ConfirmationCount).To(Equal(coinbaseMaturity + 1))
+				Expect(tx.Mature).To(BeTrue())
+				Expect(tx.BlocksUntilMature).To(Equal(int32(0)))
+				Expect(tx.Status).To(Equal(TxStatusConfirmed))
+			}
+		})
+
+		It("matures tickets after TicketMaturity blocks and counts down to expiry", func() {
+			tx := newTx(TxTypeTicketPurchase, txHeight)
+
+			tx.setConfirmationFields(txHeight+ticketMaturity, requiredConfirmations, params)
+			Expect(tx.Mature).To(BeFalse())
+			Expect(tx.BlocksUntilMature).To(Equal(int32(1)))
+			Expect(tx.Status).To(Equal(TicketStatusImmature))
+
+			tx.setConfirmationFields(txHeight+ticketMaturity+1, requiredConfirmations, params)
+			Expect(tx.Mature).To(BeTrue())
+			Expect(tx.Status).To(Equal(TicketStatusLive))
+			Expect(tx.BlocksUntilExpiry).To(Equal(ticketExpiry))
+
+			tx.setConfirmationFields(txHeight+ticketMaturity+ticketExpiry, requiredConfirmations, params)
+			Expect(tx.BlocksUntilExpiry).To(Equal(int32(1)))
+			Expect(tx.Status).To(Equal(TicketStatusLive))
+
+			tx.setConfirmationFields(txHeight+ticketMaturity+ticketExpiry+1, requiredConfirmations, params)
+			Expect(tx.BlocksUntilExpiry).To(Equal(int32(0)))
+			Expect(tx.Status).To(Equal(TicketStatusExpired))
+		})
+
+		It("reports spent tickets as voted or revoked after the expiry height", func() {
+			tx := newTx(TxTypeTicketPurchase, txHeight)
+			tx.TicketSpender = "spender"
+
+			tx.setConfirmationFields(txHeight+ticketMaturity+1, requiredConfirmations, params)
+			Expect(tx.BlocksUntilExpiry).To(Equal(int32(0)))
+			Expect(tx.Status).To(Equal(TicketStatusVotedOrRevoked))
+
+			tx.setConfirmationFields(txHeight+ticketMaturity+ticketExpiry+1, requiredConfirmations, params)
+			Expect(tx.Status).To(Equal(TicketStatusVotedOrRevoked))
+		})
+	})
+})
@@ -0,0 +1,169 @@
+package dcrlibwallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/decred/dcrd/blockchain/stake/v4"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+const (
+	TreasuryPolicyYes     = "yes"
+	TreasuryPolicyNo      = "no"
+	TreasuryPolicyAbstain = "abstain"
+)
+
+// SetTreasuryPolicy saves the voting policy for treasury spends signed by the
+// hex encoded Politeia key piKey. The policy must be one of "yes", "no" or
+// "abstain". The policy is also set with the VSPs of the wallet's unspent,
+// unexpired tickets, the saved policy is reverted if that fails.
+func (wallet *Wallet) SetTreasuryPolicy(privPass []byte, piKey, policy string) error {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	vote, err := treasuryVote(policy)
+	if err != nil {
+		return err
+	}
+
+	piKeyBytes, err := hex.DecodeString(piKey)
+	if err != nil || len(piKeyBytes) != 33 {
+		return fmt.Errorf("%s: invalid pi key %q", ErrInvalid, piKey)
+	}
+
+	// The wallet will need to be unlocked to sign the API
+	// request(s) for setting this policy with the VSP.
+	relock, err := wallet.unlockTemporarily(privPass)
+	if err != nil {
+		return translateError(err)
+	}
+	defer relock()
+
+	ctx := wallet.shutdownContext()
+	currentVote := wallet.Internal().TreasuryKeyPolicy(piKeyBytes, nil)
+	err = wallet.Internal().SetTreasuryKeyPolicy(ctx, piKeyBytes, vote, nil)
+	if err != nil {
+		return translateError(err)
+	}
+
+	treasuryPolicy := map[string]string{piKey: policy}
+	err = wallet.updateVSPVotingPreferences(ctx, nil, nil, nil, treasuryPolicy)
+	if err != nil {
+		// Updating the policy with the VSPs failed, revert the locally
+		// saved policy.
+		revertError := wallet.Internal().SetTreasuryKeyPolicy(ctx, piKeyBytes, currentVote, nil)
+		if revertError != nil {
+			log.Errorf("unable to revert locally saved treasury policy: %v", revertError)
+		}
+	}
+	return err
+}
+
+// SetTSpendPolicy saves the voting policy for the treasury spend with the
+// provided hash. The policy must be one of "yes", "no" or "abstain". The
+// policy is also set with the VSPs of the wallet's unspent, unexpired tickets,
+// the saved policy is reverted if that fails.
+func (wallet *Wallet) SetTSpendPolicy(privPass []byte, tspendHash, policy string) error {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	vote, err := treasuryVote(policy)
+	if err != nil {
+		return err
+	}
+
+	hash, err := chainhash.NewHashFromStr(tspendHash)
+	if err != nil {
+		return fmt.Errorf("%s: invalid tspend hash %q", ErrInvalid, tspendHash)
+	}
+
+	// The wallet will need to be unlocked to sign the API
+	// request(s) for setting this policy with the VSP.
+	relock, err := wallet.unlockTemporarily(privPass)
+	if err != nil {
+		return translateError(err)
+	}
+	defer relock()
+
+	ctx := wallet.shutdownContext()
+	currentVote := wallet.Internal().TSpendPolicy(hash, nil)
+	err = wallet.Internal().SetTSpendPolicy(ctx, hash, vote, nil)
+	if err != nil {
+		return translateError(err)
+	}
+
+	tspendPolicy := map[string]string{tspendHash: policy}
+	err = wallet.updateVSPVotingPreferences(ctx, nil, nil, tspendPolicy, nil)
+	if err != nil {
+		// Updating the policy with the VSPs failed, revert the locally
+		// saved policy.
+		revertError := wallet.Internal().SetTSpendPolicy(ctx, hash, currentVote, nil)
+		if revertError != nil {
+			log.Errorf("unable to revert locally saved tspend policy: %v", revertError)
+		}
+	}
+	return err
+}
+
+// TreasuryPolicies returns a JSON encoded TreasuryPolicies with the policies
+// saved for Politeia keys and for the treasury spends known to the wallet.
+func (wallet *Wallet) TreasuryPolicies() (string, error) {
+	policies := &TreasuryPolicies{
+		TreasuryKeyPolicies: make([]*TreasuryPolicy, 0),
+		TSpendPolicies:      make([]*TreasuryPolicy, 0),
+	}
+
+	for _, keyPolicy := range wallet.Internal().TreasuryKeyPolicies() {
+		// Policies set for individual tickets are not reported.
+		if keyPolicy.Ticket != nil {
+			continue
+		}
+		policies.TreasuryKeyPolicies = append(policies.TreasuryKeyPolicies, &TreasuryPolicy{
+			Key:    hex.EncodeToString(keyPolicy.PiKey),
+			Policy: treasuryPolicyString(keyPolicy.Policy),
+		})
+	}
+
+	for _, tspend := range wallet.Internal().GetAllTSpends(wallet.shutdownContext()) {
+		tspendHash := tspend.TxHash()
+		policies.TSpendPolicies = append(policies.TSpendPolicies, &TreasuryPolicy{
+			Key:    tspendHash.String(),
+			Policy: treasuryPolicyString(wallet.Internal().TSpendPolicy(&tspendHash, nil)),
+		})
+	}
+
+	result, _ := json.Marshal(policies)
+	return string(result), nil
+}
+
+// treasuryVote returns the treasury vote for policy or an error if policy is
+// not "yes", "no" or "abstain".
+func treasuryVote(policy string) (stake.TreasuryVoteT, error) {
+	switch policy {
+	case TreasuryPolicyYes:
+		return stake.TreasuryVoteYes, nil
+	case TreasuryPolicyNo:
+		return stake.TreasuryVoteNo, nil
+	case TreasuryPolicyAbstain:
+		return stake.TreasuryVoteInvalid, nil
+	}
+	return 0, fmt.Errorf("%s: invalid treasury policy %q", ErrInvalid, policy)
+}
+
+func treasuryPolicyString(vote stake.TreasuryVoteT) string {
+	switch vote {
+	case stake.TreasuryVoteYes:
+		return TreasuryPolicyYes
+	case stake.TreasuryVoteNo:
+		return TreasuryPolicyNo
+	}
+	return TreasuryPolicyAbstain
+}
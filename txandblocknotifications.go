@@ -18,6 +18,18 @@ func (mw *MultiWallet) listenForTransactions(walletID int) {
 				if v == nil {
 					return
 				}
+
+				wallet.clearStakeInfoCache()
+
+				// Transactions from detached blocks are unmined until they
+				// are included in one of the attached blocks below.
+				for _, header := range v.DetachedBlocks {
+					blockHash := header.BlockHash()
+					err := wallet.reindexDetachedBlockTransactions(&blockHash)
+					if err != nil {
+						log.Errorf("[%d] Error reindexing txs from detached block %s: %v", wallet.ID, blockHash, err)
+					}
+				}
 				for _, transaction := range v.UnminedTransactions {
 					tempTransaction, err := wallet.decodeTransactionWithTxSummary(&transaction, nil)
 					if err != nil {
@@ -33,6 +45,10 @@ func (mw *MultiWallet) listenForTransactions(walletID int) {
 
 					if !overwritten {
 						log.Infof("[%d] New Transaction %s", wallet.ID, tempTransaction.Hash)
+						mw.publishTicketNotification(wallet, tempTransaction)
+						if tempTransaction.Type == TxTypeMixed && wallet.IsAccountMixerActive() {
+							mw.publishAccountMixerSessionSucceeded(wallet.ID, tempTransaction.Hash)
+						}
 
 						result, err := json.Marshal(tempTransaction)
 						if err != nil {
@@ -52,11 +68,14 @@ func (mw *MultiWallet) listenForTransactions(walletID int) {
 							return
 						}
 
-						_, err = wallet.walletDataDB.SaveOrUpdate(&Transaction{}, tempTransaction)
+						overwritten, err := wallet.walletDataDB.SaveOrUpdate(&Transaction{}, tempTransaction)
 						if err != nil {
 							log.Errorf("[%d] Incoming block replace tx error :%v", wallet.ID, err)
 							return
 						}
+						if !overwritten {
+							mw.publishTicketNotification(wallet, tempTransaction)
+						}
 						mw.publishTransactionConfirmed(wallet.ID, transaction.Hash.String(), int32(block.Header.Height))
 					}
 
@@ -67,6 +86,12 @@ func (mw *MultiWallet) listenForTransactions(walletID int) {
 					mw.checkWalletMixers()
 				}
 
+				// Balances change too often during sync to be reported,
+				// they are checked once sync completes.
+				if mw.IsSynced() {
+					mw.checkBalanceChanges(wallet)
+				}
+
 			case <-mw.syncData.syncCanceled:
 				n.Done()
 			}
@@ -3,15 +3,16 @@ package dcrlibwallet
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"decred.org/dcrwallet/v2/errors"
 	w "decred.org/dcrwallet/v2/wallet"
 	"decred.org/dcrwallet/v2/wallet/txauthor"
 	"decred.org/dcrwallet/v2/wallet/txrules"
+	"decred.org/dcrwallet/v2/wallet/txsizes"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrd/txscript/v4"
@@ -20,6 +21,16 @@ import (
 	"github.com/planetdecred/dcrlibwallet/txhelper"
 )
 
+const (
+	// MinTxFeeRatePerKb is the lowest fee rate that can be used for a
+	// transaction, lower rates would be rejected by the network.
+	MinTxFeeRatePerKb = int64(txrules.DefaultRelayFeePerKb)
+
+	// MaxTxFeeRatePerKb is the highest fee rate that can be used for a
+	// transaction to prevent accidentally paying an absurd fee.
+	MaxTxFeeRatePerKb = 100 * MinTxFeeRatePerKb
+)
+
 type TxAuthor struct {
 	sourceWallet        *Wallet
 	sourceAccountNumber uint32
@@ -28,6 +39,13 @@ type TxAuthor struct {
 	inputs              []*wire.TxIn
 	changeDestination   *TransactionDestination
 
+	// changeDestinationAccount is the account set to receive the change
+	// of this tx with SetChangeDestination, or -1 to use changeAccount.
+	changeDestinationAccount int32
+
+	requiredConfirmations int32
+	feeRate               dcrutil.Amount
+
 	unsignedTx     *txauthor.AuthoredTx
 	needsConstruct bool
 }
@@ -38,29 +56,199 @@ func (mw *MultiWallet) NewUnsignedTx(walletID int, sourceAccountNumber int32) (*
 		return nil, fmt.Errorf(ErrWalletNotFound)
 	}
 
+	return mw.NewUnsignedTxWithConfirmations(walletID, sourceAccountNumber, sourceWallet.RequiredConfirmations())
+}
+
+// NewUnsignedTxWithConfirmations is like NewUnsignedTx but only outputs with
+// at least requiredConfs confirmations will be selected as inputs.
+func (mw *MultiWallet) NewUnsignedTxWithConfirmations(walletID int, sourceAccountNumber int32, requiredConfs int32) (*TxAuthor, error) {
+	sourceWallet := mw.WalletWithID(walletID)
+	if sourceWallet == nil {
+		return nil, fmt.Errorf(ErrWalletNotFound)
+	}
+
 	_, err := sourceWallet.GetAccount(sourceAccountNumber)
 	if err != nil {
 		return nil, err
 	}
 
 	return &TxAuthor{
-		sourceWallet:        sourceWallet,
-		sourceAccountNumber: uint32(sourceAccountNumber),
-		destinations:        make([]TransactionDestination, 0),
-		needsConstruct:      true,
+		sourceWallet:          sourceWallet,
+		sourceAccountNumber:   uint32(sourceAccountNumber),
+		destinations:          make([]TransactionDestination, 0),
+		requiredConfirmations: requiredConfs,
+		feeRate:               dcrutil.Amount(mw.TransactionFeeRate()),
+		needsConstruct:        true,
+
+		changeDestinationAccount: -1,
 	}, nil
 }
 
+// SetTransactionFeeRate sets the fee rate, in atoms per kB, used for newly
+// constructed transactions.
+func (mw *MultiWallet) SetTransactionFeeRate(atomsPerKb int64) error {
+	if err := validateFeeRate(atomsPerKb); err != nil {
+		return err
+	}
+
+	return mw.db.Set(userConfigBucketName, TransactionFeeRateConfigKey, atomsPerKb)
+}
+
+// TransactionFeeRate returns the fee rate, in atoms per kB, used for newly
+// constructed transactions.
+func (mw *MultiWallet) TransactionFeeRate() int64 {
+	return mw.ReadLongConfigValueForKey(TransactionFeeRateConfigKey, MinTxFeeRatePerKb)
+}
+
+// isDustAmount returns true if an output of amount paying to a script of
+// scriptSize bytes costs more to spend than it is worth at feeRate. It is the
+// dust rule for the outputs created, selected and counted as spendable.
+func isDustAmount(amount int64, scriptSize int, feeRate dcrutil.Amount) bool {
+	return txrules.IsDustAmount(dcrutil.Amount(amount), scriptSize, feeRate)
+}
+
+// feeRate returns the fee rate used for newly constructed transactions.
+func (wallet *Wallet) feeRate() dcrutil.Amount {
+	if wallet.transactionFeeRate == nil {
+		return dcrutil.Amount(MinTxFeeRatePerKb)
+	}
+	return dcrutil.Amount(wallet.transactionFeeRate())
+}
+
+func validateFeeRate(atomsPerKb int64) error {
+	if atomsPerKb < MinTxFeeRatePerKb || atomsPerKb > MaxTxFeeRatePerKb {
+		return errors.New(ErrInvalidFeeRate)
+	}
+	return nil
+}
+
+// SendTransaction constructs, signs and publishes a transaction that sends
+// amountAtoms to destAddress from srcAccount, spending only outputs with at
+// least requiredConfs confirmations. The transaction is added to the wallet's
+// tx index before returning so it shows up as unconfirmed immediately.
+func (mw *MultiWallet) SendTransaction(walletID int, privPass []byte, destAddress string, amountAtoms int64,
+	srcAccount int32, requiredConfs int32) ([]byte, error) {
+
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	tx, err := mw.NewUnsignedTxWithConfirmations(walletID, srcAccount, requiredConfs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.AddSendDestination(destAddress, amountAtoms, false); err != nil {
+		return nil, err
+	}
+
+	return mw.broadcastAndIndex(tx, privPass)
+}
+
+// SendTransactionToMultiple is like SendTransaction but pays every destination
+// in destinationsJSON, a JSON array of TransactionDestination objects, in a
+// single transaction. At most one destination may set SendMax and no address
+// may be repeated; AddSendDestination can be used to pay an address twice.
+func (mw *MultiWallet) SendTransactionToMultiple(walletID int, privPass []byte, destinationsJSON string,
+	srcAccount int32, requiredConfs int32) ([]byte, error) {
+
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	var destinations []TransactionDestination
+	if err := json.Unmarshal([]byte(destinationsJSON), &destinations); err != nil {
+		return nil, errors.E(errors.Invalid, "invalid destinations")
+	}
+	if len(destinations) == 0 {
+		return nil, errors.E(errors.Invalid, "no destinations")
+	}
+
+	addresses := make(map[string]bool, len(destinations))
+	for _, destination := range destinations {
+		if addresses[destination.Address] {
+			return nil, errors.New(ErrDuplicateDestination)
+		}
+		addresses[destination.Address] = true
+	}
+
+	tx, err := mw.NewUnsignedTxWithConfirmations(walletID, srcAccount, requiredConfs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, destination := range destinations {
+		err = tx.AddSendDestination(destination.Address, destination.AtomAmount, destination.SendMax)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return mw.broadcastAndIndex(tx, privPass)
+}
+
+// broadcastAndIndex broadcasts tx and adds it to the source wallet's tx index.
+func (mw *MultiWallet) broadcastAndIndex(tx *TxAuthor, privPass []byte) ([]byte, error) {
+	msgTx, err := mw.broadcastAndIndexTx(tx, privPass)
+	if err != nil {
+		return nil, err
+	}
+
+	txHash := msgTx.TxHash()
+	return txHash[:], nil
+}
+
+// broadcastAndIndexTx is like broadcastAndIndex but returns the published
+// transaction.
+func (mw *MultiWallet) broadcastAndIndexTx(tx *TxAuthor, privPass []byte) (*wire.MsgTx, error) {
+	msgTx, err := tx.broadcast(privPass)
+	if err != nil {
+		return nil, err
+	}
+
+	txHash := msgTx.TxHash()
+	mw.indexSentTransaction(tx.sourceWallet, &txHash)
+	return msgTx, nil
+}
+
+// indexSentTransaction adds a transaction published by the wallet to its tx
+// index. The transaction has been published at this point, failing to index it
+// only delays it showing up until the mempool notification is received.
+func (mw *MultiWallet) indexSentTransaction(wallet *Wallet, hash *chainhash.Hash) {
+	transaction, overwritten, err := wallet.indexTransaction(hash)
+	if err != nil {
+		log.Errorf("[%d] Error indexing sent tx %s: %v", wallet.ID, hash, err)
+		return
+	}
+
+	if !overwritten {
+		result, err := json.Marshal(transaction)
+		if err != nil {
+			log.Error(err)
+		} else {
+			mw.mempoolTransactionNotification(string(result))
+		}
+	}
+}
+
 func (tx *TxAuthor) AddSendDestination(address string, atomAmount int64, sendMax bool) error {
 	_, err := stdaddr.DecodeAddress(address, tx.sourceWallet.chainParams)
 	if err != nil {
-		return translateError(err)
+		return errors.New(ErrInvalidAddress)
 	}
 
 	if err := tx.validateSendAmount(sendMax, atomAmount); err != nil {
 		return err
 	}
 
+	if sendMax && tx.sendMaxDestinationIndex() >= 0 {
+		return errors.New(ErrSendMaxMultipleDestinations)
+	}
+
 	tx.destinations = append(tx.destinations, TransactionDestination{
 		Address:    address,
 		AtomAmount: atomAmount,
@@ -72,14 +260,23 @@ func (tx *TxAuthor) AddSendDestination(address string, atomAmount int64, sendMax
 }
 
 func (tx *TxAuthor) UpdateSendDestination(index int, address string, atomAmount int64, sendMax bool) error {
+	_, err := stdaddr.DecodeAddress(address, tx.sourceWallet.chainParams)
+	if err != nil {
+		return errors.New(ErrInvalidAddress)
+	}
+
 	if err := tx.validateSendAmount(sendMax, atomAmount); err != nil {
 		return err
 	}
 
-	if len(tx.destinations) < index {
+	if index < 0 || index >= len(tx.destinations) {
 		return errors.New(ErrIndexOutOfRange)
 	}
 
+	if sendMaxIndex := tx.sendMaxDestinationIndex(); sendMax && sendMaxIndex >= 0 && sendMaxIndex != index {
+		return errors.New(ErrSendMaxMultipleDestinations)
+	}
+
 	tx.destinations[index] = TransactionDestination{
 		Address:    address,
 		AtomAmount: atomAmount,
@@ -100,16 +297,73 @@ func (tx *TxAuthor) SendDestination(atIndex int) *TransactionDestination {
 	return &tx.destinations[atIndex]
 }
 
-func (tx *TxAuthor) SetChangeDestination(address string) {
+// SetChangeDestination sends the change of this tx to an internal address of
+// account instead of the source account. The mixed account cannot receive
+// change.
+func (tx *TxAuthor) SetChangeDestination(account int32) error {
+	if account < 0 || uint32(account) == ImportedAccountNumber {
+		return fmt.Errorf("%s: account %d cannot receive change", ErrInvalid, account)
+	}
+	if account == tx.sourceWallet.MixedAccountNumber() {
+		return fmt.Errorf("%s: the mixed account cannot receive change", ErrInvalid)
+	}
+
+	if _, err := tx.sourceWallet.AccountName(account); err != nil {
+		return err
+	}
+
+	tx.changeDestination = nil
+	tx.changeDestinationAccount = account
+	tx.changeAddress = ""
+	tx.needsConstruct = true
+	return nil
+}
+
+// SetCustomChangeAddress sends the change of this tx to address, which does
+// not have to belong to the wallet.
+func (tx *TxAuthor) SetCustomChangeAddress(address string) error {
+	_, err := stdaddr.DecodeAddress(address, tx.sourceWallet.chainParams)
+	if err != nil {
+		return errors.New(ErrInvalidAddress)
+	}
+
 	tx.changeDestination = &TransactionDestination{
 		Address: address,
 	}
+	if tx.changeDestinationAccount != -1 {
+		tx.changeDestinationAccount = -1
+		tx.changeAddress = ""
+	}
 	tx.needsConstruct = true
+	return nil
 }
 
+// RemoveChangeDestination restores the default change destination, an
+// internal address of the source account.
 func (tx *TxAuthor) RemoveChangeDestination() {
 	tx.changeDestination = nil
+	if tx.changeDestinationAccount != -1 {
+		tx.changeDestinationAccount = -1
+		tx.changeAddress = ""
+	}
+	tx.needsConstruct = true
+}
+
+// SetFeeRate overrides the fee rate, in atoms per kB, used for this
+// transaction.
+func (tx *TxAuthor) SetFeeRate(atomsPerKb int64) error {
+	if err := validateFeeRate(atomsPerKb); err != nil {
+		return err
+	}
+
+	tx.feeRate = dcrutil.Amount(atomsPerKb)
 	tx.needsConstruct = true
+	return nil
+}
+
+// FeeRate returns the fee rate, in atoms per kB, used for this transaction.
+func (tx *TxAuthor) FeeRate() int64 {
+	return int64(tx.feeRate)
 }
 
 func (tx *TxAuthor) TotalSendAmount() *Amount {
@@ -130,7 +384,7 @@ func (tx *TxAuthor) EstimateFeeAndSize() (*TxFeeAndSize, error) {
 		return nil, translateError(err)
 	}
 
-	feeToSendTx := txrules.FeeForSerializeSize(txrules.DefaultRelayFeePerKb, unsignedTx.EstimatedSignedSerializeSize)
+	feeToSendTx := txrules.FeeForSerializeSize(tx.feeRate, unsignedTx.EstimatedSignedSerializeSize)
 	feeAmount := &Amount{
 		AtomValue: int64(feeToSendTx),
 		DcrValue:  feeToSendTx.ToCoin(),
@@ -152,13 +406,64 @@ func (tx *TxAuthor) EstimateFeeAndSize() (*TxFeeAndSize, error) {
 	}, nil
 }
 
+// EstimateMaxSendAmount estimates the amount that would be received by toAddress
+// if all outputs with at least requiredConfs confirmations in srcAccount are
+// spent to it, along with the fee for doing so. Sending the max amount to
+// toAddress from srcAccount produces a transaction without change that pays
+// exactly the estimated amount.
+func (mw *MultiWallet) EstimateMaxSendAmount(walletID int, srcAccount int32, toAddress string, requiredConfs int32) (*MaxSendEstimate, error) {
+	tx, err := mw.NewUnsignedTxWithConfirmations(walletID, srcAccount, requiredConfs)
+	if err != nil {
+		return nil, err
+	}
+
+	spendable, err := tx.sourceWallet.SpendableForAccountWithConfirmations(srcAccount, requiredConfs)
+	if err != nil {
+		return nil, err
+	}
+	if spendable == 0 {
+		return nil, errors.New(ErrZeroSpendableBalance)
+	}
+
+	if err = tx.AddSendDestination(toAddress, 0, true); err != nil {
+		return nil, err
+	}
+
+	unsignedTx, err := tx.unsignedTransaction()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var totalOutput int64
+	for _, txOut := range unsignedTx.Tx.TxOut {
+		totalOutput += txOut.Value
+	}
+	fee := int64(unsignedTx.TotalInput) - totalOutput
+
+	var amount int64
+	if unsignedTx.ChangeIndex >= 0 {
+		amount = unsignedTx.Tx.TxOut[unsignedTx.ChangeIndex].Value
+	}
+
+	return &MaxSendEstimate{
+		Amount: &Amount{
+			AtomValue: amount,
+			DcrValue:  AmountCoin(amount),
+		},
+		Fee: &Amount{
+			AtomValue: fee,
+			DcrValue:  AmountCoin(fee),
+		},
+	}, nil
+}
+
 func (tx *TxAuthor) EstimateMaxSendAmount() (*Amount, error) {
 	txFeeAndSize, err := tx.EstimateFeeAndSize()
 	if err != nil {
 		return nil, err
 	}
 
-	spendableAccountBalance, err := tx.sourceWallet.SpendableForAccount(int32(tx.sourceAccountNumber))
+	spendableAccountBalance, err := tx.sourceWallet.SpendableForAccountWithConfirmations(int32(tx.sourceAccountNumber), tx.requiredConfirmations)
 	if err != nil {
 		return nil, err
 	}
@@ -171,13 +476,40 @@ func (tx *TxAuthor) EstimateMaxSendAmount() (*Amount, error) {
 	}, nil
 }
 
+// UseInputsJSON is like UseInputs but takes a JSON array of utxo keys.
+func (tx *TxAuthor) UseInputsJSON(utxoKeysJSON string) error {
+	var utxoKeys []string
+	if err := json.Unmarshal([]byte(utxoKeysJSON), &utxoKeys); err != nil {
+		return errors.E(errors.Invalid, "invalid utxo keys")
+	}
+
+	return tx.UseInputs(utxoKeys)
+}
+
+// UseInputs restricts the inputs of this transaction to the utxos identified
+// by utxoKeys, in the "hash:index" format. All the utxos must be spendable
+// outputs of the source account.
 func (tx *TxAuthor) UseInputs(utxoKeys []string) error {
 	// first clear any previously set inputs
 	// so that an outdated set of inputs isn't used if an error occurs from this function
 	tx.inputs = nil
+
+	unspentOutputs, err := tx.sourceWallet.UnspentOutputsWithConfirmations(int32(tx.sourceAccountNumber), tx.requiredConfirmations)
+	if err != nil {
+		return err
+	}
+
+	accountUtxos := make(map[string]*UnspentOutput, len(unspentOutputs))
+	for _, utxo := range unspentOutputs {
+		accountUtxos[utxo.OutputKey] = utxo
+	}
+
 	inputs := make([]*wire.TxIn, 0, len(utxoKeys))
 	for _, utxoKey := range utxoKeys {
 		idx := strings.Index(utxoKey, ":")
+		if idx < 0 {
+			return fmt.Errorf("invalid utxo key '%s'", utxoKey)
+		}
 		hash := utxoKey[:idx]
 		hashIndex := utxoKey[idx+1:]
 		index, err := strconv.Atoi(hashIndex)
@@ -190,16 +522,13 @@ func (tx *TxAuthor) UseInputs(utxoKeys []string) error {
 			return err
 		}
 
-		op := &wire.OutPoint{
-			Hash:  *txHash,
-			Index: uint32(index),
-		}
-		outputInfo, err := tx.sourceWallet.Internal().OutputInfo(tx.sourceWallet.shutdownContext(), op)
-		if err != nil {
+		utxo, ok := accountUtxos[fmt.Sprintf("%s:%d", txHash, index)]
+		if !ok {
 			return fmt.Errorf("no valid utxo found for '%s' in the source account", utxoKey)
 		}
 
-		input := wire.NewTxIn(op, int64(outputInfo.Amount), nil)
+		op := wire.NewOutPoint(txHash, uint32(index), int8(utxo.Tree))
+		input := wire.NewTxIn(op, utxo.Amount, nil)
 		inputs = append(inputs, input)
 	}
 
@@ -209,12 +538,49 @@ func (tx *TxAuthor) UseInputs(utxoKeys []string) error {
 }
 
 func (tx *TxAuthor) Broadcast(privatePassphrase []byte) ([]byte, error) {
+	msgTx, err := tx.broadcast(privatePassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	txHash := msgTx.TxHash()
+	return txHash[:], nil
+}
+
+// BroadcastWithResult is like Broadcast but also reports the fee paid by the
+// published transaction and the fee rate used to construct it.
+func (tx *TxAuthor) BroadcastWithResult(privatePassphrase []byte) (*BroadcastResult, error) {
+	msgTx, err := tx.broadcast(privatePassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	fee := int64(tx.unsignedTx.TotalInput)
+	for _, txOut := range msgTx.TxOut {
+		fee -= txOut.Value
+	}
+
+	return &BroadcastResult{
+		TxHash: msgTx.TxHash().String(),
+		Fee: &Amount{
+			AtomValue: fee,
+			DcrValue:  AmountCoin(fee),
+		},
+		FeeRate: int64(tx.feeRate),
+	}, nil
+}
+
+func (tx *TxAuthor) broadcast(privatePassphrase []byte) (*wire.MsgTx, error) {
 	defer func() {
 		for i := range privatePassphrase {
 			privatePassphrase[i] = 0
 		}
 	}()
 
+	if tx.sourceWallet.IsWatchingOnlyWallet() {
+		return nil, errors.New(ErrWalletIsWatchOnly)
+	}
+
 	n, err := tx.sourceWallet.Internal().NetworkBackend()
 	if err != nil {
 		log.Error(err)
@@ -246,17 +612,14 @@ func (tx *TxAuthor) Broadcast(privatePassphrase []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	lock := make(chan time.Time, 1)
-	defer func() {
-		lock <- time.Time{}
-	}()
-
-	ctx := tx.sourceWallet.shutdownContext()
-	err = tx.sourceWallet.Internal().Unlock(ctx, privatePassphrase, lock)
+	relock, err := tx.sourceWallet.unlockTemporarily(privatePassphrase)
 	if err != nil {
 		log.Error(err)
-		return nil, errors.New(ErrInvalidPassphrase)
+		return nil, err
 	}
+	defer relock()
+
+	ctx := tx.sourceWallet.shutdownContext()
 
 	var additionalPkScripts map[wire.OutPoint][]byte
 
@@ -286,11 +649,11 @@ func (tx *TxAuthor) Broadcast(privatePassphrase []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	txHash, err := tx.sourceWallet.Internal().PublishTransaction(ctx, &msgTx, n)
+	_, err = tx.sourceWallet.Internal().PublishTransaction(ctx, &msgTx, n)
 	if err != nil {
 		return nil, translateError(err)
 	}
-	return txHash[:], nil
+	return &msgTx, nil
 }
 
 func (tx *TxAuthor) unsignedTransaction() (*txauthor.AuthoredTx, error) {
@@ -326,7 +689,7 @@ func (tx *TxAuthor) constructTransaction() (*txauthor.AuthoredTx, error) {
 
 		// check if multiple destinations are set to receive max amount
 		if destination.SendMax && changeSource != nil {
-			return nil, fmt.Errorf("cannot send max amount to multiple recipients")
+			return nil, errors.New(ErrSendMaxMultipleDestinations)
 		}
 
 		if destination.SendMax {
@@ -350,6 +713,19 @@ func (tx *TxAuthor) constructTransaction() (*txauthor.AuthoredTx, error) {
 		}
 	}
 
+	if changeSource != nil && tx.changeDestination != nil {
+		return nil, errors.E(errors.Invalid, "no change is generated when sending max amount,"+
+			" change destinations must not be provided")
+	}
+
+	if changeSource == nil && tx.changeDestination != nil {
+		changeSource, err = txhelper.MakeTxChangeSource(tx.changeDestination.Address, tx.sourceWallet.chainParams)
+		if err != nil {
+			log.Errorf("constructTransaction: error preparing change source: %v", err)
+			return nil, fmt.Errorf("change source error: %v", err)
+		}
+	}
+
 	if changeSource == nil {
 		// dcrwallet should ordinarily handle cases where a nil changeSource
 		// is passed to `wallet.NewUnsignedTransaction` but the changeSource
@@ -364,9 +740,8 @@ func (tx *TxAuthor) constructTransaction() (*txauthor.AuthoredTx, error) {
 		}
 	}
 
-	requiredConfirmations := tx.sourceWallet.RequiredConfirmations()
-	return tx.sourceWallet.Internal().NewUnsignedTransaction(ctx, outputs, txrules.DefaultRelayFeePerKb, tx.sourceAccountNumber,
-		requiredConfirmations, outputSelectionAlgorithm, changeSource, nil)
+	return tx.sourceWallet.Internal().NewUnsignedTransaction(ctx, outputs, tx.feeRate, tx.sourceAccountNumber,
+		tx.requiredConfirmations, outputSelectionAlgorithm, changeSource, nil)
 }
 
 // changeSource derives an internal address from the source wallet and account
@@ -375,17 +750,7 @@ func (tx *TxAuthor) constructTransaction() (*txauthor.AuthoredTx, error) {
 // change source for receiving change from this tx back into the wallet.
 func (tx *TxAuthor) changeSource(ctx context.Context) (txauthor.ChangeSource, error) {
 	if tx.changeAddress == "" {
-		var changeAccount uint32
-
-		// MixedAccountNumber would be -1 if mixer config isn't set.
-		if tx.sourceAccountNumber == uint32(tx.sourceWallet.MixedAccountNumber()) ||
-			tx.sourceWallet.AccountMixerMixChange() {
-			changeAccount = uint32(tx.sourceWallet.UnmixedAccountNumber())
-		} else {
-			changeAccount = tx.sourceAccountNumber
-		}
-
-		address, err := tx.sourceWallet.Internal().NewChangeAddress(ctx, changeAccount)
+		address, err := tx.sourceWallet.Internal().NewChangeAddress(ctx, tx.changeAccount())
 		if err != nil {
 			return nil, fmt.Errorf("change address error: %v", err)
 		}
@@ -401,10 +766,42 @@ func (tx *TxAuthor) changeSource(ctx context.Context) (txauthor.ChangeSource, er
 	return changeSource, nil
 }
 
-// validateSendAmount validate the amount to send to a destination address
+// changeAccount returns the account that receives the change of this tx.
+// Unless an account is set with SetChangeDestination, change from the mixed
+// account, or all change if the mixer is set to mix change, goes to the
+// unmixed account so that it is never mixed with mixed outputs.
+func (tx *TxAuthor) changeAccount() uint32 {
+	if tx.changeDestinationAccount != -1 {
+		return uint32(tx.changeDestinationAccount)
+	}
+
+	// MixedAccountNumber would be -1 if mixer config isn't set.
+	if tx.sourceAccountNumber == uint32(tx.sourceWallet.MixedAccountNumber()) ||
+		tx.sourceWallet.AccountMixerMixChange() {
+		return uint32(tx.sourceWallet.UnmixedAccountNumber())
+	}
+	return tx.sourceAccountNumber
+}
+
+// sendMaxDestinationIndex returns the index of the destination set to receive
+// the max amount or -1 if there is none.
+func (tx *TxAuthor) sendMaxDestinationIndex() int {
+	for i, destination := range tx.destinations {
+		if destination.SendMax {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateSendAmount validate the amount to send to a destination address.
+// Amounts that are dust at the fee rate of the transaction are rejected.
 func (tx *TxAuthor) validateSendAmount(sendMax bool, atomAmount int64) error {
 	if !sendMax && (atomAmount <= 0 || atomAmount > MaxAmountAtom) {
 		return errors.E(errors.Invalid, "invalid amount")
 	}
+	if !sendMax && isDustAmount(atomAmount, txsizes.P2PKHPkScriptSize, tx.feeRate) {
+		return errors.New(ErrOutputIsDust)
+	}
 	return nil
 }
@@ -0,0 +1,122 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"decred.org/dcrwallet/v2/errors"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/planetdecred/dcrlibwallet/txhelper"
+)
+
+var csvExportHeader = []string{"timestamp", "block_height", "type", "direction", "amount", "fee", "hash", "note"}
+
+// ExportTransactionsToCSV writes the transactions that match txFilter to a
+// CSV file at filePath, oldest first, and returns the number of transactions
+// written. The file is written to a temporary file that replaces filePath
+// once complete, so a failed export does not leave a partial file.
+func (wallet *Wallet) ExportTransactionsToCSV(filePath string, txFilter int32) (int32, error) {
+	tempPath := filePath + ".tmp"
+	file, err := os.Create(tempPath)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := wallet.exportTransactionsCSV(file, txFilter)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, filePath)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ExportTransactionsCSV is like ExportTransactionsToCSV but returns the CSV
+// content instead of writing it to a file.
+func (wallet *Wallet) ExportTransactionsCSV(txFilter int32) (string, error) {
+	var buf bytes.Buffer
+	if _, err := wallet.exportTransactionsCSV(&buf, txFilter); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (wallet *Wallet) exportTransactionsCSV(w io.Writer, txFilter int32) (int32, error) {
+	if !wallet.WalletOpened() {
+		return 0, errors.New(ErrWalletNotLoaded)
+	}
+
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(csvExportHeader); err != nil {
+		return 0, err
+	}
+
+	var count int32
+	var transactions []Transaction
+	bestBlock := wallet.GetBestBlock()
+	err := wallet.walletDataDB.ReadInBatches(wallet.shutdownContext(), txFilter, false, wallet.RequiredConfirmations(),
+		bestBlock, &transactions, func() error {
+			for i := range transactions {
+				wallet.setReadTimeFields(&transactions[i], bestBlock)
+				if err := csvWriter.Write(csvExportRecord(&transactions[i])); err != nil {
+					return err
+				}
+			}
+			count += int32(len(transactions))
+
+			// Flush each batch so large histories aren't buffered in memory.
+			csvWriter.Flush()
+			return csvWriter.Error()
+		})
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func csvExportRecord(tx *Transaction) []string {
+	amount := tx.Amount
+	if tx.Type == txhelper.TxTypeVote || tx.Type == txhelper.TxTypeRevocation {
+		// Report the net staking reward rather than the returned stake.
+		amount = tx.VoteReward
+	}
+
+	var direction string
+	switch tx.Direction {
+	case txhelper.TxDirectionSent:
+		direction = "sent"
+	case txhelper.TxDirectionReceived:
+		direction = "received"
+	case txhelper.TxDirectionTransferred:
+		direction = "transferred"
+	}
+
+	return []string{
+		time.Unix(tx.Timestamp, 0).UTC().Format(time.RFC3339),
+		strconv.FormatInt(int64(tx.BlockHeight), 10),
+		tx.Type,
+		direction,
+		formatAmountAtoms(dcrutil.Amount(amount)),
+		formatAmountAtoms(dcrutil.Amount(tx.Fee)),
+		tx.Hash,
+		tx.Note,
+	}
+}
@@ -1,8 +1,6 @@
 package txhelper
 
 import (
-	"math"
-
 	"decred.org/dcrwallet/v2/wallet"
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrd/wire"
@@ -20,24 +18,58 @@ func MsgTxFeeSizeRate(transactionHex string) (msgTx *wire.MsgTx, fee dcrutil.Amo
 	return
 }
 
-func TransactionAmountAndDirection(inputTotal, outputTotal, fee int64) (amount int64, direction int32) {
-	amountDifference := outputTotal - inputTotal
+// TransactionAmountAndDirection classifies a transaction of txType, one of the
+// TxType constants, from the point of view of a wallet. debits and credits map
+// the wallet's accounts to the amounts the transaction spends from and pays to
+// them, fee is the transaction fee.
+//
+// Ticket purchases and mixes move funds between the wallet's own outputs and
+// are transferred, votes, revocations and coinbases return or earn funds and
+// are received. Regular transactions are classified by the net change of the
+// wallet's balance, those that only move funds between the wallet's accounts
+// are transferred.
+func TransactionAmountAndDirection(txType string, debits, credits map[int32]int64, fee int64) (amount int64, direction int32) {
+	var totalDebit, totalCredit int64
+	for _, debit := range debits {
+		totalDebit += debit
+	}
+	for _, credit := range credits {
+		totalCredit += credit
+	}
 
-	if amountDifference < 0 && float64(fee) == math.Abs(float64(amountDifference)) {
-		// transferred internally, the only real amount spent was transaction fee
-		direction = TxDirectionTransferred
-		amount = fee
-	} else if amountDifference > 0 {
-		// received
-		direction = TxDirectionReceived
-		amount = outputTotal
-	} else {
-		// sent
-		direction = TxDirectionSent
-		amount = inputTotal - outputTotal - fee
+	switch txType {
+	case TxTypeTicketPurchase, TxTypeMixed:
+		return totalCredit, TxDirectionTransferred
+	case TxTypeVote, TxTypeRevocation, TxTypeCoinBase:
+		return totalCredit, TxDirectionReceived
 	}
 
-	return
+	if totalDebit == 0 {
+		return totalCredit, TxDirectionReceived
+	}
+
+	net := totalCredit - totalDebit
+	if net > 0 {
+		return net, TxDirectionReceived
+	}
+
+	sent := -net - fee
+	if sent > 0 {
+		return sent, TxDirectionSent
+	}
+
+	// Only the fee left the wallet, report the amount paid to accounts
+	// other than the ones funding the tx, or all credits if the tx only
+	// consolidates the outputs of an account.
+	for account, credit := range credits {
+		if moved := credit - debits[account]; moved > 0 {
+			amount += moved
+		}
+	}
+	if amount == 0 {
+		amount = totalCredit
+	}
+	return amount, TxDirectionTransferred
 }
 
 func FormatTransactionType(txType wallet.TransactionType) string {
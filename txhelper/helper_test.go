@@ -0,0 +1,33 @@
+package txhelper_test
+
+import (
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/planetdecred/dcrlibwallet/txhelper"
+)
+
+var _ = DescribeTable("TransactionAmountAndDirection",
+	func(txType string, debits, credits map[int32]int64, fee, amount int64, direction int32) {
+		gotAmount, gotDirection := TransactionAmountAndDirection(txType, debits, credits, fee)
+		Expect(gotAmount).To(Equal(amount))
+		Expect(gotDirection).To(Equal(direction))
+	},
+	Entry("send with change", TxTypeRegular,
+		map[int32]int64{0: 10000}, map[int32]int64{0: 3000}, int64(200), int64(6800), TxDirectionSent),
+	Entry("send without change", TxTypeRegular,
+		map[int32]int64{0: 10000}, nil, int64(200), int64(9800), TxDirectionSent),
+	Entry("receive", TxTypeRegular,
+		nil, map[int32]int64{0: 5000}, int64(200), int64(5000), TxDirectionReceived),
+	Entry("receive to a tx the wallet also funds", TxTypeRegular,
+		map[int32]int64{0: 1000}, map[int32]int64{0: 3000}, int64(200), int64(2000), TxDirectionReceived),
+	Entry("transfer to another account", TxTypeRegular,
+		map[int32]int64{0: 10000}, map[int32]int64{0: 2000, 1: 7800}, int64(200), int64(7800), TxDirectionTransferred),
+	Entry("transfer within an account", TxTypeRegular,
+		map[int32]int64{0: 10000}, map[int32]int64{0: 9800}, int64(200), int64(9800), TxDirectionTransferred),
+	Entry("mixed", TxTypeMixed,
+		map[int32]int64{1: 10000}, map[int32]int64{0: 4000, 1: 5800}, int64(200), int64(9800), TxDirectionTransferred),
+	Entry("ticket purchase", TxTypeTicketPurchase,
+		map[int32]int64{0: 10000}, map[int32]int64{0: 9800}, int64(200), int64(9800), TxDirectionTransferred),
+	Entry("vote", TxTypeVote,
+		nil, map[int32]int64{0: 10100}, int64(0), int64(10100), TxDirectionReceived),
+)
@@ -0,0 +1,13 @@
+package txhelper_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestTxhelper(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Txhelper Suite")
+}
@@ -1,16 +1,104 @@
 package dcrlibwallet
 
 import (
+	"decred.org/dcrwallet/v2/errors"
 	w "decred.org/dcrwallet/v2/wallet"
+	"github.com/asdine/storm"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/planetdecred/dcrlibwallet/walletdata"
 )
 
+// txIndexBatchSize is the number of transactions saved to the tx index in a
+// single database transaction when indexing transactions.
+const txIndexBatchSize = 500
+
+// indexTransaction saves the wallet transaction with the given hash to the tx
+// index. overwritten is true if the transaction had previously been indexed.
+func (wallet *Wallet) indexTransaction(txHash *chainhash.Hash) (tx *Transaction, overwritten bool, err error) {
+	txSummary, _, blockHash, err := wallet.Internal().TransactionSummary(wallet.shutdownContext(), txHash)
+	if err != nil {
+		return nil, false, translateError(err)
+	}
+
+	tx, err = wallet.decodeTransactionWithTxSummary(txSummary, blockHash)
+	if err != nil {
+		return nil, false, err
+	}
+
+	overwritten, err = wallet.walletDataDB.SaveOrUpdate(&Transaction{}, tx)
+	return tx, overwritten, err
+}
+
+// reindexDetachedBlockTransactions updates the indexed transactions that were
+// mined in the block with the provided hash, after the block was detached.
+// Transactions that the wallet no longer has, such as transactions that
+// conflict with ones mined in the new main chain, are deleted from the index.
+func (wallet *Wallet) reindexDetachedBlockTransactions(blockHash *chainhash.Hash) error {
+	var transactions []Transaction
+	err := wallet.walletDataDB.FindAll("BlockHash", blockHash.String(), &transactions)
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	for _, tx := range transactions {
+		txHash, err := chainhash.NewHashFromStr(tx.Hash)
+		if err != nil {
+			return err
+		}
+
+		_, _, err = wallet.indexTransaction(txHash)
+		if err != nil && err.Error() == ErrNotExist {
+			log.Debugf("[%d] Removing detached tx %s from the tx index", wallet.ID, tx.Hash)
+			err = wallet.walletDataDB.DeleteTx(&Transaction{}, tx.Hash)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (wallet *Wallet) IndexTransactions() error {
+	return wallet.indexTransactions(nil)
+}
+
+// RebuildTxIndex clears the tx index and indexes all wallet transactions
+// again. progressListener, if not nil, is notified after the transactions
+// in each block are indexed.
+func (wallet *Wallet) RebuildTxIndex(progressListener TxIndexProgressListener) error {
+	if !wallet.WalletOpened() {
+		return errors.New(ErrWalletNotLoaded)
+	}
+
+	err := wallet.walletDataDB.ClearSavedTransactions(&Transaction{})
+	if err != nil {
+		return err
+	}
+
+	return wallet.indexTransactions(progressListener)
+}
+
+func (wallet *Wallet) indexTransactions(progressListener TxIndexProgressListener) error {
 	ctx := wallet.shutdownContext()
 
 	var totalIndex int32
 	var txEndHeight uint32
+	var endHeight int32 // set below, before rangeFn is called
+
+	// Transactions are saved in batches since saving each transaction in
+	// a separate database transaction is slow for wallets with many txs.
+	batch := make([]interface{}, 0, txIndexBatchSize)
+	saveBatch := func() error {
+		err := wallet.walletDataDB.SaveOrUpdateBatch(batch)
+		if err != nil {
+			log.Errorf("[%d] Index tx replace tx err : %v", wallet.ID, err)
+			return err
+		}
+		batch = batch[:0]
+		return nil
+	}
+
 	rangeFn := func(block *w.Block) (bool, error) {
 		for _, transaction := range block.Transactions {
 
@@ -27,16 +115,17 @@ func (wallet *Wallet) IndexTransactions() error {
 				return false, err
 			}
 
-			_, err = wallet.walletDataDB.SaveOrUpdate(&Transaction{}, tx)
-			if err != nil {
-				log.Errorf("[%d] Index tx replace tx err : %v", wallet.ID, err)
-				return false, err
-			}
-
+			batch = append(batch, tx)
 			totalIndex++
 		}
 
-		if block.Header != nil {
+		// The last index point is only saved when the batch is saved so
+		// that indexing resumes from the right height if interrupted.
+		if block.Header != nil && len(batch) >= txIndexBatchSize {
+			if err := saveBatch(); err != nil {
+				return false, err
+			}
+
 			txEndHeight = block.Header.Height
 			err := wallet.walletDataDB.SaveLastIndexPoint(int32(txEndHeight))
 			if err != nil {
@@ -47,6 +136,10 @@ func (wallet *Wallet) IndexTransactions() error {
 			log.Debugf("[%d] Index saved for transactions in block %d", wallet.ID, txEndHeight)
 		}
 
+		if block.Header != nil && progressListener != nil {
+			progressListener.OnTxIndexProgress(wallet.ID, totalIndex, int32(block.Header.Height), endHeight)
+		}
+
 		select {
 		case <-ctx.Done():
 			return true, ctx.Err()
@@ -61,34 +154,94 @@ func (wallet *Wallet) IndexTransactions() error {
 		return err
 	}
 
-	endHeight := wallet.GetBestBlock()
+	endHeight = wallet.GetBestBlock()
 
 	startBlock := w.NewBlockIdentifierFromHeight(beginHeight)
 	endBlock := w.NewBlockIdentifierFromHeight(endHeight)
 
-	defer func() {
-		count, err := wallet.walletDataDB.Count(walletdata.TxFilterAll, wallet.RequiredConfirmations(), endHeight, &Transaction{})
-		if err != nil {
-			log.Errorf("[%d] Post-indexing tx count error :%v", wallet.ID, err)
-		} else if count > 0 {
-			log.Infof("[%d] Transaction index finished at %d, %d transaction(s) indexed in total", wallet.ID, endHeight, count)
+	log.Infof("[%d] Indexing transactions start height: %d, end height: %d", wallet.ID, beginHeight, endHeight)
+	err = wallet.Internal().GetTransactions(ctx, rangeFn, startBlock, endBlock)
+	if err != nil {
+		return err
+	}
+
+	if err = saveBatch(); err != nil {
+		return err
+	}
+
+	count, err := wallet.walletDataDB.Count(walletdata.TxFilterAll, wallet.RequiredConfirmations(), endHeight, &Transaction{})
+	if err != nil {
+		log.Errorf("[%d] Post-indexing tx count error :%v", wallet.ID, err)
+	} else if count > 0 {
+		log.Infof("[%d] Transaction index finished at %d, %d transaction(s) indexed in total", wallet.ID, endHeight, count)
+	}
+
+	err = wallet.walletDataDB.SaveLastIndexPoint(endHeight)
+	if err != nil {
+		log.Errorf("[%d] Set tx index end block height error: ", wallet.ID, err)
+	}
+	return nil
+}
+
+// indexAccountTransactions saves the transactions mined from startHeight, and
+// the unmined transactions, that pay to or spend from account to the tx index.
+// The last index point is not changed.
+func (wallet *Wallet) indexAccountTransactions(account uint32, startHeight int32) error {
+	batch := make([]interface{}, 0, txIndexBatchSize)
+	rangeFn := func(block *w.Block) (bool, error) {
+		var blockHash *chainhash.Hash
+		if block.Header != nil {
+			hash := block.Header.BlockHash()
+			blockHash = &hash
 		}
 
-		err = wallet.walletDataDB.SaveLastIndexPoint(endHeight)
-		if err != nil {
-			log.Errorf("[%d] Set tx index end block height error: ", wallet.ID, err)
+		for i := range block.Transactions {
+			txSummary := &block.Transactions[i]
+			if !txSummaryHasAccount(txSummary, account) {
+				continue
+			}
+
+			tx, err := wallet.decodeTransactionWithTxSummary(txSummary, blockHash)
+			if err != nil {
+				return false, err
+			}
+			batch = append(batch, tx)
 		}
-	}()
 
-	log.Infof("[%d] Indexing transactions start height: %d, end height: %d", wallet.ID, beginHeight, endHeight)
-	return wallet.Internal().GetTransactions(ctx, rangeFn, startBlock, endBlock)
-}
+		if len(batch) >= txIndexBatchSize {
+			if err := wallet.walletDataDB.SaveOrUpdateBatch(batch); err != nil {
+				return false, err
+			}
+			batch = batch[:0]
+		}
+		return false, nil
+	}
 
-func (wallet *Wallet) reindexTransactions() error {
-	err := wallet.walletDataDB.ClearSavedTransactions(&Transaction{})
+	startBlock := w.NewBlockIdentifierFromHeight(startHeight)
+	err := wallet.Internal().GetTransactions(wallet.shutdownContext(), rangeFn, startBlock, nil)
 	if err != nil {
 		return err
 	}
 
-	return wallet.IndexTransactions()
+	return wallet.walletDataDB.SaveOrUpdateBatch(batch)
+}
+
+// txSummaryHasAccount returns true if an input or output of txSummary belongs
+// to account.
+func txSummaryHasAccount(txSummary *w.TransactionSummary, account uint32) bool {
+	for _, input := range txSummary.MyInputs {
+		if input.PreviousAccount == account {
+			return true
+		}
+	}
+	for _, output := range txSummary.MyOutputs {
+		if output.Account == account {
+			return true
+		}
+	}
+	return false
+}
+
+func (wallet *Wallet) reindexTransactions() error {
+	return wallet.RebuildTxIndex(nil)
 }
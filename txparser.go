@@ -5,6 +5,8 @@ import (
 
 	w "decred.org/dcrwallet/v2/wallet"
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript/v4/stdscript"
+	"github.com/decred/dcrd/wire"
 )
 
 const BlockHeightInvalid int32 = -1
@@ -75,6 +77,12 @@ func (wallet *Wallet) decodeTransactionWithTxSummary(txSummary *w.TransactionSum
 		return nil, err
 	}
 
+	if blockHash != nil {
+		decodedTx.BlockHash = blockHash.String()
+	}
+
+	decodedTx.Addresses = wallet.txAddresses(decodedTx)
+
 	if decodedTx.TicketSpentHash != "" {
 		ticketPurchaseTx, err := wallet.GetTransactionRaw(decodedTx.TicketSpentHash)
 		if err != nil {
@@ -84,24 +92,23 @@ func (wallet *Wallet) decodeTransactionWithTxSummary(txSummary *w.TransactionSum
 		timeDifferenceInSeconds := decodedTx.Timestamp - ticketPurchaseTx.Timestamp
 		decodedTx.DaysToVoteOrRevoke = int32(timeDifferenceInSeconds / 86400) // seconds to days conversion
 
-		// calculate reward
-		var ticketInvestment int64
-		for _, input := range ticketPurchaseTx.Inputs {
-			if input.AccountNumber > -1 {
-				ticketInvestment += input.Amount
+		// The outputs of a vote pay the ticket price back along with the
+		// vote subsidy, which is the reward. Only the outputs paying to the
+		// wallet are counted, a shared ticket also pays other parties.
+		// Revocations only return the ticket price, which is reported as
+		// their amount.
+		if decodedTx.Type == TxTypeVote && len(ticketPurchaseTx.Outputs) > 0 {
+			var voteOutput int64
+			for _, output := range walletTx.Outputs {
+				if output.AccountNumber > -1 {
+					voteOutput += output.AmountOut
+				}
 			}
-		}
 
-		var ticketOutput int64
-		for _, output := range walletTx.Outputs {
-			if output.AccountNumber > -1 {
-				ticketOutput += output.AmountOut
-			}
+			ticketPrice := ticketPurchaseTx.Outputs[0].Amount
+			decodedTx.VoteReward = voteOutput - ticketPrice
 		}
 
-		reward := ticketOutput - ticketInvestment
-		decodedTx.VoteReward = reward
-
 		// update ticket with spender hash
 		ticketPurchaseTx.TicketSpender = decodedTx.Hash
 		wallet.walletDataDB.SaveOrUpdate(&Transaction{}, ticketPurchaseTx)
@@ -109,3 +116,45 @@ func (wallet *Wallet) decodeTransactionWithTxSummary(txSummary *w.TransactionSum
 
 	return decodedTx, nil
 }
+
+// txAddresses returns the addresses paid to by the outputs of tx and the
+// addresses of the wallet outputs spent by its inputs, without duplicates.
+func (wallet *Wallet) txAddresses(tx *Transaction) []string {
+	addresses := make([]string, 0, len(tx.Outputs))
+	seen := make(map[string]bool)
+	addAddress := func(address string) {
+		if address != "" && !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	for _, output := range tx.Outputs {
+		addAddress(output.Address)
+	}
+
+	for _, input := range tx.Inputs {
+		if input.AccountNumber == -1 {
+			continue
+		}
+
+		prevHash, err := chainhash.NewHashFromStr(input.PreviousTransactionHash)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		outpoint := wire.NewOutPoint(prevHash, uint32(input.PreviousTransactionIndex), wire.TxTreeUnknown)
+		prevOutput, err := wallet.Internal().FetchOutput(wallet.shutdownContext(), outpoint)
+		if err != nil {
+			log.Errorf("error reading output spent by %s: %v", tx.Hash, err)
+			continue
+		}
+
+		_, addrs := stdscript.ExtractAddrs(prevOutput.Version, prevOutput.PkScript, wallet.chainParams)
+		for _, addr := range addrs {
+			addAddress(addr.String())
+		}
+	}
+
+	return addresses
+}
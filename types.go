@@ -51,12 +51,29 @@ type Amount struct {
 	DcrValue  float64
 }
 
+type PaymentURI struct {
+	Address     string `json:"address"`
+	AmountAtoms int64  `json:"amount_atoms"`
+	Label       string `json:"label"`
+}
+
 type TxFeeAndSize struct {
 	Fee                 *Amount
 	Change              *Amount
 	EstimatedSignedSize int
 }
 
+type BroadcastResult struct {
+	TxHash  string
+	Fee     *Amount
+	FeeRate int64
+}
+
+type MaxSendEstimate struct {
+	Amount *Amount
+	Fee    *Amount
+}
+
 type UnsignedTransaction struct {
 	UnsignedTransaction       []byte
 	EstimatedSignedSize       int
@@ -106,14 +123,31 @@ type PeerInfo struct {
 	Version        uint32 `json:"version"`
 	SubVer         string `json:"sub_ver"`
 	StartingHeight int64  `json:"starting_height"`
+	LastBlock      int64  `json:"last_block"`
 	BanScore       int32  `json:"ban_score"`
 }
 
+type WalletLockStateListener interface {
+	OnWalletLockStateChanged(walletID int, locked bool)
+}
+
 type AccountMixerNotificationListener interface {
 	OnAccountMixerStarted(walletID int)
 	OnAccountMixerEnded(walletID int)
 }
 
+// AccountMixerSessionListener is optionally implemented by the listeners
+// added with AddAccountMixerNotificationListener to be notified of the
+// results of the mixer's sessions.
+type AccountMixerSessionListener interface {
+	// OnAccountMixerSessionSucceeded is called when a mix transaction
+	// created by the wallet is received while the mixer is running.
+	OnAccountMixerSessionSucceeded(walletID int, txHash string)
+	// OnAccountMixerFailed is called before OnAccountMixerEnded if the mixer
+	// stopped because of an error.
+	OnAccountMixerFailed(walletID int, err string)
+}
+
 /** begin sync-related types */
 
 type SyncProgressListener interface {
@@ -123,12 +157,45 @@ type SyncProgressListener interface {
 	OnHeadersFetchProgress(headersFetchProgress *HeadersFetchProgressReport)
 	OnAddressDiscoveryProgress(addressDiscoveryProgress *AddressDiscoveryProgressReport)
 	OnHeadersRescanProgress(headersRescanProgress *HeadersRescanProgressReport)
-	OnSyncCompleted()
+	OnSyncCompleted(summary *SyncSummary)
 	OnSyncCanceled(willRestart bool)
-	OnSyncEndedWithError(err error)
+	OnSyncEndedWithError(err *SyncError)
 	Debug(debugInfo *DebugInfo)
 }
 
+// SyncError is reported to sync progress listeners when the sync ends because
+// of an error. Code is the error code of the error, as returned by ErrorCode,
+// and Kind describes the kind of the dcrwallet error that ended the sync, it
+// is empty if the error has no kind.
+type SyncError struct {
+	Code    int    `json:"code"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+
+	err error
+}
+
+func (e *SyncError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the error that ended the sync.
+func (e *SyncError) Unwrap() error {
+	return e.err
+}
+
+// SyncSummary holds statistics accumulated during a sync, reported to sync
+// progress listeners when the sync completes.
+type SyncSummary struct {
+	DurationSeconds int64 `json:"durationSeconds"`
+	HeadersFetched  int32 `json:"headersFetched"`
+	BlocksRescanned int32 `json:"blocksRescanned"`
+	PeersUsed       int32 `json:"peersUsed"`
+	// AddressDiscoveryFoundActivity is true if address discovery found used
+	// addresses or accounts that were not previously known to the wallets.
+	AddressDiscoveryFoundActivity bool `json:"addressDiscoveryFoundActivity"`
+}
+
 type GeneralSyncProgress struct {
 	TotalSyncProgress         int32 `json:"totalSyncProgress"`
 	TotalTimeRemainingSeconds int64 `json:"totalTimeRemainingSeconds"`
@@ -155,6 +222,7 @@ type HeadersFetchProgressReport struct {
 	CurrentHeaderHeight      int32 `json:"currentHeaderHeight"`
 	CurrentHeaderTimestamp   int64 `json:"currentHeaderTimestamp"`
 	HeadersFetchProgress     int32 `json:"headersFetchProgress"`
+	BestBlockOnNetwork       int32 `json:"bestBlockOnNetwork"`
 }
 
 type AddressDiscoveryProgressReport struct {
@@ -218,6 +286,33 @@ func (asyncTxBlockListener *asyncTxAndBlockNotificationListener) OnTransactionCo
 	go asyncTxBlockListener.l.OnTransactionConfirmed(walletID, hash, blockHeight)
 }
 
+// TicketNotificationListener is notified of changes to the status of the
+// tickets of the wallets.
+type TicketNotificationListener interface {
+	OnTicketPurchased(walletID int, hash string)
+	OnTicketVoted(walletID int, ticketHash, voteHash string, reward int64)
+	OnTicketMissedOrExpired(walletID int, hash string, missed bool)
+	OnTicketPurchaseFailed(walletID int, err string)
+}
+
+// BalanceNotificationListener is notified when the balance of an account
+// changes, including when immature outputs become spendable.
+type BalanceNotificationListener interface {
+	OnBalanceChanged(walletID int, accountNumber int32, balance *Balance)
+}
+
+type SyncDataListener interface {
+	OnSyncDataUpdated(usage *SyncDataUsage)
+}
+
+type ExchangeRateListener interface {
+	OnExchangeRateUpdated(rate *ExchangeRate)
+}
+
+type TxIndexProgressListener interface {
+	OnTxIndexProgress(walletID int, indexedCount int32, currentHeight int32, endHeight int32)
+}
+
 type BlocksRescanProgressListener interface {
 	OnBlocksRescanStarted(walletID int)
 	OnBlocksRescanProgress(*HeadersRescanProgressReport)
@@ -233,8 +328,37 @@ type Transaction struct {
 	Hex           string `json:"hex"`
 	Timestamp     int64  `storm:"index" json:"timestamp"`
 	BlockHeight   int32  `storm:"index" json:"block_height"`
+	BlockHash     string `storm:"index" json:"block_hash"`
 	TicketSpender string `storm:"index" json:"ticket_spender"`
 
+	// FirstSeen is the time the transaction was first indexed, it is kept
+	// when the transaction is indexed again after being mined and when the
+	// tx index is rebuilt.
+	FirstSeen int64 `json:"first_seen"`
+
+	// ConfirmationCount is computed against the best block when the
+	// transaction is read, the saved value is not kept up to date.
+	ConfirmationCount int32 `json:"confirmations"`
+
+	// Note is read from the tx notes bucket, it is not saved with the
+	// indexed transaction.
+	Note string `json:"note"`
+
+	// Expired is true for unmined transactions that can no longer be mined
+	// because the best block is past their expiry height.
+	Expired bool `json:"expired"`
+
+	// Status, Mature, BlocksUntilMature and BlocksUntilExpiry are also
+	// computed against the best block when the transaction is read.
+	// Outputs of coinbases, votes and revocations are mature with more than
+	// CoinbaseMaturity confirmations, tickets once the best block is more
+	// than TicketMaturity blocks past the ticket block.
+	// BlocksUntilExpiry is only set for unspent tickets.
+	Status            string `json:"status"`
+	Mature            bool   `json:"mature"`
+	BlocksUntilMature int32  `json:"blocks_until_mature"`
+	BlocksUntilExpiry int32  `json:"blocks_until_expiry"`
+
 	MixDenomination int64 `json:"mix_denom"`
 	MixCount        int32 `json:"mix_count"`
 
@@ -250,6 +374,10 @@ type Transaction struct {
 	Inputs    []*TxInput  `json:"inputs"`
 	Outputs   []*TxOutput `json:"outputs"`
 
+	// Addresses are the addresses paid to by the outputs of the transaction
+	// and spent from by its wallet inputs, transactions are searched by them.
+	Addresses []string `json:"addresses"`
+
 	// Vote Info
 	VoteVersion        int32  `json:"vote_version"`
 	LastBlockValid     bool   `json:"last_block_valid"`
@@ -275,6 +403,10 @@ type TxOutput struct {
 	Address       string `json:"address"`
 	Internal      bool   `json:"internal"`
 	AccountNumber int32  `json:"account_number"`
+
+	// Label is read from the address book, it is not saved with the
+	// indexed transaction.
+	Label string `json:"label"`
 }
 
 // TxInfoFromWallet contains tx data that relates to the querying wallet.
@@ -323,6 +455,19 @@ type TransactionOverview struct {
 	Coinbase    int
 }
 
+// ActivitySummary summarizes the indexed transactions of a wallet.
+// CountByType maps transaction types to the number of transactions of each
+// type. The timestamps are 0 if the wallet has no transactions.
+type ActivitySummary struct {
+	TransactionCount int            `json:"transaction_count"`
+	CountByType      map[string]int `json:"count_by_type"`
+	FirstTimestamp   int64          `json:"first_timestamp"`
+	LastTimestamp    int64          `json:"last_timestamp"`
+	// ActiveMonths is the number of distinct months, in UTC, with
+	// transactions.
+	ActiveMonths int `json:"active_months"`
+}
+
 /** end tx-related types */
 
 /** begin ticket-related types */
@@ -330,6 +475,54 @@ type TransactionOverview struct {
 type TicketPriceResponse struct {
 	TicketPrice int64
 	Height      int32
+
+	// Stale is true if the price was computed while the wallet is not
+	// connected to the network and may be outdated.
+	Stale bool
+
+	// ActivationHeight is the height at which the next ticket price takes
+	// effect and SecondsToNextPrice the estimated time until then.
+	ActivationHeight   int32
+	SecondsToNextPrice int64
+}
+
+// StakeInfo summarizes the wallet's tickets as of the block at Height.
+// Network-wide fields that are not available to the wallet are set to -1.
+type StakeInfo struct {
+	Height        int32 `json:"height"`
+	Immature      int32 `json:"immature"`
+	Live          int32 `json:"live"`
+	Voted         int32 `json:"voted"`
+	Missed        int32 `json:"missed"`
+	Expired       int32 `json:"expired"`
+	Revoked       int32 `json:"revoked"`
+	Unmined       int32 `json:"unmined"`
+	TotalRewards  int64 `json:"total_rewards"`
+	PoolSize      int32 `json:"pool_size"`
+	AllMempoolTix int32 `json:"all_mempool_tix"`
+}
+
+// TicketPurchaseEstimate is the estimated cost of a ticket purchase. The
+// total cost includes the ticket price and the fees of all tickets.
+type TicketPurchaseEstimate struct {
+	TicketPrice     int64 `json:"ticket_price"`
+	FeePerTicket    int64 `json:"fee_per_ticket"`
+	VSPFeePerTicket int64 `json:"vsp_fee_per_ticket"`
+	TotalCost       int64 `json:"total_cost"`
+	Affordable      bool  `json:"affordable"`
+}
+
+// Ticket describes a ticket purchased by the wallet and, for voted or
+// revoked tickets, the transaction that spent it.
+type Ticket struct {
+	Hash              string `json:"hash"`
+	PurchaseHeight    int32  `json:"purchase_height"`
+	PurchaseTimestamp int64  `json:"purchase_timestamp"`
+	Price             int64  `json:"price"`
+	Status            string `json:"status"`
+	SpenderHash       string `json:"spender_hash"`
+	Reward            int64  `json:"reward"`
+	VSPHost           string `json:"vsp_host"`
 }
 
 type StakingOverview struct {
@@ -345,9 +538,9 @@ type StakingOverview struct {
 // TicketBuyerConfig defines configuration parameters for running
 // an automated ticket buyer.
 type TicketBuyerConfig struct {
-	VspHost           string
-	PurchaseAccount   int32
-	BalanceToMaintain int64
+	VspHost           string `json:"vsp_host"`
+	PurchaseAccount   int32  `json:"purchase_account"`
+	BalanceToMaintain int64  `json:"balance_to_maintain"`
 
 	vspClient *vsp.Client
 }
@@ -451,6 +644,13 @@ type ProposalVote struct {
 	Bit    string
 }
 
+// ProposalVoteResult is the result of casting a ticket's vote on a proposal.
+type ProposalVoteResult struct {
+	Ticket  string `json:"ticket"`
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
 type ProposalNotificationListener interface {
 	OnProposalsSynced()
 	OnNewProposal(proposal *Proposal)
@@ -494,8 +694,41 @@ type VSP struct {
 	*VspInfoResponse
 }
 
+// VSPListEntry describes a VSP from the public VSP directory. Reachable is
+// false if the VSP did not respond to the last vspinfo request, made at
+// LastChecked. VotingReliability is the fraction of the VSP's tickets that
+// voted rather than being revoked, -1 if none have been spent yet.
+type VSPListEntry struct {
+	Host              string  `json:"host"`
+	FeePercentage     float64 `json:"fee_percentage"`
+	Voting            int64   `json:"voting"`
+	Voted             int64   `json:"voted"`
+	Revoked           int64   `json:"revoked"`
+	VotingReliability float64 `json:"voting_reliability"`
+	Closed            bool    `json:"closed"`
+	Reachable         bool    `json:"reachable"`
+	LastChecked       int64   `json:"last_checked"`
+}
+
 /** end vspd-related types */
 
+/** begin treasury types */
+
+// TreasuryPolicy is the voting policy for the treasury spends signed by a
+// Politeia key, or for a single treasury spend. Key is the hex encoded
+// Politeia key or the treasury spend hash.
+type TreasuryPolicy struct {
+	Key    string `json:"key"`
+	Policy string `json:"policy"`
+}
+
+type TreasuryPolicies struct {
+	TreasuryKeyPolicies []*TreasuryPolicy `json:"treasury_key_policies"`
+	TSpendPolicies      []*TreasuryPolicy `json:"tspend_policies"`
+}
+
+/** end treasury types */
+
 /** begin agenda types */
 
 // Agenda contains information about a consensus deployment
@@ -528,3 +761,30 @@ type DcrdataAgenda struct {
 }
 
 /** end agenda types */
+
+/** begin atomic swap types */
+
+// SwapContract describes an atomic swap contract and the output of the
+// contract transaction that pays to it.
+type SwapContract struct {
+	Contract         string `json:"contract"`
+	ContractAddress  string `json:"contract_address"`
+	RecipientAddress string `json:"recipient_address"`
+	RefundAddress    string `json:"refund_address"`
+	SecretHash       string `json:"secret_hash"`
+	LockTime         int64  `json:"lock_time"`
+	TxHash           string `json:"tx_hash"`
+	OutputIndex      uint32 `json:"output_index"`
+	Amount           int64  `json:"amount"`
+}
+
+// SwapTransaction describes a transaction published by InitiateSwap,
+// RedeemSwap or RefundSwap. Contract is only set by InitiateSwap.
+type SwapTransaction struct {
+	TxHash   string        `json:"tx_hash"`
+	Tx       string        `json:"tx"`
+	Fee      int64         `json:"fee"`
+	Contract *SwapContract `json:"contract,omitempty"`
+}
+
+/** end atomic swap types */
@@ -12,8 +12,10 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"decred.org/dcrwallet/v2/errors"
@@ -26,6 +28,7 @@ import (
 	"github.com/decred/dcrd/hdkeychain/v3"
 	"github.com/decred/dcrd/wire"
 	"github.com/planetdecred/dcrlibwallet/internal/loader"
+	"github.com/planetdecred/dcrlibwallet/utils"
 )
 
 const (
@@ -72,22 +75,15 @@ func (wallet *Wallet) RequiredConfirmations() int32 {
 	return DefaultRequiredConfirmations
 }
 
-func (mw *MultiWallet) listenForShutdown() {
-
-	mw.cancelFuncs = make([]context.CancelFunc, 0)
-	mw.shuttingDown = make(chan bool)
-	go func() {
-		<-mw.shuttingDown
-		for _, cancel := range mw.cancelFuncs {
-			cancel()
-		}
-	}()
+// initShutdownContext creates the context that is canceled when mw.Shutdown
+// is called. All contexts created with `contextWithShutdownCancel` derive from
+// it.
+func (mw *MultiWallet) initShutdownContext() {
+	mw.shutdownCtx, mw.shutdownCancel = context.WithCancel(context.Background())
 }
 
 func (wallet *Wallet) shutdownContextWithCancel() (context.Context, context.CancelFunc) {
-	ctx, cancel := context.WithCancel(context.Background())
-	wallet.cancelFuncs = append(wallet.cancelFuncs, cancel)
-	return ctx, cancel
+	return context.WithCancel(wallet.shutdownCtx)
 }
 
 func (wallet *Wallet) shutdownContext() (ctx context.Context) {
@@ -96,13 +92,29 @@ func (wallet *Wallet) shutdownContext() (ctx context.Context) {
 }
 
 func (mw *MultiWallet) contextWithShutdownCancel() (context.Context, context.CancelFunc) {
-	ctx, cancel := context.WithCancel(context.Background())
-	mw.cancelFuncs = append(mw.cancelFuncs, cancel)
-	return ctx, cancel
+	return context.WithCancel(mw.shutdownCtx)
 }
 
+// ValidateExtPubKey checks that extendedPubKey is a valid extended public
+// key for the network of the MultiWallet, see ValidateExtendedPublicKey.
 func (mw *MultiWallet) ValidateExtPubKey(extendedPubKey string) error {
-	_, err := hdkeychain.NewKeyFromString(extendedPubKey, mw.chainParams)
+	return validateExtendedPublicKey(extendedPubKey, mw.chainParams)
+}
+
+// ValidateExtendedPublicKey checks that extendedPubKey is a valid extended
+// public key for the network identified by netType. Extended private keys are
+// rejected.
+func ValidateExtendedPublicKey(extendedPubKey, netType string) error {
+	chainParams, err := utils.ChainParams(netType)
+	if err != nil {
+		return errors.New(ErrInvalid)
+	}
+
+	return validateExtendedPublicKey(extendedPubKey, chainParams)
+}
+
+func validateExtendedPublicKey(extendedPubKey string, chainParams *chaincfg.Params) error {
+	key, err := hdkeychain.NewKeyFromString(extendedPubKey, chainParams)
 	if err != nil {
 		if err == hdkeychain.ErrInvalidChild {
 			return errors.New(ErrUnusableSeed)
@@ -111,30 +123,63 @@ func (mw *MultiWallet) ValidateExtPubKey(extendedPubKey string) error {
 		return errors.New(ErrInvalid)
 	}
 
+	if key.IsPrivate() {
+		return errors.New(ErrInvalid)
+	}
+
 	return nil
 }
 
+// NormalizeAddress returns addr as host:port, using defaultPort when addr has
+// no port. Hostnames, IPv4 addresses and IPv6 addresses with or without
+// brackets are accepted. An ErrInvalidPort error is returned for ports outside
+// 1-65535 and an ErrInvalidAddress error for other malformed addresses.
 func NormalizeAddress(addr string, defaultPort string) (string, error) {
-	// If the first SplitHostPort errors because of a missing port and not
-	// for an invalid host, add the port.  If the second SplitHostPort
-	// fails, then a port is not missing and the original error should be
-	// returned.
-	host, port, origErr := net.SplitHostPort(addr)
-	if origErr == nil {
-		return net.JoinHostPort(host, port), nil
-	}
-	addr = net.JoinHostPort(addr, defaultPort)
-	_, _, err := net.SplitHostPort(addr)
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return "", fmt.Errorf("%s: empty address", ErrInvalidAddress)
+	}
+
+	host, port, err := net.SplitHostPort(addr)
 	if err != nil {
-		return "", origErr
+		// The address has no port, which is only valid for a bare host
+		// or an IPv6 literal, with or without brackets.
+		host, port = addr, defaultPort
+		if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+			host = host[1 : len(host)-1]
+		}
+		if strings.ContainsAny(host, "[]") || (strings.Contains(host, ":") && net.ParseIP(host) == nil) {
+			return "", fmt.Errorf("%s: %v", ErrInvalidAddress, err)
+		}
+	}
+
+	if host == "" || strings.ContainsAny(host, " /@") {
+		return "", fmt.Errorf("%s: invalid host %q", ErrInvalidAddress, host)
 	}
-	return addr, nil
+
+	portNumber, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || portNumber == 0 {
+		return "", fmt.Errorf("%s: port %q is not between 1 and 65535", ErrInvalidPort, port)
+	}
+
+	return net.JoinHostPort(host, strconv.FormatUint(portNumber, 10)), nil
 }
 
 // For use with gomobile bind,
 // doesn't support the alternative `GenerateSeed` function because it returns more than 2 types.
 func GenerateSeed() (string, error) {
-	seed, err := hdkeychain.GenerateSeed(hdkeychain.RecommendedSeedLen)
+	return GenerateSeedWithSize(hdkeychain.RecommendedSeedLen)
+}
+
+// GenerateSeedWithSize returns the mnemonic of a randomly generated seed of
+// entropyBytes length. entropyBytes must be between hdkeychain.MinSeedBytes
+// (16) and hdkeychain.MaxSeedBytes (64), inclusive.
+func GenerateSeedWithSize(entropyBytes int) (string, error) {
+	if entropyBytes < hdkeychain.MinSeedBytes || entropyBytes > hdkeychain.MaxSeedBytes {
+		return "", errors.E(ErrInvalidSeedLength)
+	}
+
+	seed, err := hdkeychain.GenerateSeed(uint8(entropyBytes))
 	if err != nil {
 		return "", err
 	}
@@ -147,6 +192,73 @@ func VerifySeed(seedMnemonic string) bool {
 	return err == nil
 }
 
+// SeedValidation describes the result of validating the words of a seed
+// mnemonic.
+type SeedValidation struct {
+	// InvalidWordIndex is the index of the first word that is not a valid
+	// PGP word for its position in the mnemonic, or -1 if all words are valid.
+	InvalidWordIndex int `json:"invalidWordIndex"`
+	// ChecksumValid is true if the mnemonic decodes to a seed with a valid
+	// checksum.
+	ChecksumValid bool `json:"checksumValid"`
+}
+
+// ValidateSeedWords checks each word of the seed mnemonic against the PGP
+// word list and reports the index of the first invalid word, if any, and
+// whether the mnemonic checksum is valid.
+func ValidateSeedWords(seedMnemonic string) *SeedValidation {
+	validation := &SeedValidation{InvalidWordIndex: -1}
+
+	words := strings.Fields(strings.ToLower(seedMnemonic))
+	for i, word := range words {
+		// Words at even positions are taken from the even word list and
+		// words at odd positions from the odd word list.
+		index, ok := seedWordIndex(word)
+		if !ok || index%2 != i%2 {
+			validation.InvalidWordIndex = i
+			return validation
+		}
+	}
+
+	validation.ChecksumValid = len(words) > 0 && VerifySeed(seedMnemonic)
+	return validation
+}
+
+// SeedWords returns the space-separated PGP word list used to encode seed
+// mnemonics.
+func SeedWords() string {
+	return strings.Join(seedWordList, " ")
+}
+
+// WordAt returns the word at the provided index of the PGP word list used to
+// encode seed mnemonics.
+func WordAt(index int) (string, error) {
+	if index < 0 || index >= len(seedWordList) {
+		return "", errors.E(ErrIndexOutOfRange)
+	}
+	return seedWordList[index], nil
+}
+
+// seedWordList is the PGP word list used to encode seed mnemonics.
+var seedWordList = PGPWordList()
+
+var (
+	seedWordIndexesOnce sync.Once
+	seedWordIndexes     map[string]int
+)
+
+func seedWordIndex(word string) (int, bool) {
+	seedWordIndexesOnce.Do(func() {
+		seedWordIndexes = make(map[string]int, len(seedWordList))
+		for i, w := range seedWordList {
+			seedWordIndexes[strings.ToLower(w)] = i
+		}
+	})
+
+	index, ok := seedWordIndexes[word]
+	return index, ok
+}
+
 // ExtractDateOrTime returns the date represented by the timestamp as a date string if the timestamp is over 24 hours ago.
 // Otherwise, the time alone is returned as a string.
 func ExtractDateOrTime(timestamp int64) string {
@@ -175,6 +287,112 @@ func AmountAtom(f float64) int64 {
 	return int64(amount)
 }
 
+// AmountString formats atoms as a DCR amount with exactly 8 decimal places,
+// e.g. "-1.50000000", without going through a float.
+func AmountString(atoms int64) string {
+	sign := ""
+	magnitude := uint64(atoms)
+	if atoms < 0 {
+		sign = "-"
+		magnitude = uint64(-atoms)
+	}
+
+	whole := magnitude / dcrutil.AtomsPerCoin
+	frac := magnitude % dcrutil.AtomsPerCoin
+	return fmt.Sprintf("%s%d.%08d", sign, whole, frac)
+}
+
+// AmountCeilString formats atoms as a DCR amount with decimalPlaces decimal
+// places, between 0 and 8, rounding the magnitude of the amount up so that
+// amounts such as fees are never under-reported.
+func AmountCeilString(atoms int64, decimalPlaces int32) string {
+	if decimalPlaces < 0 {
+		decimalPlaces = 0
+	} else if decimalPlaces > 8 {
+		decimalPlaces = 8
+	}
+
+	sign := ""
+	magnitude := uint64(atoms)
+	if atoms < 0 {
+		sign = "-"
+		magnitude = uint64(-atoms)
+	}
+
+	unit := uint64(math.Pow10(int(8 - decimalPlaces)))
+	magnitude = (magnitude + unit - 1) / unit * unit
+
+	formatted := AmountString(int64(magnitude))
+	if decimalPlaces == 0 {
+		formatted = formatted[:len(formatted)-9]
+	} else {
+		formatted = formatted[:len(formatted)-int(8-decimalPlaces)]
+	}
+	return sign + formatted
+}
+
+// ParseAmount parses a user entered DCR amount and returns it in atoms. Either
+// '.' or ',' is accepted as the decimal separator. If both are present, the
+// last one is the decimal separator and the other is taken as a digit
+// grouping separator, so an amount with more than one of the separator
+// that is last is rejected. Negative amounts and amounts with more than 8
+// decimal places are rejected.
+func ParseAmount(amount string) (int64, error) {
+	amount = strings.Join(strings.Fields(amount), "")
+
+	decimalSeparator := strings.LastIndexAny(amount, ".,")
+	if decimalSeparator >= 0 {
+		if strings.Count(amount, amount[decimalSeparator:decimalSeparator+1]) > 1 {
+			return 0, fmt.Errorf("%s: more than one decimal separator", ErrInvalid)
+		}
+		whole := strings.NewReplacer(".", "", ",", "").Replace(amount[:decimalSeparator])
+		amount = whole + "." + amount[decimalSeparator+1:]
+	}
+
+	atoms, err := parseAmountAtoms(amount)
+	if err != nil {
+		return 0, err
+	}
+	return int64(atoms), nil
+}
+
+// AppDataDir returns the directory for the data of the app with appName on
+// the current platform. It follows the dcrutil.AppDataDir conventions on
+// desktop platforms. On Android the app's private files directory is used and
+// on iOS the app's Application Support directory.
+func AppDataDir(appName string) string {
+	switch runtime.GOOS {
+	case "android":
+		// gomobile sets TMPDIR to the app's cache directory, the private
+		// files directory is its sibling.
+		return filepath.Join(filepath.Dir(os.TempDir()), "files", appName)
+	case "ios":
+		homeDir, err := os.UserHomeDir()
+		if err == nil {
+			return filepath.Join(homeDir, "Library", "Application Support", appName)
+		}
+	}
+
+	return dcrutil.AppDataDir(appName, false)
+}
+
+// DefaultDataDir returns the default data directory of dcrlibwallet on the
+// current platform.
+func DefaultDataDir() string {
+	return AppDataDir("dcrlibwallet")
+}
+
+// checkDirWritable returns an error if a file cannot be created in dir.
+func checkDirWritable(dir string) error {
+	file, err := ioutil.TempFile(dir, ".write-check-")
+	if err != nil {
+		return err
+	}
+
+	file.Close()
+	return os.Remove(file.Name())
+}
+
 func EncodeHex(hexBytes []byte) string {
 	return hex.EncodeToString(hexBytes)
 }
@@ -313,10 +531,10 @@ func backupFile(fileName string, suffix int) (newName string, err error) {
 	return newName, nil
 }
 
-func initWalletLoader(chainParams *chaincfg.Params, walletDataDir, walletDbDriver string) *loader.Loader {
+func initWalletLoader(chainParams *chaincfg.Params, walletDataDir, walletDbDriver string, gapLimit uint32) *loader.Loader {
 	// TODO: Allow users provide values to override these defaults.
 	cfg := &WalletConfig{
-		GapLimit:                20,
+		GapLimit:                gapLimit,
 		AllowHighFees:           false,
 		RelayFee:                txrules.DefaultRelayFeePerKb,
 		AccountGapLimit:         wallet.DefaultAccountGapLimit,
@@ -500,3 +718,9 @@ func HttpGet(url string, respObj interface{}) (*http.Response, []byte, error) {
 	err = json.Unmarshal(respBytes, respObj)
 	return resp, respBytes, err
 }
+
+// NetworkExists returns true if netType identifies a network supported by
+// NewMultiWallet, "mainnet" or "testnet3".
+func NetworkExists(netType string) bool {
+	return utils.NetworkExists(netType)
+}
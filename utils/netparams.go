@@ -12,13 +12,35 @@ var (
 	testnetParams = chaincfg.TestNet3Params()
 )
 
-func ChainParams(netType string) (*chaincfg.Params, error) {
-	switch strings.ToLower(netType) {
+// NormalizeNetType returns the chaincfg name of the network identified by
+// netType, ignoring case and surrounding whitespace. "testnet" is accepted
+// as an alias for "testnet3".
+func NormalizeNetType(netType string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(netType)) {
 	case strings.ToLower(mainnetParams.Name):
-		return mainnetParams, nil
-	case strings.ToLower(testnetParams.Name):
-		return testnetParams, nil
+		return mainnetParams.Name, nil
+	case strings.ToLower(testnetParams.Name), "testnet":
+		return testnetParams.Name, nil
 	default:
-		return nil, errors.New("invalid net type")
+		return "", errors.Errorf("invalid net type %q, expected %q or %q",
+			netType, mainnetParams.Name, testnetParams.Name)
+	}
+}
+
+// NetworkExists returns true if netType identifies a supported network.
+func NetworkExists(netType string) bool {
+	_, err := NormalizeNetType(netType)
+	return err == nil
+}
+
+func ChainParams(netType string) (*chaincfg.Params, error) {
+	netType, err := NormalizeNetType(netType)
+	if err != nil {
+		return nil, err
+	}
+
+	if netType == mainnetParams.Name {
+		return mainnetParams, nil
 	}
+	return testnetParams, nil
 }
@@ -0,0 +1,180 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/hdkeychain/v3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Utils", func() {
+	Describe("Seed Generation", func() {
+		Context("GenerateSeedWithSize", func() {
+			It("generates seeds that can be decoded", func() {
+				for _, size := range []int{16, 32, 64} {
+					seed, err := GenerateSeedWithSize(size)
+					Expect(err).To(BeNil())
+					Expect(VerifySeed(seed)).To(BeTrue())
+				}
+			})
+
+			It("rejects seed sizes out of range", func() {
+				_, err := GenerateSeedWithSize(15)
+				Expect(err).ToNot(BeNil())
+
+				_, err = GenerateSeedWithSize(65)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("Seed Validation", func() {
+		Context("ValidateSeedWords", func() {
+			It("accepts a valid seed", func() {
+				seed, err := GenerateSeed()
+				Expect(err).To(BeNil())
+
+				validation := ValidateSeedWords(seed)
+				Expect(validation.InvalidWordIndex).To(Equal(-1))
+				Expect(validation.ChecksumValid).To(BeTrue())
+			})
+
+			It("reports the index of the first invalid word", func() {
+				seed, err := GenerateSeed()
+				Expect(err).To(BeNil())
+
+				words := strings.Fields(seed)
+				words[3] = "notaword"
+
+				validation := ValidateSeedWords(strings.Join(words, " "))
+				Expect(validation.InvalidWordIndex).To(Equal(3))
+				Expect(validation.ChecksumValid).To(BeFalse())
+			})
+
+			It("reports words from the wrong list as invalid", func() {
+				seed, err := GenerateSeed()
+				Expect(err).To(BeNil())
+
+				// Swapping the first two words places an odd list word at an
+				// even position.
+				words := strings.Fields(seed)
+				words[0], words[1] = words[1], words[0]
+
+				validation := ValidateSeedWords(strings.Join(words, " "))
+				Expect(validation.InvalidWordIndex).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("Payment URI amounts", func() {
+		It("formats and parses amounts without rounding", func() {
+			for _, atoms := range []int64{1, 10000000, 100000000, 123456789, 2100000000000000} {
+				formatted := formatAmountAtoms(dcrutil.Amount(atoms))
+				parsed, err := parseAmountAtoms(formatted)
+				Expect(err).To(BeNil())
+				Expect(int64(parsed)).To(Equal(atoms))
+			}
+
+			Expect(formatAmountAtoms(dcrutil.Amount(150000000))).To(Equal("1.5"))
+			Expect(formatAmountAtoms(dcrutil.Amount(-150000000))).To(Equal("-1.5"))
+			Expect(formatAmountAtoms(dcrutil.Amount(0))).To(Equal("0"))
+		})
+
+		It("rejects amounts with more than 8 decimal places", func() {
+			_, err := parseAmountAtoms("0.000000001")
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Describe("Amount helpers", func() {
+		It("formats amounts with 8 decimal places", func() {
+			Expect(AmountString(150000000)).To(Equal("1.50000000"))
+			Expect(AmountString(-1)).To(Equal("-0.00000001"))
+			Expect(AmountString(0)).To(Equal("0.00000000"))
+		})
+
+		It("rounds the magnitude up", func() {
+			Expect(AmountCeilString(123456789, 2)).To(Equal("1.24"))
+			Expect(AmountCeilString(-123456789, 2)).To(Equal("-1.24"))
+			Expect(AmountCeilString(100000000, 0)).To(Equal("1"))
+		})
+
+		It("parses amounts with either decimal separator", func() {
+			for _, amount := range []string{"1234.5", "1234,5", "1,234.5", "1.234,5", " 1234.50 "} {
+				atoms, err := ParseAmount(amount)
+				Expect(err).To(BeNil())
+				Expect(atoms).To(Equal(int64(123450000000)))
+			}
+		})
+
+		It("rejects negative amounts and more than 8 decimal places", func() {
+			_, err := ParseAmount("-1")
+			Expect(err).ToNot(BeNil())
+
+			_, err = ParseAmount("0,000000001")
+			Expect(err).ToNot(BeNil())
+
+			for _, amount := range []string{"1.234.5", "1,234,5", "1,234.5.6"} {
+				_, err = ParseAmount(amount)
+				Expect(err).ToNot(BeNil())
+			}
+		})
+	})
+
+	Describe("ValidateExtendedPublicKey", func() {
+		It("accepts extended public keys of the network only", func() {
+			xprv, err := hdkeychain.NewMaster(bytes.Repeat([]byte{1}, 32), chaincfg.TestNet3Params())
+			Expect(err).To(BeNil())
+			xpub := xprv.Neuter()
+
+			Expect(ValidateExtendedPublicKey(xpub.String(), "testnet3")).To(Succeed())
+
+			err = ValidateExtendedPublicKey(xprv.String(), "testnet3")
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrInvalid]))
+			err = ValidateExtendedPublicKey(xpub.String(), "mainnet")
+			Expect(ErrorCode(err)).To(Equal(errorCodes[ErrInvalid]))
+		})
+	})
+
+	Describe("NormalizeAddress", func() {
+		It("adds the default port to addresses without a port", func() {
+			for _, test := range []struct {
+				addr       string
+				normalized string
+			}{
+				{"mainnet-seed.example.org", "mainnet-seed.example.org:19108"},
+				{"mainnet-seed.example.org:29108", "mainnet-seed.example.org:29108"},
+				{"127.0.0.1", "127.0.0.1:19108"},
+				{" 127.0.0.1:9108 ", "127.0.0.1:9108"},
+				{"::1", "[::1]:19108"},
+				{"[::1]", "[::1]:19108"},
+				{"[::1]:19108", "[::1]:19108"},
+				{"[2001:db8::1]:65535", "[2001:db8::1]:65535"},
+				{"expyuzz4wqqyqhjn.onion", "expyuzz4wqqyqhjn.onion:19108"},
+				{"expyuzz4wqqyqhjn.onion:9108", "expyuzz4wqqyqhjn.onion:9108"},
+			} {
+				normalized, err := NormalizeAddress(test.addr, "19108")
+				Expect(err).To(BeNil(), test.addr)
+				Expect(normalized).To(Equal(test.normalized), test.addr)
+			}
+		})
+
+		It("rejects ports out of range", func() {
+			for _, addr := range []string{"127.0.0.1:0", "127.0.0.1:65536", "[::1]:-1", "example.org:port", "example.org:"} {
+				_, err := NormalizeAddress(addr, "19108")
+				Expect(ErrorCode(err)).To(Equal(errorCodes[ErrInvalidPort]), addr)
+			}
+		})
+
+		It("rejects malformed addresses", func() {
+			for _, addr := range []string{"", ":19108", "[::1", "[::1]]", "2001:db8::zz", "example.org:1:2", "user@example.org"} {
+				_, err := NormalizeAddress(addr, "19108")
+				Expect(ErrorCode(err)).To(Equal(errorCodes[ErrInvalidAddress]), addr)
+			}
+		})
+	})
+})
@@ -1,12 +1,16 @@
 package dcrlibwallet
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"decred.org/dcrwallet/v2/errors"
 	"decred.org/dcrwallet/v2/wallet/txauthor"
 	"decred.org/dcrwallet/v2/wallet/txrules"
 	"decred.org/dcrwallet/v2/wallet/txsizes"
+	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrd/txscript/v4"
@@ -68,7 +72,7 @@ func (tx *TxAuthor) constructCustomTransaction() (*txauthor.AuthoredTx, error) {
 	// no recipient is set to receive max amount.
 	nextInternalAddress := func() (string, error) {
 		ctx := tx.sourceWallet.shutdownContext()
-		addr, err := tx.sourceWallet.Internal().NewChangeAddress(ctx, tx.sourceAccountNumber)
+		addr, err := tx.sourceWallet.Internal().NewChangeAddress(ctx, tx.changeAccount())
 		if err != nil {
 			return "", err
 		}
@@ -118,21 +122,22 @@ func (tx *TxAuthor) newUnsignedTxUTXO(inputs []*wire.TxIn, sendDestinations []Tr
 	}
 
 	maxSignedSize := txsizes.EstimateSerializeSize(inputScriptSizes, outputs, changeScriptSize)
-	maxRequiredFee := txrules.FeeForSerializeSize(txrules.DefaultRelayFeePerKb, maxSignedSize)
+	maxRequiredFee := txrules.FeeForSerializeSize(tx.feeRate, maxSignedSize)
 	changeAmount := totalInputAmount - totalSendAmount - int64(maxRequiredFee)
 
 	if changeAmount < 0 {
-		return nil, errors.New(ErrInsufficientBalance)
+		return nil, fmt.Errorf("%s: selected inputs are short by %v", ErrInsufficientBalance, dcrutil.Amount(-changeAmount))
 	}
 
-	if changeAmount != 0 && !txrules.IsDustAmount(dcrutil.Amount(changeAmount), changeScriptSize, txrules.DefaultRelayFeePerKb) {
+	changeIndex := -1
+	if changeAmount != 0 && !isDustAmount(changeAmount, changeScriptSize, tx.feeRate) {
 		if changeScriptSize > txscript.MaxScriptElementSize {
 			return nil, fmt.Errorf("script size exceed maximum bytes pushable to the stack")
 		}
 		if maxAmountRecipientAddress != "" {
-			outputs, err = tx.changeOutput(changeAmount, maxAmountRecipientAddress, outputs)
+			outputs, changeIndex, err = tx.changeOutput(changeAmount, maxAmountRecipientAddress, outputs)
 		} else if changeDestination != nil {
-			outputs, err = tx.changeOutput(changeAmount, changeDestination.Address, outputs)
+			outputs, changeIndex, err = tx.changeOutput(changeAmount, changeDestination.Address, outputs)
 		}
 		if err != nil {
 			return nil, fmt.Errorf("change address error: %v", err)
@@ -142,6 +147,7 @@ func (tx *TxAuthor) newUnsignedTxUTXO(inputs []*wire.TxIn, sendDestinations []Tr
 	return &txauthor.AuthoredTx{
 		TotalInput:                   dcrutil.Amount(totalInputAmount),
 		EstimatedSignedSerializeSize: maxSignedSize,
+		ChangeIndex:                  changeIndex,
 		Tx: &wire.MsgTx{
 			SerType:  wire.TxSerializeFull,
 			Version:  wire.TxVersion,
@@ -153,12 +159,132 @@ func (tx *TxAuthor) newUnsignedTxUTXO(inputs []*wire.TxIn, sendDestinations []Tr
 	}, nil
 }
 
-func (tx *TxAuthor) changeOutput(changeAmount int64, maxAmountRecipientAddress string, outputs []*wire.TxOut) ([]*wire.TxOut, error) {
+func (tx *TxAuthor) changeOutput(changeAmount int64, maxAmountRecipientAddress string, outputs []*wire.TxOut) ([]*wire.TxOut, int, error) {
 	changeOutput, err := txhelper.MakeTxOutput(maxAmountRecipientAddress, changeAmount, tx.sourceWallet.chainParams)
 	if err != nil {
-		return nil, err
+		return nil, -1, err
 	}
 	outputs = append(outputs, changeOutput)
-	txauthor.RandomizeOutputPosition(outputs, len(outputs)-1)
-	return outputs, nil
+	changeIndex := txauthor.RandomizeOutputPosition(outputs, len(outputs)-1)
+	return outputs, changeIndex, nil
+}
+
+// LockUnspentOutput excludes the output identified by outputKey, in the
+// "hash:index" format, from being selected as an input for new transactions.
+// The lock is saved and reapplied when the wallet is opened.
+func (wallet *Wallet) LockUnspentOutput(outputKey string) error {
+	if !wallet.WalletOpened() {
+		return errors.New(ErrWalletNotLoaded)
+	}
+
+	op, err := parseOutputKey(outputKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = wallet.Internal().OutputInfo(wallet.shutdownContext(), op)
+	if err != nil {
+		return errors.New(ErrNotExist)
+	}
+
+	wallet.lockedOutputsMu.Lock()
+	defer wallet.lockedOutputsMu.Unlock()
+
+	wallet.Internal().LockOutpoint(&op.Hash, op.Index)
+
+	lockedOutputs := wallet.lockedOutputs()
+	for _, key := range lockedOutputs {
+		if key == op.String() {
+			return nil
+		}
+	}
+	wallet.SaveUserConfigValue(LockedOutputsConfigKey, append(lockedOutputs, op.String()))
+	return nil
+}
+
+// UnlockUnspentOutput makes an output previously locked with
+// LockUnspentOutput available for selection as an input again.
+func (wallet *Wallet) UnlockUnspentOutput(outputKey string) error {
+	if !wallet.WalletOpened() {
+		return errors.New(ErrWalletNotLoaded)
+	}
+
+	op, err := parseOutputKey(outputKey)
+	if err != nil {
+		return err
+	}
+
+	wallet.lockedOutputsMu.Lock()
+	defer wallet.lockedOutputsMu.Unlock()
+
+	wallet.Internal().UnlockOutpoint(&op.Hash, op.Index)
+
+	lockedOutputs := wallet.lockedOutputs()
+	for i, key := range lockedOutputs {
+		if key == op.String() {
+			lockedOutputs = append(lockedOutputs[:i], lockedOutputs[i+1:]...)
+			wallet.SaveUserConfigValue(LockedOutputsConfigKey, lockedOutputs)
+			break
+		}
+	}
+	return nil
+}
+
+// ListLockedOutputs returns a JSON array of the keys of the outputs locked
+// with LockUnspentOutput.
+func (wallet *Wallet) ListLockedOutputs() (string, error) {
+	wallet.lockedOutputsMu.Lock()
+	lockedOutputs := wallet.lockedOutputs()
+	wallet.lockedOutputsMu.Unlock()
+
+	result, err := json.Marshal(lockedOutputs)
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
+// lockedOutputs returns the saved output locks. wallet.lockedOutputsMu
+// MUST be held by the caller.
+func (wallet *Wallet) lockedOutputs() []string {
+	lockedOutputs := make([]string, 0)
+	wallet.readUserConfigValue(false, LockedOutputsConfigKey, &lockedOutputs)
+	return lockedOutputs
+}
+
+// relockOutputs locks the outputs saved by LockUnspentOutput with the
+// loaded wallet.
+func (wallet *Wallet) relockOutputs() {
+	wallet.lockedOutputsMu.Lock()
+	defer wallet.lockedOutputsMu.Unlock()
+
+	for _, key := range wallet.lockedOutputs() {
+		op, err := parseOutputKey(key)
+		if err != nil {
+			log.Errorf("[%d] invalid locked output %s: %v", wallet.ID, key, err)
+			continue
+		}
+		wallet.Internal().LockOutpoint(&op.Hash, op.Index)
+	}
+}
+
+// parseOutputKey parses an output key in the "hash:index" format.
+func parseOutputKey(outputKey string) (*wire.OutPoint, error) {
+	idx := strings.LastIndex(outputKey, ":")
+	if idx < 0 {
+		return nil, errors.E(errors.Invalid, "invalid output key")
+	}
+
+	hash, err := chainhash.NewHashFromStr(outputKey[:idx])
+	if err != nil {
+		return nil, errors.E(errors.Invalid, "invalid output key")
+	}
+
+	index, err := strconv.ParseUint(outputKey[idx+1:], 10, 32)
+	if err != nil {
+		return nil, errors.E(errors.Invalid, "invalid output key")
+	}
+
+	return wire.NewOutPoint(hash, uint32(index), wire.TxTreeUnknown), nil
 }
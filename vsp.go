@@ -4,13 +4,20 @@ import (
 	"context"
 	"crypto/ed25519"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"decred.org/dcrwallet/v2/errors"
 	"github.com/planetdecred/dcrlibwallet/internal/vsp"
 )
 
+// vspListCacheTTL is how long a VSP list fetched by GetVSPList is used
+// before the directory is fetched again.
+const vspListCacheTTL = time.Hour
+
 // VSPClient loads or creates a VSP client instance for the specified host.
 func (wallet *Wallet) VSPClient(host string, pubKey []byte) (*vsp.Client, error) {
 	wallet.vspClientsMu.Lock()
@@ -34,6 +41,130 @@ func (wallet *Wallet) VSPClient(host string, pubKey []byte) (*vsp.Client, error)
 	return client, nil
 }
 
+// SetVSP sets the VSP used by PurchaseTicketsWithVSP for the wallet with
+// walletID. The VSP must serve the active network.
+func (mw *MultiWallet) SetVSP(walletID int, host string) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	info, err := vspInfo(host)
+	if err != nil {
+		return err
+	}
+	if info.Network != mw.NetType() {
+		return fmt.Errorf("invalid net %s", info.Network)
+	}
+
+	wallet.SetStringConfigValueForKey(VSPHostConfigKey, host)
+	mw.SaveLastUsedVSP(host)
+	return nil
+}
+
+// PurchaseTicketsWithVSP purchases numTickets tickets from the specified
+// account of the wallet with walletID and pays the fee of the VSP set with
+// SetVSP. Tickets whose fee payment is interrupted can be processed later
+// with ProcessUnprocessedVSPTickets. Returns the hashes of the tickets.
+func (mw *MultiWallet) PurchaseTicketsWithVSP(walletID int, privPass []byte, account, numTickets int32) ([]string, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return nil, errors.New(ErrNotExist)
+	}
+
+	host, info, err := wallet.vspInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	err = wallet.checkTicketsAffordable(account, numTickets, wallet.RequiredConfirmations(), info.FeePercentage)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes, err := wallet.PurchaseTickets(account, numTickets, host, info.PubKey, privPass)
+	if err != nil {
+		return nil, err
+	}
+
+	ticketHashes := make([]string, len(hashes))
+	for i, hash := range hashes {
+		ticketHashes[i] = hash.String()
+	}
+	return ticketHashes, nil
+}
+
+// GetVSPTicketsFeeStatus returns the fee status of the ticket with the
+// provided hash as recorded by the wallet, or by the VSP if the wallet is
+// unlocked.
+func (mw *MultiWallet) GetVSPTicketsFeeStatus(walletID int, ticketHash string) (string, error) {
+	ticketInfo, err := mw.VSPTicketInfo(walletID, ticketHash)
+	if err != nil {
+		return "", err
+	}
+	return ticketInfo.FeeTxStatus.String(), nil
+}
+
+// ProcessUnprocessedVSPTickets retries the fee payment, with the VSP set with
+// SetVSP, of the wallet's unspent and unexpired tickets that are not yet
+// registered with any VSP. Fees are paid from the specified account.
+func (mw *MultiWallet) ProcessUnprocessedVSPTickets(walletID int, privPass []byte, account int32) error {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	host, info, err := wallet.vspInfo()
+	if err != nil {
+		return err
+	}
+
+	vspClient, err := wallet.VSPClient(host, info.PubKey)
+	if err != nil {
+		return err
+	}
+
+	relock, err := wallet.unlockTemporarily(privPass)
+	if err != nil {
+		return translateError(err)
+	}
+	defer relock()
+
+	vspPolicy := vsp.Policy{
+		MaxFee:     0.2e8,
+		FeeAcct:    uint32(account),
+		ChangeAcct: uint32(account),
+	}
+	vspClient.ProcessUnprocessedTickets(wallet.shutdownContext(), vspPolicy)
+	return nil
+}
+
+// vspInfo returns the host and info of the VSP set with SetVSP.
+func (wallet *Wallet) vspInfo() (string, *VspInfoResponse, error) {
+	host := wallet.ReadStringConfigValueForKey(VSPHostConfigKey, "")
+	if host == "" {
+		return "", nil, errors.New("vsp not set for this wallet")
+	}
+
+	info, err := vspInfo(host)
+	if err != nil {
+		return "", nil, err
+	}
+	return host, info, nil
+}
+
 // KnownVSPs returns a list of known VSPs. This list may be updated by calling
 // ReloadVSPList. This method is safe for concurrent access.
 func (mw *MultiWallet) KnownVSPs() []*VSP {
@@ -190,3 +321,97 @@ func defaultVSPs(network string) ([]string, error) {
 	}
 	return vsps, nil
 }
+
+type vspListCache struct {
+	FetchedAt int64
+	VSPs      []*VSPListEntry
+}
+
+// GetVSPList returns a JSON array of the VSPs listed in the public VSP
+// directory for netType, each probed for reachability. The list is cached
+// for vspListCacheTTL; an expired cached list is returned if the directory
+// cannot be fetched.
+func (mw *MultiWallet) GetVSPList(netType string) (string, error) {
+	cacheKey := VSPListConfigKey + "_" + netType
+	cache := new(vspListCache)
+	cacheErr := mw.ReadUserConfigValue(cacheKey, cache)
+	if cacheErr == nil && time.Since(time.Unix(cache.FetchedAt, 0)) < vspListCacheTTL {
+		result, _ := json.Marshal(cache.VSPs)
+		return string(result), nil
+	}
+
+	vsps, err := fetchVSPList(netType)
+	if err != nil {
+		if cacheErr != nil {
+			return "", err
+		}
+		log.Warnf("using cached vsp list, fetching vsp list failed: %v", err)
+		vsps = cache.VSPs
+	} else {
+		mw.SaveUserConfigValue(cacheKey, &vspListCache{
+			FetchedAt: time.Now().Unix(),
+			VSPs:      vsps,
+		})
+	}
+
+	result, _ := json.Marshal(vsps)
+	return string(result), nil
+}
+
+// fetchVSPList fetches the VSPs for netType from the public VSP directory and
+// probes the vspinfo endpoint of each VSP concurrently.
+func fetchVSPList(netType string) ([]*VSPListEntry, error) {
+	var directory map[string]*VspInfoResponse
+	_, _, err := HttpGet("https://api.decred.org/?c=vsp", &directory)
+	if err != nil {
+		return nil, err
+	}
+
+	vsps := make([]*VSPListEntry, 0, len(directory))
+	for url, info := range directory {
+		if !strings.Contains(netType, info.Network) {
+			continue
+		}
+
+		vsps = append(vsps, &VSPListEntry{
+			Host:          "https://" + url,
+			FeePercentage: info.FeePercentage,
+			Voting:        info.Voting,
+			Voted:         info.Voted,
+			Revoked:       info.Revoked,
+			Closed:        info.VspClosed,
+		})
+	}
+
+	var wg sync.WaitGroup
+	for _, entry := range vsps {
+		wg.Add(1)
+		go func(entry *VSPListEntry) {
+			defer wg.Done()
+
+			info, err := vspInfo(entry.Host)
+			entry.LastChecked = time.Now().Unix()
+			if err != nil {
+				log.Debugf("vsp info error for %s: %v", entry.Host, err)
+				return
+			}
+
+			entry.Reachable = true
+			entry.FeePercentage = info.FeePercentage
+			entry.Voting = info.Voting
+			entry.Voted = info.Voted
+			entry.Revoked = info.Revoked
+			entry.Closed = info.VspClosed
+		}(entry)
+	}
+	wg.Wait()
+
+	for _, entry := range vsps {
+		entry.VotingReliability = -1
+		if spent := entry.Voted + entry.Revoked; spent > 0 {
+			entry.VotingReliability = float64(entry.Voted) / float64(spent)
+		}
+	}
+
+	return vsps, nil
+}
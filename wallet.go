@@ -26,6 +26,7 @@ type Wallet struct {
 	EncryptedSeed         []byte
 	IsRestored            bool
 	HasDiscoveredAccounts bool
+	RescanOnFirstSync     bool
 	PrivatePassphraseType int32
 
 	chainParams  *chaincfg.Params
@@ -37,13 +38,49 @@ type Wallet struct {
 	syncing           bool
 	waitingForHeaders bool
 
-	shuttingDown       chan bool
-	cancelFuncs        []context.CancelFunc
-	cancelAccountMixer context.CancelFunc
+	// customPubPass is true if the wallet was opened with a public
+	// passphrase other than the default one.
+	customPubPass bool
+
+	// shutdownCtx is canceled when the wallet is shut down, all contexts
+	// created with `shutdownContextWithCancel` derive from it.
+	shutdownMu     sync.Mutex
+	shutdownCtx    context.Context
+	shutdownCancel context.CancelFunc
+
+	// cancelAccountMixer stops the account mixer, it is nil if the mixer
+	// is not running.
+	cancelAccountMixerMu sync.Mutex
+	cancelAccountMixer   context.CancelFunc
 
 	cancelAutoTicketBuyerMu sync.Mutex
 	cancelAutoTicketBuyer   context.CancelFunc
 
+	// ticketBuyerPurchases is the number of ongoing ticket buyer purchases,
+	// the wallet is locked when it drops to 0 if ticketBuyerRelock is set.
+	ticketBuyerUnlockMu  sync.Mutex
+	ticketBuyerPurchases int
+	ticketBuyerRelock    bool
+
+	// lockTimer locks the wallet when it fires, if the wallet was unlocked
+	// with UnlockWalletWithTimeout.
+	lockTimerMu  sync.Mutex
+	lockTimer    *time.Timer
+	lockDeadline time.Time
+
+	// lockedOutputsMu guards the locked outputs saved to the config db.
+	lockedOutputsMu sync.Mutex
+
+	// stakeInfo caches the result of StakeInfo for the current tip block,
+	// it is cleared when blocks are attached or new txs are received.
+	stakeInfoMu sync.Mutex
+	stakeInfo   *StakeInfo
+
+	// accountBalances holds the account balances reported to the balance
+	// notification listeners, it is nil until the balances are first read.
+	accountBalancesMu sync.Mutex
+	accountBalances   map[int32]Balance
+
 	vspClientsMu sync.Mutex
 	vspClients   map[string]*vsp.Client
 
@@ -57,55 +94,123 @@ type Wallet struct {
 	// This function is ideally assigned when the `wallet.prepare` method is
 	// called from a MultiWallet instance.
 	readUserConfigValue configReadFn
+
+	// deleteUserConfigValue deletes the value saved for the provided key
+	// from a config database. This function is ideally assigned when the
+	// `wallet.prepare` method is called from a MultiWallet instance.
+	deleteUserConfigValue configDeleteFn
+
+	// lockStateChanged is called when the wallet is locked or unlocked.
+	// It is assigned by the MultiWallet instance managing this wallet.
+	lockStateChanged func(walletID int, locked bool)
+
+	// rescanning returns true if a blocks rescan is in progress and
+	// ticketPurchaseFailed is called when the ticket buyer fails to buy a
+	// ticket. Both are assigned by the MultiWallet instance managing this
+	// wallet.
+	rescanning           func() bool
+	ticketPurchaseFailed func(walletID int, err error)
+
+	// addressLabel returns the address book label of an address, it is
+	// assigned by the MultiWallet instance managing this wallet.
+	addressLabel func(address string) string
+
+	// transactionFeeRate returns the fee rate used for newly constructed
+	// transactions, it is assigned by the MultiWallet instance managing
+	// this wallet.
+	transactionFeeRate func() int64
 }
 
 // prepare gets a wallet ready for use by opening the transactions index database
 // and initializing the wallet loader which can be used subsequently to create,
 // load and unload the wallet.
 func (wallet *Wallet) prepare(rootDir string, chainParams *chaincfg.Params,
-	setUserConfigValueFn configSaveFn, readUserConfigValueFn configReadFn) (err error) {
+	setUserConfigValueFn configSaveFn, readUserConfigValueFn configReadFn, deleteUserConfigValueFn configDeleteFn) (err error) {
 
 	wallet.chainParams = chainParams
 	wallet.dataDir = filepath.Join(rootDir, strconv.Itoa(wallet.ID))
 	wallet.vspClients = make(map[string]*vsp.Client)
 	wallet.setUserConfigValue = setUserConfigValueFn
 	wallet.readUserConfigValue = readUserConfigValueFn
+	wallet.deleteUserConfigValue = deleteUserConfigValueFn
 
 	// open database for indexing transactions for faster loading
+	err = wallet.openWalletDataDB()
+	if err != nil {
+		return err
+	}
+
+	// init loader
+	wallet.loader = initWalletLoader(wallet.chainParams, wallet.dataDir, wallet.DbDriver, uint32(wallet.GapLimit()))
+
+	// init the context that is canceled when the wallet is shut down to stop
+	// long running operations
+	wallet.shutdownMu.Lock()
+	wallet.shutdownCtx, wallet.shutdownCancel = context.WithCancel(context.Background())
+	wallet.shutdownMu.Unlock()
+
+	return nil
+}
+
+// openWalletDataDB opens the database used for indexing transactions.
+func (wallet *Wallet) openWalletDataDB() (err error) {
 	walletDataDBPath := filepath.Join(wallet.dataDir, walletdata.DbName)
 	oldTxDBPath := filepath.Join(wallet.dataDir, walletdata.OldDbName)
 	if exists, _ := fileExists(oldTxDBPath); exists {
 		moveFile(oldTxDBPath, walletDataDBPath)
 	}
-	wallet.walletDataDB, err = walletdata.Initialize(walletDataDBPath, chainParams, &Transaction{})
+	wallet.walletDataDB, err = walletdata.Initialize(walletDataDBPath, wallet.chainParams, &Transaction{})
 	if err != nil {
 		log.Error(err.Error())
 		return err
 	}
 
-	// init loader
-	wallet.loader = initWalletLoader(wallet.chainParams, wallet.dataDir, wallet.DbDriver)
-
-	// init cancelFuncs slice to hold cancel functions for long running
-	// operations and start go routine to listen for shutdown signal
-	wallet.cancelFuncs = make([]context.CancelFunc, 0)
-	wallet.shuttingDown = make(chan bool)
-	go func() {
-		<-wallet.shuttingDown
-		for _, cancel := range wallet.cancelFuncs {
-			cancel()
+	return nil
+}
+
+// closeWallet unloads the wallet and closes the transactions index database.
+// Unlike Shutdown, the wallet can be opened again with openWallet.
+func (wallet *Wallet) closeWallet() error {
+	wallet.stopLockTimer()
+
+	if _, loaded := wallet.loader.LoadedWallet(); loaded {
+		err := wallet.loader.UnloadWallet()
+		if err != nil {
+			return err
 		}
-	}()
+	}
+
+	if wallet.walletDataDB != nil {
+		err := wallet.walletDataDB.Close()
+		if err != nil {
+			return err
+		}
+		wallet.walletDataDB = nil
+	}
 
 	return nil
 }
 
+// Shutdown cancels the wallet's long running operations, locks and unloads
+// the wallet and closes its transactions index database. Calling Shutdown on
+// a wallet that is already shut down has no effect.
 func (wallet *Wallet) Shutdown() {
-	// Trigger shuttingDown signal to cancel all contexts created with
-	// `wallet.shutdownContext()` or `wallet.shutdownContextWithCancel()`.
-	wallet.shuttingDown <- true
+	wallet.shutdownMu.Lock()
+	defer wallet.shutdownMu.Unlock()
+
+	if wallet.shutdownCtx == nil || wallet.shutdownCtx.Err() != nil {
+		return
+	}
+
+	// Cancel all contexts created with `wallet.shutdownContext()` or
+	// `wallet.shutdownContextWithCancel()`.
+	wallet.shutdownCancel()
 
 	if _, loaded := wallet.loader.LoadedWallet(); loaded {
+		if !wallet.IsLocked() {
+			wallet.LockWallet()
+		}
+
 		err := wallet.loader.UnloadWallet()
 		if err != nil {
 			log.Errorf("Failed to close wallet: %v", err)
@@ -121,6 +226,7 @@ func (wallet *Wallet) Shutdown() {
 		} else {
 			log.Info("tx db closed successfully")
 		}
+		wallet.walletDataDB = nil
 	}
 }
 
@@ -134,6 +240,61 @@ func (wallet *Wallet) WalletCreationTimeInMillis() (int64, error) {
 	return wallet.CreatedAt.UnixNano() / int64(time.Millisecond), nil
 }
 
+// Birthday returns the unix timestamp from which the wallet may have
+// transactions. This is the creation time for wallets created by this library
+// or the time set with SetBirthday for restored wallets. 0 is returned if the
+// birthday of a restored wallet is unknown.
+func (wallet *Wallet) Birthday() int64 {
+	birthday := wallet.ReadLongConfigValueForKey(WalletBirthdayConfigKey, 0)
+	if birthday == 0 && !wallet.IsRestored {
+		birthday = wallet.CreatedAt.Unix()
+	}
+	return birthday
+}
+
+// SetBirthday sets an approximate time from which the wallet may have
+// transactions. It is used to bound the blocks scanned for restored wallets.
+func (wallet *Wallet) SetBirthday(unixTime int64) error {
+	if unixTime < 0 || unixTime > time.Now().Unix() {
+		return errors.New(ErrInvalid)
+	}
+
+	wallet.SetLongConfigValueForKey(WalletBirthdayConfigKey, unixTime)
+	return nil
+}
+
+// birthdayBlockHeight returns the height of the last main chain block mined
+// a day or more before the wallet's birthday, or 0 if the birthday is unknown.
+func (wallet *Wallet) birthdayBlockHeight() (int32, error) {
+	birthday := wallet.Birthday()
+	if birthday == 0 {
+		return 0, nil
+	}
+	// Allow for inaccurate block timestamps and birthdays set by users.
+	birthday -= int64((24 * time.Hour).Seconds())
+
+	ctx := wallet.shutdownContext()
+	_, tipHeight := wallet.Internal().MainChainTip(ctx)
+
+	// Binary search for the highest block with a timestamp before the birthday.
+	var low, high int32 = 0, tipHeight
+	for low < high {
+		mid := low + (high-low+1)/2
+		info, err := wallet.Internal().BlockInfo(ctx, w.NewBlockIdentifierFromHeight(mid))
+		if err != nil {
+			return 0, err
+		}
+
+		if info.Timestamp < birthday {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+
+	return low, nil
+}
+
 func (wallet *Wallet) NetType() string {
 	return wallet.chainParams.Name
 }
@@ -193,17 +354,78 @@ func (wallet *Wallet) IsWatchingOnlyWallet() bool {
 }
 
 func (wallet *Wallet) openWallet() error {
-	pubPass := []byte(w.InsecurePubPassphrase)
+	return wallet.openWalletWithPublicPassphrase([]byte(w.InsecurePubPassphrase))
+}
+
+// openWalletWithPublicPassphrase opens the wallet database using pubPass.
+// ErrInvalidPublicPassphrase is returned if pubPass is incorrect. The
+// transactions index database is closed again if the wallet cannot be
+// opened, so that opening the wallet can be retried.
+func (wallet *Wallet) openWalletWithPublicPassphrase(pubPass []byte) error {
+	if wallet.WalletOpened() {
+		return nil
+	}
+
+	openedWalletDataDB := false
+	if wallet.walletDataDB == nil {
+		// The transactions index database was closed by closeWallet.
+		if err := wallet.openWalletDataDB(); err != nil {
+			return err
+		}
+		openedWalletDataDB = true
+	}
+
+	// The gap limit may have been changed with SetAccountGapLimit since
+	// the loader was created.
+	wallet.loader.SetGapLimit(uint32(wallet.GapLimit()))
 
 	_, err := wallet.loader.OpenExistingWallet(wallet.shutdownContext(), pubPass)
 	if err != nil {
 		log.Error(err)
+
+		if openedWalletDataDB {
+			if closeErr := wallet.walletDataDB.Close(); closeErr != nil {
+				log.Errorf("[%d] tx db closed with error: %v", wallet.ID, closeErr)
+			}
+			wallet.walletDataDB = nil
+		}
+
+		if errors.Is(err, errors.Passphrase) {
+			return errors.New(ErrInvalidPublicPassphrase)
+		}
 		return translateError(err)
 	}
 
+	wallet.customPubPass = string(pubPass) != w.InsecurePubPassphrase
+
+	// Output locks are kept in memory by the wallet, reapply the ones
+	// that were saved before the wallet was last closed.
+	wallet.relockOutputs()
+
 	return nil
 }
 
+// WalletHasCustomPublicPassphrase returns true if the wallet database is
+// encrypted with a public passphrase other than the default one, in which case
+// the wallet must be opened with OpenWalletWithPublicPassphrase. Wallets that
+// are not open are checked by opening them with the default passphrase.
+func (wallet *Wallet) WalletHasCustomPublicPassphrase() (bool, error) {
+	if wallet.WalletOpened() {
+		return wallet.customPubPass, nil
+	}
+
+	ctx := wallet.shutdownContext()
+	_, err := wallet.loader.OpenExistingWallet(ctx, []byte(w.InsecurePubPassphrase))
+	if err != nil {
+		if errors.Is(err, errors.Passphrase) {
+			return true, nil
+		}
+		return false, translateError(err)
+	}
+
+	return false, wallet.loader.UnloadWallet()
+}
+
 func (wallet *Wallet) WalletOpened() bool {
 	return wallet.Internal() != nil
 }
@@ -214,28 +436,230 @@ func (wallet *Wallet) UnlockWallet(privPass []byte) error {
 		return fmt.Errorf("wallet has not been loaded")
 	}
 
+	if loadedWallet.WatchingOnly() {
+		return errors.New(ErrWalletIsWatchOnly)
+	}
+
+	wasLocked := loadedWallet.Locked()
+
 	ctx, _ := wallet.shutdownContextWithCancel()
 	err := loadedWallet.Unlock(ctx, privPass, nil)
 	if err != nil {
+		if errors.Is(err, errors.Passphrase) && !wasLocked {
+			// dcrwallet locks an unlocked wallet if the wrong passphrase
+			// is provided.
+			wallet.notifyLockStateChanged(true)
+		}
 		return translateError(err)
 	}
 
+	if wasLocked {
+		wallet.notifyLockStateChanged(false)
+	}
+
+	return nil
+}
+
+// VerifyPrivatePassphrase checks that privPass is the wallet's private
+// passphrase. The wallet is locked again after the check if it was locked
+// before.
+func (wallet *Wallet) VerifyPrivatePassphrase(privPass []byte) (bool, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	loadedWallet, ok := wallet.loader.LoadedWallet()
+	if !ok {
+		return false, errors.New(ErrWalletNotLoaded)
+	}
+
+	if loadedWallet.WatchingOnly() {
+		return false, errors.New(ErrWalletIsWatchOnly)
+	}
+
+	wasLocked := loadedWallet.Locked()
+	err := loadedWallet.Unlock(wallet.shutdownContext(), privPass, nil)
+	if errors.Is(err, errors.Passphrase) {
+		if !wasLocked {
+			// dcrwallet locks an unlocked wallet if the wrong passphrase
+			// is provided.
+			wallet.notifyLockStateChanged(true)
+		}
+		return false, nil
+	} else if err != nil {
+		return false, translateError(err)
+	}
+
+	if wasLocked {
+		loadedWallet.Lock()
+	}
+
+	return true, nil
+}
+
+// UnlockWalletWithTimeout unlocks the wallet and locks it again after
+// timeoutSeconds, unless LockWallet is called earlier. Unlocking the wallet
+// again with a timeout resets the timer.
+func (wallet *Wallet) UnlockWalletWithTimeout(privPass []byte, timeoutSeconds int64) error {
+	if timeoutSeconds <= 0 {
+		return errors.New(ErrInvalid)
+	}
+
+	err := wallet.UnlockWallet(privPass)
+	if err != nil {
+		return err
+	}
+
+	wallet.lockTimerMu.Lock()
+	defer wallet.lockTimerMu.Unlock()
+
+	if wallet.lockTimer != nil {
+		wallet.lockTimer.Stop()
+	}
+	wallet.startLockTimer(time.Now().Add(time.Duration(timeoutSeconds) * time.Second))
+
+	return nil
+}
+
+// ExtendUnlockTimeout delays the automatic lock of a wallet unlocked with
+// UnlockWalletWithTimeout to timeoutSeconds from now, unless the wallet is
+// already due to be locked later. This allows operations that require the
+// wallet to be unlocked, such as signing a transaction, to complete before
+// the wallet is locked.
+func (wallet *Wallet) ExtendUnlockTimeout(timeoutSeconds int64) error {
+	if timeoutSeconds <= 0 {
+		return errors.New(ErrInvalid)
+	}
+
+	wallet.lockTimerMu.Lock()
+	defer wallet.lockTimerMu.Unlock()
+
+	if wallet.lockTimer == nil || wallet.IsLocked() {
+		return errors.New(ErrWalletLocked)
+	}
+
+	deadline := time.Now().Add(time.Duration(timeoutSeconds) * time.Second)
+	if deadline.After(wallet.lockDeadline) {
+		wallet.lockDeadline = deadline
+		wallet.lockTimer.Reset(time.Until(deadline))
+	}
 	return nil
 }
 
+// unlockTemporarily unlocks the wallet with privPass for an operation that
+// requires the private keys and returns the func that restores the lock state
+// the wallet had before, once the operation is done. A wallet that was locked
+// is locked again and one that was unlocked without a timeout is left
+// unlocked. The automatic lock of a wallet unlocked with
+// UnlockWalletWithTimeout is paused during the operation and the remaining
+// timeout is restored after it, locking the wallet if it has expired.
+func (wallet *Wallet) unlockTemporarily(privPass []byte) (relock func(), err error) {
+	wasLocked := wallet.IsLocked()
+	lockDeadline := wallet.pauseLockTimer()
+
+	err = wallet.UnlockWallet(privPass)
+	if err != nil {
+		wallet.resumeLockTimer(lockDeadline)
+		return nil, err
+	}
+
+	return func() {
+		if wasLocked {
+			wallet.LockWallet()
+			return
+		}
+		wallet.resumeLockTimer(lockDeadline)
+	}, nil
+}
+
+// pauseLockTimer stops the automatic lock of a wallet unlocked with
+// UnlockWalletWithTimeout and returns the time it was due, which is zero if
+// there is no automatic lock.
+func (wallet *Wallet) pauseLockTimer() time.Time {
+	wallet.lockTimerMu.Lock()
+	defer wallet.lockTimerMu.Unlock()
+
+	deadline := wallet.lockDeadline
+	if wallet.lockTimer != nil {
+		wallet.lockTimer.Stop()
+		wallet.lockTimer = nil
+		wallet.lockDeadline = time.Time{}
+	}
+	return deadline
+}
+
+// resumeLockTimer locks the wallet at deadline, as paused by pauseLockTimer,
+// unless deadline is zero or the wallet has been unlocked with a new timeout
+// since.
+func (wallet *Wallet) resumeLockTimer(deadline time.Time) {
+	if deadline.IsZero() {
+		return
+	}
+
+	wallet.lockTimerMu.Lock()
+	defer wallet.lockTimerMu.Unlock()
+
+	if wallet.lockTimer == nil {
+		wallet.startLockTimer(deadline)
+	}
+}
+
+// startLockTimer locks the wallet at deadline. lockTimerMu must be held.
+func (wallet *Wallet) startLockTimer(deadline time.Time) {
+	wallet.lockDeadline = deadline
+	wallet.lockTimer = time.AfterFunc(time.Until(deadline), func() {
+		log.Infof("[%d] Unlock timeout expired, locking wallet", wallet.ID)
+		wallet.LockWallet()
+	})
+}
+
+func (wallet *Wallet) stopLockTimer() {
+	wallet.lockTimerMu.Lock()
+	defer wallet.lockTimerMu.Unlock()
+
+	if wallet.lockTimer != nil {
+		wallet.lockTimer.Stop()
+		wallet.lockTimer = nil
+		wallet.lockDeadline = time.Time{}
+	}
+}
+
+// LockWallet locks the wallet if it is loaded and unlocked. Locking is
+// skipped while the account mixer is running.
 func (wallet *Wallet) LockWallet() {
+	loadedWallet, ok := wallet.loader.LoadedWallet()
+	if !ok {
+		return
+	}
+
 	if wallet.IsAccountMixerActive() {
 		log.Error("LockWallet ignored due to active account mixer")
 		return
 	}
 
-	if !wallet.Internal().Locked() {
-		wallet.Internal().Lock()
+	wallet.stopLockTimer()
+
+	if !loadedWallet.Locked() {
+		loadedWallet.Lock()
+		wallet.notifyLockStateChanged(true)
 	}
 }
 
+// IsLocked returns true if the wallet is locked or not loaded.
 func (wallet *Wallet) IsLocked() bool {
-	return wallet.Internal().Locked()
+	loadedWallet, ok := wallet.loader.LoadedWallet()
+	if !ok {
+		return true
+	}
+	return loadedWallet.Locked()
+}
+
+func (wallet *Wallet) notifyLockStateChanged(locked bool) {
+	if wallet.lockStateChanged != nil {
+		wallet.lockStateChanged(wallet.ID, locked)
+	}
 }
 
 func (wallet *Wallet) changePrivatePassphrase(oldPass []byte, newPass []byte) error {
@@ -276,9 +700,16 @@ func (wallet *Wallet) deleteWallet(privatePassphrase []byte) error {
 	}
 
 	wallet.Shutdown()
+	wallet.walletDataDB = nil
 
 	log.Info("Deleting Wallet")
-	return os.RemoveAll(wallet.dataDir)
+	err := os.RemoveAll(wallet.dataDir)
+	if err != nil {
+		log.Errorf("Error deleting wallet data directory: %v", err)
+		return err
+	}
+
+	return nil
 }
 
 // DecryptSeed decrypts wallet.EncryptedSeed using privatePassphrase
@@ -10,6 +10,13 @@ const (
 	AccountMixerMixedAccount   = "account_mixer_mixed_account"
 	AccountMixerUnmixedAccount = "account_mixer_unmixed_account"
 	AccountMixerMixTxChange    = "account_mixer_mix_tx_change"
+	AccountMixerCSPPServer     = "account_mixer_cspp_server"
+
+	WalletBirthdayConfigKey = "wallet_birthday"
+	LockedOutputsConfigKey  = "locked_outputs"
+	VSPHostConfigKey        = "vsp_host"
+	ForceFullSyncConfigKey  = "force_full_sync"
+	GapLimitConfigKey       = "gap_limit"
 )
 
 func (wallet *Wallet) SaveUserConfigValue(key string, value interface{}) {
@@ -37,6 +44,18 @@ func (wallet *Wallet) ReadUserConfigValue(key string, valueOut interface{}) erro
 	return err
 }
 
+func (wallet *Wallet) DeleteUserConfigValueForKey(key string) {
+	if wallet.deleteUserConfigValue == nil {
+		log.Errorf("call wallet.prepare before deleting wallet config values")
+		return
+	}
+
+	err := wallet.deleteUserConfigValue(key)
+	if err != nil && err != storm.ErrNotFound {
+		log.Errorf("error deleting config value for key: %s, error: %v", key, err)
+	}
+}
+
 func (wallet *Wallet) SetBoolConfigValueForKey(key string, value bool) {
 	wallet.SaveUserConfigValue(key, value)
 }
@@ -0,0 +1,224 @@
+package walletdata
+
+import (
+	"reflect"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	bolt "go.etcd.io/bbolt"
+)
+
+// TxAddressesBucketName is the bucket for the hashes of the saved
+// transactions involving each address, keyed by address. It is updated as
+// transactions are saved from the Addresses field of the records.
+const TxAddressesBucketName = "TxAddresses"
+
+// TxHashesForAddress returns the hashes of the saved transactions involving
+// address, in the order they were first saved.
+func (db *DB) TxHashesForAddress(address string) ([]string, error) {
+	return readTxHashes(db.walletDataDB, address)
+}
+
+// ReadForAddress returns the saved transactions involving address, newest
+// first. The transactions are read from the addresses bucket rather than by
+// scanning all saved transactions.
+func (db *DB) ReadForAddress(address string, transactions interface{}) error {
+	find := func(to interface{}) error {
+		return readTxsForAddress(db.walletDataDB, address, to)
+	}
+	return db.readIndexed(find, q.True(), 0, 0, true, transactions)
+}
+
+func readTxHashes(node storm.Node, address string) ([]string, error) {
+	var txHashes []string
+	err := node.Get(TxAddressesBucketName, address, &txHashes)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+	return txHashes, nil
+}
+
+// readTxsForAddress appends the saved transactions involving address to the
+// transactions slice pointed to by to.
+func readTxsForAddress(node storm.Node, address string, to interface{}) error {
+	txHashes, err := readTxHashes(node, address)
+	if err != nil {
+		return err
+	}
+
+	results := reflect.ValueOf(to).Elem()
+	for _, txHash := range txHashes {
+		record := reflect.New(results.Type().Elem())
+		err = node.One("Hash", txHash, record.Interface())
+		if err == storm.ErrNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+		results.Set(reflect.Append(results, record.Elem()))
+	}
+	return nil
+}
+
+// updateTxAddresses removes the hash of oldRecord, if not nil, from the
+// addresses bucket and adds the hash of newRecord, if not nil.
+func updateTxAddresses(node storm.Node, oldRecord, newRecord interface{}) error {
+	if oldRecord != nil {
+		txHash, addresses := recordAddresses(oldRecord)
+		for _, address := range addresses {
+			if err := removeTxHash(node, address, txHash); err != nil {
+				return err
+			}
+		}
+	}
+
+	if newRecord != nil {
+		txHash, addresses := recordAddresses(newRecord)
+		for _, address := range addresses {
+			if err := addTxHash(node, address, txHash); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// recordAddresses returns the hash and the Addresses field of record, which
+// has no addresses if the field does not exist.
+func recordAddresses(record interface{}) (txHash string, addresses []string) {
+	v := reflect.Indirect(reflect.ValueOf(record))
+	txHash = v.FieldByName("Hash").String()
+	if field := v.FieldByName("Addresses"); field.IsValid() {
+		addresses, _ = field.Interface().([]string)
+	}
+	return txHash, addresses
+}
+
+func addTxHash(node storm.Node, address, txHash string) error {
+	txHashes, err := readTxHashes(node, address)
+	if err != nil {
+		return err
+	}
+
+	for _, savedHash := range txHashes {
+		if savedHash == txHash {
+			return nil
+		}
+	}
+	return node.Set(TxAddressesBucketName, address, append(txHashes, txHash))
+}
+
+func removeTxHash(node storm.Node, address, txHash string) error {
+	txHashes, err := readTxHashes(node, address)
+	if err != nil {
+		return err
+	}
+
+	for i, savedHash := range txHashes {
+		if savedHash != txHash {
+			continue
+		}
+
+		txHashes = append(txHashes[:i], txHashes[i+1:]...)
+		if len(txHashes) == 0 {
+			return node.Delete(TxAddressesBucketName, address)
+		}
+		return node.Set(TxAddressesBucketName, address, txHashes)
+	}
+	return nil
+}
+
+// clearTxAddresses deletes the addresses bucket, for use when the
+// transactions are deleted.
+func clearTxAddresses(node storm.Node) error {
+	err := node.Drop(TxAddressesBucketName)
+	if err != nil && err != bolt.ErrBucketNotFound {
+		return err
+	}
+	return nil
+}
+
+// buildTxAddresses sets the Addresses of the saved transactions, for dbs
+// created before the addresses were kept, and saves them to the addresses
+// bucket. The addresses are those of the outputs of each transaction and of
+// the outputs spent by its wallet inputs, which are read from the saved
+// transactions that created them.
+func buildTxAddresses(node storm.Node, txData interface{}) error {
+	txType := reflect.TypeOf(txData).Elem()
+	if _, ok := txType.FieldByName("Addresses"); !ok {
+		return nil
+	}
+
+	records := reflect.New(reflect.SliceOf(txType))
+	err := node.All(records.Interface())
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	outputAddresses := make(map[string]map[int64]string)
+	for i := 0; i < records.Elem().Len(); i++ {
+		record := records.Elem().Index(i)
+		addresses := make(map[int64]string)
+		forEachElem(record.FieldByName("Outputs"), func(output reflect.Value) {
+			addresses[output.FieldByName("Index").Int()] = output.FieldByName("Address").String()
+		})
+		outputAddresses[record.FieldByName("Hash").String()] = addresses
+	}
+
+	for i := 0; i < records.Elem().Len(); i++ {
+		record := records.Elem().Index(i)
+
+		var addresses []string
+		forEachElem(record.FieldByName("Outputs"), func(output reflect.Value) {
+			addresses = appendAddress(addresses, output.FieldByName("Address").String())
+		})
+		forEachElem(record.FieldByName("Inputs"), func(input reflect.Value) {
+			if input.FieldByName("AccountNumber").Int() == -1 {
+				return
+			}
+			prevOutputs := outputAddresses[input.FieldByName("PreviousTransactionHash").String()]
+			addresses = appendAddress(addresses, prevOutputs[input.FieldByName("PreviousTransactionIndex").Int()])
+		})
+		record.FieldByName("Addresses").Set(reflect.ValueOf(addresses))
+
+		err = node.Save(record.Addr().Interface())
+		if err != nil {
+			return err
+		}
+		err = updateTxAddresses(node, nil, record.Addr().Interface())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// forEachElem calls fn with each element of slice, dereferencing pointers and
+// skipping nil elements.
+func forEachElem(slice reflect.Value, fn func(elem reflect.Value)) {
+	if !slice.IsValid() || slice.Kind() != reflect.Slice {
+		return
+	}
+	for i := 0; i < slice.Len(); i++ {
+		elem := reflect.Indirect(slice.Index(i))
+		if elem.IsValid() {
+			fn(elem)
+		}
+	}
+}
+
+// appendAddress appends address to addresses unless it is empty or already
+// in addresses.
+func appendAddress(addresses []string, address string) []string {
+	if address == "" {
+		return addresses
+	}
+	for _, a := range addresses {
+		if a == address {
+			return addresses
+		}
+	}
+	return append(addresses, address)
+}
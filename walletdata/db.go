@@ -2,6 +2,7 @@ package walletdata
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/asdine/storm"
@@ -16,11 +17,54 @@ const (
 	TxBucketName = "TxIndexInfo"
 	KeyDbVersion = "DbVersion"
 
+	// TxNotesBucketName is the bucket for user notes on transactions. It is
+	// not cleared when transactions are reindexed.
+	TxNotesBucketName = "TxNotes"
+
+	// TxFirstSeenBucketName is the bucket for the time each transaction was
+	// first saved. It is not cleared when transactions are reindexed, so the
+	// time is kept when the tx index is rebuilt.
+	TxFirstSeenBucketName = "TxFirstSeen"
+
 	// TxDbVersion is necessary to force re-indexing if changes are made to the structure of data being stored.
 	// Increment this version number if db structure changes such that client apps need to re-index.
-	TxDbVersion uint32 = 3
+	// Add a migration for the previous version if the db can be upgraded in place instead.
+	TxDbVersion uint32 = 9
 )
 
+// migration upgrades a wallet data database by one version. It is run in
+// a single database transaction along with the version number update.
+type migration func(node storm.Node, txData interface{}) error
+
+// migrations maps a db version to the migration that upgrades a db from that
+// version to the next. Databases at a version without a migration are cleared
+// and their transactions reindexed.
+var migrations = map[uint32]migration{
+	// Version 4 indexes transactions by block hash.
+	3: reindexTxData,
+	// Version 5 reclassifies the direction of transactions, there is no
+	// migration so that all transactions are reindexed.
+	// Version 6 computes fees from the wallet's debits, there is no
+	// migration so that the fees of all transactions are recomputed.
+	// Version 7 computes vote rewards from the ticket price, there is no
+	// migration so that the rewards of all votes are recomputed.
+	// Version 8 keeps aggregate stats of the saved transactions.
+	7: buildTxStats,
+	// Version 9 keeps the addresses involved in each transaction.
+	8: buildTxAddresses,
+}
+
+// reindexTxData rebuilds the storm indexes for txData, for use when the
+// index tags of txData change.
+func reindexTxData(node storm.Node, txData interface{}) error {
+	err := node.ReIndex(txData)
+	if err == storm.ErrNotFound {
+		// No transactions have been saved yet.
+		return nil
+	}
+	return err
+}
+
 type DB struct {
 	walletDataDB *storm.DB
 	chainParams  *chaincfg.Params
@@ -37,14 +81,16 @@ func Initialize(dbPath string, chainParams *chaincfg.Params, txData interface{})
 		return nil, err
 	}
 
-	walletDataDB, err = ensureTxDatabaseVersion(walletDataDB, dbPath, txData)
+	err = ensureTxDatabaseVersion(walletDataDB, txData)
 	if err != nil {
+		walletDataDB.Close()
 		return nil, err
 	}
 
 	// init bucket for saving/reading transaction objects
 	err = walletDataDB.Init(txData)
 	if err != nil {
+		walletDataDB.Close()
 		return nil, fmt.Errorf("error initializing tx bucket for wallet: %s", err.Error())
 	}
 
@@ -55,6 +101,15 @@ func Initialize(dbPath string, chainParams *chaincfg.Params, txData interface{})
 	}, nil
 }
 
+// Copy writes a copy of the database to w from a single read transaction, so
+// the copy is consistent even if the database is written to meanwhile.
+func (db *DB) Copy(w io.Writer) error {
+	return db.walletDataDB.Bolt.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
 func openOrCreateDB(dbPath string) (*storm.DB, error) {
 	var isNewDbFile bool
 
@@ -81,6 +136,7 @@ func openOrCreateDB(dbPath string) (*storm.DB, error) {
 	if isNewDbFile {
 		err = walletDataDB.Set(TxBucketName, KeyDbVersion, TxDbVersion)
 		if err != nil {
+			walletDataDB.Close()
 			os.RemoveAll(dbPath)
 			return nil, fmt.Errorf("error initializing wallet data db: %s", err.Error())
 		}
@@ -90,26 +146,78 @@ func openOrCreateDB(dbPath string) (*storm.DB, error) {
 }
 
 // ensureTxDatabaseVersion checks the version of the existing db against `TxDbVersion`.
-// If there's a difference, the current wallet data db file is deleted and a new one created.
-func ensureTxDatabaseVersion(walletDataDB *storm.DB, dbPath string, txData interface{}) (*storm.DB, error) {
+// Older dbs are upgraded using the registered migrations, the saved transactions are
+// deleted if there is no migration for a version. Dbs created by newer versions of
+// this package are rejected. The db is left open, the caller closes it on error.
+func ensureTxDatabaseVersion(walletDataDB *storm.DB, txData interface{}) error {
 	var currentDbVersion uint32
 	err := walletDataDB.Get(TxBucketName, KeyDbVersion, &currentDbVersion)
 	if err != nil && err != storm.ErrNotFound {
 		// ignore key not found errors as earlier db versions did not set a version number in the db.
-		return nil, fmt.Errorf("error checking wallet data database version: %s", err.Error())
+		return fmt.Errorf("error checking wallet data database version: %s", err.Error())
+	}
+
+	if currentDbVersion > TxDbVersion {
+		return fmt.Errorf("wallet data database version %d is newer than the supported version %d",
+			currentDbVersion, TxDbVersion)
 	}
 
-	if currentDbVersion != TxDbVersion {
-		if err = walletDataDB.Drop(txData); err != nil {
-			return nil, fmt.Errorf("error deleting outdated wallet data database: %s", err.Error())
+	for currentDbVersion < TxDbVersion {
+		migrate, ok := migrations[currentDbVersion]
+		if !ok {
+			return clearTxData(walletDataDB, txData)
 		}
 
-		if err = walletDataDB.Set(TxBucketName, KeyDbVersion, TxDbVersion); err != nil {
-			return nil, fmt.Errorf("error updating tx db version: %s", err.Error())
+		if err = runMigration(walletDataDB, migrate, currentDbVersion, txData); err != nil {
+			return err
 		}
+		currentDbVersion++
+	}
 
-		return walletDataDB, walletDataDB.Set(TxBucketName, KeyEndBlock, 0) // reset tx index
+	return nil
+}
+
+// runMigration upgrades the db from version to version+1.
+func runMigration(walletDataDB *storm.DB, migrate migration, version uint32, txData interface{}) error {
+	tx, err := walletDataDB.Begin(true)
+	if err != nil {
+		return fmt.Errorf("error starting wallet data database migration: %s", err.Error())
 	}
+	defer tx.Rollback()
 
-	return walletDataDB, nil
+	if err = migrate(tx, txData); err != nil {
+		return fmt.Errorf("error migrating wallet data database from version %d: %s", version, err.Error())
+	}
+
+	if err = tx.Set(TxBucketName, KeyDbVersion, version+1); err != nil {
+		return fmt.Errorf("error updating tx db version: %s", err.Error())
+	}
+
+	return tx.Commit()
+}
+
+// clearTxData deletes the saved transactions and resets the tx index so
+// that all transactions are indexed again.
+func clearTxData(walletDataDB *storm.DB, txData interface{}) error {
+	if err := keepFirstSeenTimes(walletDataDB, txData); err != nil {
+		return fmt.Errorf("error saving transaction first seen times: %s", err.Error())
+	}
+
+	if err := walletDataDB.Drop(txData); err != nil && err != storm.ErrNotFound {
+		return fmt.Errorf("error deleting outdated wallet data database: %s", err.Error())
+	}
+
+	if err := clearTxStats(walletDataDB); err != nil {
+		return fmt.Errorf("error deleting outdated wallet data stats: %s", err.Error())
+	}
+
+	if err := clearTxAddresses(walletDataDB); err != nil {
+		return fmt.Errorf("error deleting outdated wallet data addresses: %s", err.Error())
+	}
+
+	if err := walletDataDB.Set(TxBucketName, KeyDbVersion, TxDbVersion); err != nil {
+		return fmt.Errorf("error updating tx db version: %s", err.Error())
+	}
+
+	return walletDataDB.Set(TxBucketName, KeyEndBlock, 0) // reset tx index
 }
@@ -0,0 +1,475 @@
+package walletdata
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/decred/dcrd/chaincfg/v3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/planetdecred/dcrlibwallet/txhelper"
+	bolt "go.etcd.io/bbolt"
+)
+
+type testTx struct {
+	Hash        string `storm:"id,unique"`
+	Type        string `storm:"index"`
+	BlockHash   string `storm:"index"`
+	BlockHeight int32  `storm:"index"`
+	Timestamp   int64  `storm:"index"`
+	FirstSeen   int64
+	Inputs      []*testTxInput
+	Outputs     []*testTxOutput
+	Addresses   []string
+}
+
+type testTxInput struct {
+	PreviousTransactionHash  string
+	PreviousTransactionIndex int32
+	AccountNumber            int32
+}
+
+type testTxOutput struct {
+	Index   int32
+	Address string
+}
+
+// createDbAtVersion creates a wallet data db with the provided version and
+// transactions, as an older version of this package would have.
+func createDbAtVersion(dbPath string, version uint32, txs ...*testTx) {
+	db, err := storm.Open(dbPath)
+	Expect(err).To(BeNil())
+	defer db.Close()
+
+	Expect(db.Set(TxBucketName, KeyDbVersion, version)).To(Succeed())
+	for _, tx := range txs {
+		Expect(db.Save(tx)).To(Succeed())
+	}
+}
+
+// hashes returns the hashes of txs.
+func hashes(txs []testTx) []string {
+	result := make([]string, len(txs))
+	for i, tx := range txs {
+		result[i] = tx.Hash
+	}
+	return result
+}
+
+var _ = Describe("DB", func() {
+	var dbPath string
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "walletdata")
+		Expect(err).To(BeNil())
+		dbPath = filepath.Join(dir, DbName)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(filepath.Dir(dbPath))
+	})
+
+	Context("Initialize", func() {
+		It("migrates a version 3 db without deleting transactions", func() {
+			createDbAtVersion(dbPath, 3, &testTx{Hash: "tx1", BlockHash: "block1", BlockHeight: 1, Timestamp: 1})
+
+			db, err := storm.Open(dbPath)
+			Expect(err).To(BeNil())
+			Expect(runMigration(db, migrations[3], 3, &testTx{})).To(Succeed())
+
+			var version uint32
+			Expect(db.Get(TxBucketName, KeyDbVersion, &version)).To(Succeed())
+			Expect(version).To(Equal(uint32(4)))
+
+			var txs []testTx
+			Expect(db.Find("BlockHash", "block1", &txs)).To(Succeed())
+			Expect(txs).To(HaveLen(1))
+			Expect(txs[0].Hash).To(Equal("tx1"))
+			Expect(db.Close()).To(Succeed())
+		})
+
+		It("clears a version 4 db to reclassify transactions", func() {
+			createDbAtVersion(dbPath, 4, &testTx{Hash: "tx1", BlockHash: "block1", BlockHeight: 1, Timestamp: 1})
+
+			db, err := Initialize(dbPath, chaincfg.TestNet3Params(), &testTx{})
+			Expect(err).To(BeNil())
+			defer db.Close()
+
+			var txs []testTx
+			Expect(db.FindAll("BlockHash", "block1", &txs)).To(Equal(storm.ErrNotFound))
+		})
+
+		It("clears transactions in a db without a migration", func() {
+			createDbAtVersion(dbPath, 2, &testTx{Hash: "tx1", BlockHash: "block1", BlockHeight: 1, Timestamp: 1})
+
+			db, err := Initialize(dbPath, chaincfg.TestNet3Params(), &testTx{})
+			Expect(err).To(BeNil())
+			defer db.Close()
+
+			var txs []testTx
+			Expect(db.FindAll("BlockHash", "block1", &txs)).To(Equal(storm.ErrNotFound))
+		})
+
+		It("rejects a db from a newer version", func() {
+			createDbAtVersion(dbPath, TxDbVersion+1)
+
+			_, err := Initialize(dbPath, chaincfg.TestNet3Params(), &testTx{})
+			Expect(err).ToNot(BeNil())
+
+			// The rejected db is closed, so it can be opened again.
+			db, err := storm.Open(dbPath, storm.BoltOptions(0600, &bolt.Options{Timeout: time.Second}))
+			Expect(err).To(BeNil())
+			Expect(db.Close()).To(Succeed())
+		})
+
+		It("closes the db when a migration fails", func() {
+			createDbAtVersion(dbPath, 7, &testTx{Hash: "tx1", BlockHeight: 1, Timestamp: 1})
+
+			db, err := storm.Open(dbPath)
+			Expect(err).To(BeNil())
+			Expect(db.Bolt.Update(func(tx *bolt.Tx) error {
+				return tx.Bucket([]byte("testTx")).Put([]byte("tx2"), []byte(`{"Hash":"tx2","Timestamp":"1"}`))
+			})).To(Succeed())
+			Expect(db.Close()).To(Succeed())
+
+			_, err = Initialize(dbPath, chaincfg.TestNet3Params(), &testTx{})
+			Expect(err).ToNot(BeNil())
+
+			db, err = storm.Open(dbPath, storm.BoltOptions(0600, &bolt.Options{Timeout: time.Second}))
+			Expect(err).To(BeNil())
+			Expect(db.Close()).To(Succeed())
+		})
+	})
+
+	Context("ReadContext", func() {
+		It("stops reading when the context is canceled", func() {
+			createDbAtVersion(dbPath, TxDbVersion, &testTx{Hash: "tx1", BlockHash: "block1", BlockHeight: 1, Timestamp: 1})
+
+			db, err := Initialize(dbPath, chaincfg.TestNet3Params(), &testTx{})
+			Expect(err).To(BeNil())
+			defer db.Close()
+
+			var txs []testTx
+			Expect(db.ReadContext(context.Background(), 0, 0, TxFilterAll, true, 0, 1, &txs)).To(Succeed())
+			Expect(txs).To(HaveLen(1))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			Expect(db.ReadContext(ctx, 0, 0, TxFilterAll, true, 0, 1, &txs)).To(Equal(context.Canceled))
+		})
+
+		It("pages through the mined and unmined transactions in order", func() {
+			createDbAtVersion(dbPath, TxDbVersion,
+				&testTx{Hash: "tx1", Type: txhelper.TxTypeRegular, BlockHeight: 1, Timestamp: 100},
+				&testTx{Hash: "tx2", Type: txhelper.TxTypeVote, BlockHeight: 2, Timestamp: 200},
+				&testTx{Hash: "tx3", Type: txhelper.TxTypeRegular, BlockHeight: 3, Timestamp: 300},
+				&testTx{Hash: "tx4", Type: txhelper.TxTypeRegular, BlockHeight: -1, Timestamp: 400},
+				&testTx{Hash: "tx5", Type: txhelper.TxTypeRegular, BlockHeight: -1, Timestamp: 350})
+
+			db, err := Initialize(dbPath, chaincfg.TestNet3Params(), &testTx{})
+			Expect(err).To(BeNil())
+			defer db.Close()
+
+			var txs []testTx
+			Expect(db.Read(0, 0, TxFilterAll, true, 0, 3, &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"tx4", "tx5", "tx3", "tx2", "tx1"}))
+			Expect(db.Read(1, 2, TxFilterAll, true, 0, 3, &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"tx5", "tx3"}))
+			Expect(db.Read(2, 2, TxFilterAll, false, 0, 3, &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"tx3", "tx5"}))
+			Expect(db.Read(1, 2, TxFilterRegular, true, 0, 3, &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"tx5", "tx3"}))
+			Expect(db.Read(5, 2, TxFilterAll, true, 0, 3, &txs)).To(Succeed())
+			Expect(txs).To(BeEmpty())
+		})
+	})
+
+	Context("ReadWithQuery and ReadSince", func() {
+		var db *DB
+
+		BeforeEach(func() {
+			createDbAtVersion(dbPath, TxDbVersion,
+				&testTx{Hash: "tx1", Type: "regular", BlockHeight: 1, Timestamp: 100},
+				&testTx{Hash: "tx2", Type: "vote", BlockHeight: 2, Timestamp: 200},
+				&testTx{Hash: "tx3", Type: "regular", BlockHeight: 3, Timestamp: 300},
+				&testTx{Hash: "tx4", Type: "regular", BlockHeight: -1, Timestamp: 400})
+
+			var err error
+			db, err = Initialize(dbPath, chaincfg.TestNet3Params(), &testTx{})
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			db.Close()
+		})
+
+		It("reads the transactions matching all criteria in order", func() {
+			var txs []testTx
+			query := &TxQuery{Direction: -1, Type: "regular"}
+			Expect(db.ReadWithQuery(query, 0, 0, true, &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"tx4", "tx3", "tx1"}))
+
+			query = &TxQuery{Direction: -1, Type: "regular", MaxHeight: 2}
+			Expect(db.ReadWithQuery(query, 0, 0, true, &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"tx1"}))
+
+			query = &TxQuery{Direction: -1, MinHeight: 2}
+			Expect(db.ReadWithQuery(query, 1, 1, false, &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"tx3"}))
+
+			query = &TxQuery{Direction: -1, MinTimestamp: 250}
+			Expect(db.ReadWithQuery(query, 0, 0, true, &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"tx4", "tx3"}))
+		})
+
+		It("reads the transactions since a height or timestamp and the unmined transactions", func() {
+			var txs []testTx
+			Expect(db.ReadSince("BlockHeight", int32(2), &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"tx4", "tx3", "tx2"}))
+
+			Expect(db.ReadSince("Timestamp", int64(300), &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"tx4", "tx3"}))
+
+			Expect(db.ReadSince("BlockHeight", int32(10), &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"tx4"}))
+		})
+	})
+
+	Context("FirstSeen", func() {
+		It("keeps the first seen time when the transactions are cleared", func() {
+			db, err := Initialize(dbPath, chaincfg.TestNet3Params(), &testTx{})
+			Expect(err).To(BeNil())
+			defer db.Close()
+
+			_, err = db.SaveOrUpdate(&testTx{}, &testTx{Hash: "tx1", BlockHeight: -1, Timestamp: 10, FirstSeen: 10})
+			Expect(err).To(BeNil())
+			_, err = db.SaveOrUpdate(&testTx{}, &testTx{Hash: "tx1", BlockHeight: 1, Timestamp: 20, FirstSeen: 20})
+			Expect(err).To(BeNil())
+
+			Expect(db.ClearSavedTransactions(&testTx{})).To(Succeed())
+
+			_, err = db.SaveOrUpdate(&testTx{}, &testTx{Hash: "tx1", BlockHeight: 1, Timestamp: 20, FirstSeen: 30})
+			Expect(err).To(BeNil())
+
+			var tx testTx
+			Expect(db.FindOne("Hash", "tx1", &tx)).To(Succeed())
+			Expect(tx.FirstSeen).To(Equal(int64(10)))
+		})
+
+		It("keeps the first seen time of transactions saved by older versions", func() {
+			createDbAtVersion(dbPath, 4, &testTx{Hash: "tx1", BlockHeight: 1, Timestamp: 20, FirstSeen: 10})
+
+			db, err := Initialize(dbPath, chaincfg.TestNet3Params(), &testTx{})
+			Expect(err).To(BeNil())
+			defer db.Close()
+
+			_, err = db.SaveOrUpdate(&testTx{}, &testTx{Hash: "tx1", BlockHeight: 1, Timestamp: 20, FirstSeen: 30})
+			Expect(err).To(BeNil())
+
+			var tx testTx
+			Expect(db.FindOne("Hash", "tx1", &tx)).To(Succeed())
+			Expect(tx.FirstSeen).To(Equal(int64(10)))
+		})
+		It("skips saved transactions that cannot be decoded", func() {
+			createDbAtVersion(dbPath, 4, &testTx{Hash: "tx1", BlockHeight: 1, Timestamp: 20, FirstSeen: 10})
+
+			db, err := storm.Open(dbPath)
+			Expect(err).To(BeNil())
+			Expect(db.Bolt.Update(func(tx *bolt.Tx) error {
+				return tx.Bucket([]byte("testTx")).Put([]byte("tx2"), []byte(`{"Hash":"tx2","FirstSeen":"10"}`))
+			})).To(Succeed())
+			Expect(db.Close()).To(Succeed())
+
+			walletDataDB, err := Initialize(dbPath, chaincfg.TestNet3Params(), &testTx{})
+			Expect(err).To(BeNil())
+			defer walletDataDB.Close()
+
+			_, err = walletDataDB.SaveOrUpdate(&testTx{}, &testTx{Hash: "tx1", BlockHeight: 1, Timestamp: 20, FirstSeen: 30})
+			Expect(err).To(BeNil())
+
+			var tx testTx
+			Expect(walletDataDB.FindOne("Hash", "tx1", &tx)).To(Succeed())
+			Expect(tx.FirstSeen).To(Equal(int64(10)))
+		})
+
+		It("forgets the first seen time of deleted transactions", func() {
+			db, err := Initialize(dbPath, chaincfg.TestNet3Params(), &testTx{})
+			Expect(err).To(BeNil())
+			defer db.Close()
+
+			_, err = db.SaveOrUpdate(&testTx{}, &testTx{Hash: "tx1", BlockHeight: -1, Timestamp: 10, FirstSeen: 10})
+			Expect(err).To(BeNil())
+			Expect(db.DeleteTx(&testTx{}, "tx1")).To(Succeed())
+
+			_, err = db.SaveOrUpdate(&testTx{}, &testTx{Hash: "tx1", BlockHeight: 1, Timestamp: 20, FirstSeen: 20})
+			Expect(err).To(BeNil())
+
+			var tx testTx
+			Expect(db.FindOne("Hash", "tx1", &tx)).To(Succeed())
+			Expect(tx.FirstSeen).To(Equal(int64(20)))
+		})
+	})
+
+	Context("Search", func() {
+		It("finds transactions by hash prefix and by the addresses of a migrated db", func() {
+			createDbAtVersion(dbPath, 8,
+				&testTx{Hash: "ab01", BlockHeight: 1, Timestamp: 100, Outputs: []*testTxOutput{
+					{Index: 0, Address: "addr1"},
+					{Index: 1, Address: "addr2"},
+				}},
+				&testTx{Hash: "ab02", BlockHeight: 2, Timestamp: 200, Inputs: []*testTxInput{
+					{PreviousTransactionHash: "ab01", PreviousTransactionIndex: 1, AccountNumber: 0},
+					{PreviousTransactionHash: "cd01", PreviousTransactionIndex: 0, AccountNumber: -1},
+				}, Outputs: []*testTxOutput{
+					{Index: 0, Address: "addr3"},
+				}})
+
+			db, err := Initialize(dbPath, chaincfg.TestNet3Params(), &testTx{})
+			Expect(err).To(BeNil())
+			defer db.Close()
+
+			var txs []testTx
+			Expect(db.Search("AB0", 0, 0, &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"ab02", "ab01"}))
+			Expect(db.Search("ab01", 0, 0, &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"ab01"}))
+
+			By("Finding the transactions paying to and spending from an address")
+			Expect(db.Search("addr2", 0, 0, &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"ab02", "ab01"}))
+			Expect(db.Search("addr2", 1, 1, &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"ab01"}))
+			Expect(db.Search("addr1", 0, 0, &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"ab01"}))
+			Expect(db.Search("addr4", 0, 0, &txs)).To(Succeed())
+			Expect(txs).To(BeEmpty())
+
+			By("Updating the addresses as transactions are saved and deleted")
+			_, err = db.SaveOrUpdate(&testTx{}, &testTx{Hash: "ab01", BlockHeight: 1, Timestamp: 100, Addresses: []string{"addr4"}})
+			Expect(err).To(BeNil())
+			Expect(db.Search("addr1", 0, 0, &txs)).To(Succeed())
+			Expect(txs).To(BeEmpty())
+			Expect(db.Search("addr4", 0, 0, &txs)).To(Succeed())
+			Expect(hashes(txs)).To(Equal([]string{"ab01"}))
+
+			Expect(db.DeleteTx(&testTx{}, "ab02")).To(Succeed())
+			Expect(db.Search("addr3", 0, 0, &txs)).To(Succeed())
+			Expect(txs).To(BeEmpty())
+
+			Expect(db.ClearSavedTransactions(&testTx{})).To(Succeed())
+			addrTxHashes, err := db.TxHashesForAddress("addr4")
+			Expect(err).To(BeNil())
+			Expect(addrTxHashes).To(BeEmpty())
+		})
+	})
+
+	Context("TxStats", func() {
+		const jan, feb, mar = 1578000000, 1581000000, 1584000000 // 2020-01, 2020-02, 2020-03
+
+		It("updates the stats as transactions are saved and cleared", func() {
+			db, err := Initialize(dbPath, chaincfg.TestNet3Params(), &testTx{})
+			Expect(err).To(BeNil())
+			defer db.Close()
+
+			for _, tx := range []*testTx{
+				{Hash: "tx1", Type: "regular", Timestamp: jan},
+				{Hash: "tx2", Type: "vote", Timestamp: mar},
+				{Hash: "tx1", Type: "regular", Timestamp: feb},
+			} {
+				_, err = db.SaveOrUpdate(&testTx{}, tx)
+				Expect(err).To(BeNil())
+			}
+
+			stats, err := db.TxStats()
+			Expect(err).To(BeNil())
+			Expect(stats.Count).To(Equal(2))
+			Expect(stats.CountByType).To(Equal(map[string]int{"regular": 1, "vote": 1}))
+			Expect(stats.CountByMonth).To(Equal(map[string]int{"2020-02": 1, "2020-03": 1}))
+
+			earliest, latest, err := db.TimestampRange(&[]testTx{})
+			Expect(err).To(BeNil())
+			Expect(earliest).To(Equal(int64(feb)))
+			Expect(latest).To(Equal(int64(mar)))
+
+			Expect(db.ClearSavedTransactions(&testTx{})).To(Succeed())
+
+			stats, err = db.TxStats()
+			Expect(err).To(BeNil())
+			Expect(stats.Count).To(Equal(0))
+
+			earliest, latest, err = db.TimestampRange(&[]testTx{})
+			Expect(err).To(BeNil())
+			Expect(earliest).To(BeZero())
+			Expect(latest).To(BeZero())
+		})
+
+		It("builds the stats of a version 7 db", func() {
+			createDbAtVersion(dbPath, 7,
+				&testTx{Hash: "tx1", Type: "regular", Timestamp: jan},
+				&testTx{Hash: "tx2", Type: "regular", Timestamp: mar})
+
+			db, err := Initialize(dbPath, chaincfg.TestNet3Params(), &testTx{})
+			Expect(err).To(BeNil())
+			defer db.Close()
+
+			stats, err := db.TxStats()
+			Expect(err).To(BeNil())
+			Expect(stats.Count).To(Equal(2))
+			Expect(stats.CountByType).To(Equal(map[string]int{"regular": 2}))
+			Expect(stats.CountByMonth).To(HaveLen(2))
+		})
+	})
+})
+
+// BenchmarkSaveOrUpdateBatch measures saving the transactions of a wallet
+// with 20k transactions one per database transaction and in batches of 500,
+// as the tx index is built. Run with -benchtime=1x.
+func BenchmarkSaveOrUpdateBatch(b *testing.B) {
+	const txCount = 20000
+
+	for _, batchSize := range []int{1, 500} {
+		b.Run(fmt.Sprintf("BatchSize%d", batchSize), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				dir, err := ioutil.TempDir("", "walletdata")
+				if err != nil {
+					b.Fatal(err)
+				}
+				db, err := Initialize(filepath.Join(dir, DbName), chaincfg.TestNet3Params(), &testTx{})
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+
+				batch := make([]interface{}, 0, batchSize)
+				for n := 0; n < txCount; n++ {
+					batch = append(batch, &testTx{
+						Hash:        fmt.Sprintf("%064x", n),
+						Type:        "regular",
+						BlockHeight: int32(n / 10),
+						Timestamp:   int64(1600000000 + n*30),
+						Outputs:     []*testTxOutput{{Index: 0, Address: fmt.Sprintf("addr%d", n%1000)}},
+						Addresses:   []string{fmt.Sprintf("addr%d", n%1000)},
+					})
+					if len(batch) == batchSize || n == txCount-1 {
+						if err := db.SaveOrUpdateBatch(batch); err != nil {
+							b.Fatal(err)
+						}
+						batch = batch[:0]
+					}
+				}
+
+				b.StopTimer()
+				db.Close()
+				os.RemoveAll(dir)
+				b.StartTimer()
+			}
+		})
+	}
+}
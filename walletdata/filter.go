@@ -1,6 +1,8 @@
 package walletdata
 
 import (
+	"math"
+
 	"github.com/asdine/storm"
 	"github.com/asdine/storm/q"
 	"github.com/planetdecred/dcrlibwallet/txhelper"
@@ -24,7 +26,81 @@ const (
 	TxFilterTickets     int32 = 14
 )
 
+// TxQuery filters transactions by multiple criteria. Criteria with zero
+// values are ignored, except Direction which is ignored if it is set to
+// txhelper.TxDirectionInvalid. The transactions are read from the type index
+// if Type is set, otherwise from the block height, timestamp or direction
+// index, and only scanned if no criterion is set.
+type TxQuery struct {
+	Direction    int32  `json:"direction"`
+	Type         string `json:"type"`
+	MinHeight    int32  `json:"min_height"`
+	MaxHeight    int32  `json:"max_height"`
+	MinTimestamp int64  `json:"min_timestamp"`
+	MaxTimestamp int64  `json:"max_timestamp"`
+}
+
+func (txQuery *TxQuery) matcher() q.Matcher {
+	matchers := []q.Matcher{q.True()}
+	if txQuery.Direction != txhelper.TxDirectionInvalid {
+		matchers = append(matchers, q.Eq("Direction", txQuery.Direction))
+	}
+	if txQuery.Type != "" {
+		matchers = append(matchers, q.Eq("Type", txQuery.Type))
+	}
+	if txQuery.MinHeight > 0 {
+		matchers = append(matchers, q.Gte("BlockHeight", txQuery.MinHeight))
+	}
+	if txQuery.MaxHeight > 0 {
+		matchers = append(matchers, q.Lte("BlockHeight", txQuery.MaxHeight), q.Gt("BlockHeight", -1))
+	}
+	if txQuery.MinTimestamp > 0 {
+		matchers = append(matchers, q.Gte("Timestamp", txQuery.MinTimestamp))
+	}
+	if txQuery.MaxTimestamp > 0 {
+		matchers = append(matchers, q.Lte("Timestamp", txQuery.MaxTimestamp))
+	}
+	return q.And(matchers...)
+}
+
+// find reads the candidates for the transactions matched by txQuery from the
+// index of one of the criteria set.
+func (txQuery *TxQuery) find(node storm.Node, to interface{}) error {
+	switch {
+	case txQuery.Type != "":
+		return node.Find("Type", txQuery.Type, to)
+
+	case txQuery.MinHeight > 0 || txQuery.MaxHeight > 0:
+		// Unmined transactions, with a block height of -1, are above
+		// the range in the index and are left out as the matcher
+		// requires.
+		maxHeight := txQuery.MaxHeight
+		if maxHeight <= 0 {
+			maxHeight = math.MaxInt32
+		}
+		return node.Range("BlockHeight", txQuery.MinHeight, maxHeight, to)
+
+	case txQuery.MinTimestamp > 0 || txQuery.MaxTimestamp > 0:
+		maxTimestamp := txQuery.MaxTimestamp
+		if maxTimestamp <= 0 {
+			maxTimestamp = math.MaxInt64
+		}
+		return node.Range("Timestamp", txQuery.MinTimestamp, maxTimestamp, to)
+
+	case txQuery.Direction != txhelper.TxDirectionInvalid:
+		return node.Find("Direction", txQuery.Direction, to)
+
+	default:
+		return node.All(to)
+	}
+}
+
 func (db *DB) prepareTxQuery(txFilter, requiredConfirmations, bestBlock int32) (query storm.Query) {
+	return db.walletDataDB.Select(db.txFilterMatcher(txFilter, requiredConfirmations, bestBlock))
+}
+
+// txFilterMatcher returns a matcher for the transactions that match txFilter.
+func (db *DB) txFilterMatcher(txFilter, requiredConfirmations, bestBlock int32) (matcher q.Matcher) {
 	// tickets with block height less than this are matured.
 	maturityBlock := bestBlock - int32(db.chainParams.TicketMaturity)
 
@@ -33,22 +109,22 @@ func (db *DB) prepareTxQuery(txFilter, requiredConfirmations, bestBlock int32) (
 
 	switch txFilter {
 	case TxFilterSent:
-		query = db.walletDataDB.Select(
+		matcher = q.And(
 			q.Eq("Type", txhelper.TxTypeRegular),
 			q.Eq("Direction", txhelper.TxDirectionSent),
 		)
 	case TxFilterReceived:
-		query = db.walletDataDB.Select(
+		matcher = q.And(
 			q.Eq("Type", txhelper.TxTypeRegular),
 			q.Eq("Direction", txhelper.TxDirectionReceived),
 		)
 	case TxFilterTransferred:
-		query = db.walletDataDB.Select(
+		matcher = q.And(
 			q.Eq("Type", txhelper.TxTypeRegular),
 			q.Eq("Direction", txhelper.TxDirectionTransferred),
 		)
 	case TxFilterStaking:
-		query = db.walletDataDB.Select(
+		matcher = q.And(
 			q.Or(
 				q.Eq("Type", txhelper.TxTypeTicketPurchase),
 				q.Eq("Type", txhelper.TxTypeVote),
@@ -56,34 +132,34 @@ func (db *DB) prepareTxQuery(txFilter, requiredConfirmations, bestBlock int32) (
 			),
 		)
 	case TxFilterCoinBase:
-		query = db.walletDataDB.Select(
+		matcher = q.And(
 			q.Eq("Type", txhelper.TxTypeCoinBase),
 		)
 	case TxFilterRegular:
-		query = db.walletDataDB.Select(
+		matcher = q.And(
 			q.Eq("Type", txhelper.TxTypeRegular),
 		)
 	case TxFilterMixed:
-		query = db.walletDataDB.Select(
+		matcher = q.And(
 			q.Eq("Type", txhelper.TxTypeMixed),
 		)
 	case TxFilterVoted:
-		query = db.walletDataDB.Select(
+		matcher = q.And(
 			q.Eq("Type", txhelper.TxTypeVote),
 		)
 	case TxFilterRevoked:
-		query = db.walletDataDB.Select(
+		matcher = q.And(
 			q.Eq("Type", txhelper.TxTypeRevocation),
 		)
 	case TxFilterImmature:
-		query = db.walletDataDB.Select(
+		matcher = q.And(
 			q.Eq("Type", txhelper.TxTypeTicketPurchase),
 			q.And(
 				q.Gt("BlockHeight", maturityBlock),
 			),
 		)
 	case TxFilterLive:
-		query = db.walletDataDB.Select(
+		matcher = q.And(
 			q.Eq("Type", txhelper.TxTypeTicketPurchase),
 			q.Eq("TicketSpender", ""),           // not spent by a vote or revoke
 			q.Gt("BlockHeight", 0),              // mined
@@ -91,27 +167,25 @@ func (db *DB) prepareTxQuery(txFilter, requiredConfirmations, bestBlock int32) (
 			q.Gt("BlockHeight", expiryBlock),    // not expired
 		)
 	case TxFilterUnmined:
-		query = db.walletDataDB.Select(
+		matcher = q.And(
 			q.Eq("Type", txhelper.TxTypeTicketPurchase),
 			q.Or(
 				q.Eq("BlockHeight", -1),
 			),
 		)
 	case TxFilterExpired:
-		query = db.walletDataDB.Select(
+		matcher = q.And(
 			q.Eq("Type", txhelper.TxTypeTicketPurchase),
 			q.Eq("TicketSpender", ""), // not spent by a vote or revoke
 			q.Gt("BlockHeight", 0),    // mined
 			q.Lte("BlockHeight", expiryBlock),
 		)
 	case TxFilterTickets:
-		query = db.walletDataDB.Select(
+		matcher = q.And(
 			q.Eq("Type", txhelper.TxTypeTicketPurchase),
 		)
 	default:
-		query = db.walletDataDB.Select(
-			q.True(),
-		)
+		matcher = q.True()
 	}
 
 	return
@@ -1,12 +1,34 @@
 package walletdata
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
 	"github.com/asdine/storm"
 	"github.com/asdine/storm/q"
+	bolt "go.etcd.io/bbolt"
 )
 
 const MaxReOrgBlocks = 6
 
+// readBatchSize is the number of transactions read per db transaction by
+// ReadInBatches.
+const readBatchSize = 500
+
+// blockHeightIndex is the bucket of the storm index on the BlockHeight field
+// of the saved transactions. Its keys are the big-endian block height followed
+// by "__" and the hash, so a cursor over the bucket walks the transactions by
+// block height. The keys of unmined transactions, with a block height of -1,
+// start with unminedKeyPrefix or a greater byte and sort after the mined ones.
+const blockHeightIndex = "__storm_index_BlockHeight"
+
+var unminedKeyPrefix = []byte{0x80}
+
 // ReadIndexingStartBlock checks if the end block height was saved from last indexing operation.
 // If so, the end block height - MaxReOrgBlocks is returned.
 // Otherwise, 0 is returned to begin indexing from height 0.
@@ -27,24 +49,437 @@ func (db *DB) ReadIndexingStartBlock() (int32, error) {
 // Read queries the db for `limit` count transactions that match the specified `txFilter`
 // starting from the specified `offset`; and saves the transactions found to the received `transactions` object.
 // `transactions` should be a pointer to a slice of Transaction objects.
+// Transactions are ordered by block height and then timestamp, unmined
+// transactions are treated as the newest.
 func (db *DB) Read(offset, limit, txFilter int32, newestFirst bool, requiredConfirmations, bestBlock int32, transactions interface{}) error {
-	query := db.prepareTxQuery(txFilter, requiredConfirmations, bestBlock)
-	if offset > 0 {
-		query = query.Skip(int(offset))
+	return db.ReadContext(context.Background(), offset, limit, txFilter, newestFirst, requiredConfirmations, bestBlock, transactions)
+}
+
+// ReadContext is like Read but stops reading and returns ctx.Err() when ctx
+// is canceled. The query runs in a read-only db transaction, so canceling it
+// does not affect the saved transactions.
+func (db *DB) ReadContext(ctx context.Context, offset, limit, txFilter int32, newestFirst bool, requiredConfirmations, bestBlock int32, transactions interface{}) error {
+	matcher := db.txFilterMatcher(txFilter, requiredConfirmations, bestBlock)
+	return db.readMatching(ctx, matcher, txFilter == TxFilterAll, offset, limit, newestFirst, transactions)
+}
+
+// ReadInBatches reads the transactions that match txFilter, in the order of
+// Read, in batches of up to readBatchSize transactions. Each batch is read in
+// its own read-only db transaction and saved to transactions, a pointer to a
+// slice of transactions, before fn is called, so the saved transactions are
+// never all held in memory. Reading stops with the error returned by fn, or
+// with ctx.Err() when ctx is canceled.
+func (db *DB) ReadInBatches(ctx context.Context, txFilter int32, newestFirst bool, requiredConfirmations, bestBlock int32,
+	transactions interface{}, fn func() error) error {
+
+	matcher := db.txFilterMatcher(txFilter, requiredConfirmations, bestBlock)
+	results := reflect.ValueOf(transactions).Elem()
+	txType := results.Type().Elem()
+
+	readUnmined := func() error {
+		err := db.walletDataDB.Bolt.View(func(tx *bolt.Tx) error {
+			results.Set(reflect.MakeSlice(results.Type(), 0, 0))
+			records, index := db.txIndexBuckets(tx, txType)
+			if index == nil {
+				return nil
+			}
+
+			unmined, err := db.readUnminedTxs(records, index, txType, newestFirst)
+			if err != nil {
+				return err
+			}
+			for i := 0; i < unmined.Len(); i++ {
+				match, err := matcher.Match(unmined.Index(i).Addr().Interface())
+				if err != nil {
+					return err
+				}
+				if match {
+					results.Set(reflect.Append(results, unmined.Index(i)))
+				}
+			}
+			return nil
+		})
+		if err != nil || results.Len() == 0 {
+			return err
+		}
+		return fn()
 	}
-	if limit > 0 {
-		query = query.Limit(int(limit))
+
+	readMined := func() error {
+		var after []byte
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			var lastKey []byte
+			var full bool
+			err := db.walletDataDB.Bolt.View(func(tx *bolt.Tx) error {
+				results.Set(reflect.MakeSlice(results.Type(), 0, readBatchSize))
+				records, index := db.txIndexBuckets(tx, txType)
+				if index == nil {
+					return nil
+				}
+
+				return walkMinedTxs(index, after, newestFirst, func(key, hash []byte) (bool, error) {
+					record := reflect.New(txType)
+					err := db.walletDataDB.Codec().Unmarshal(records.Get(hash), record.Interface())
+					if err != nil {
+						return false, err
+					}
+					lastKey = append(lastKey[:0], key...)
+
+					match, err := matcher.Match(record.Interface())
+					if err != nil {
+						return false, err
+					}
+					if match {
+						results.Set(reflect.Append(results, record.Elem()))
+					}
+
+					full = results.Len() == readBatchSize
+					return !full, nil
+				})
+			})
+			if err != nil {
+				return err
+			}
+
+			if results.Len() > 0 {
+				if err := fn(); err != nil {
+					return err
+				}
+			}
+			if !full {
+				return nil
+			}
+			after = lastKey
+		}
 	}
+
+	first, second := readMined, readUnmined
 	if newestFirst {
-		query = query.OrderBy("Timestamp").Reverse()
-	} else {
-		query = query.OrderBy("Timestamp")
+		first, second = readUnmined, readMined
+	}
+	if err := first(); err != nil {
+		return err
+	}
+	return second()
+}
+
+// txIndexBuckets returns the bucket of the saved transactions of txType and
+// its block height index, or nils if no transactions were saved.
+func (db *DB) txIndexBuckets(tx *bolt.Tx, txType reflect.Type) (records, index *bolt.Bucket) {
+	records = db.walletDataDB.GetBucket(tx, txType.Name())
+	if records == nil {
+		return nil, nil
+	}
+	index = records.Bucket([]byte(blockHeightIndex))
+	if index == nil {
+		return nil, nil
 	}
+	return records, index
+}
 
-	err := query.Find(transactions)
+// walkMinedTxs calls visit with the index key and the hash of the mined
+// transactions in index, oldest or newest first, starting after the key after
+// if it is not nil, until visit returns false. Only the index keys are read,
+// the records of the transactions are left for visit to read. Transactions at
+// height 0 are not in the index since storm does not index zero values, but
+// the wallet has no transactions in the genesis block.
+func walkMinedTxs(index *bolt.Bucket, after []byte, newestFirst bool, visit func(key, hash []byte) (bool, error)) error {
+	cursor := index.Cursor()
+	next := cursor.Next
+
+	var key, hash []byte
+	switch {
+	case newestFirst:
+		// Start at the last key before after, or before the unmined
+		// transactions.
+		next = cursor.Prev
+		bound := after
+		if bound == nil {
+			bound = unminedKeyPrefix
+		}
+		if key, _ = cursor.Seek(bound); key == nil {
+			key, hash = cursor.Last()
+		} else {
+			key, hash = cursor.Prev()
+		}
+	case after != nil:
+		if key, hash = cursor.Seek(after); bytes.Equal(key, after) {
+			key, hash = cursor.Next()
+		}
+	default:
+		key, hash = cursor.First()
+	}
+
+	for ; key != nil; key, hash = next() {
+		if bytes.Compare(key, unminedKeyPrefix) >= 0 {
+			if newestFirst {
+				continue
+			}
+			return nil
+		}
+		if hash == nil {
+			continue // the nested bucket of the index
+		}
+
+		more, err := visit(key, hash)
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+// readUnminedTxs returns the unmined transactions in index, decoded into a
+// slice of txType and ordered by timestamp.
+func (db *DB) readUnminedTxs(records, index *bolt.Bucket, txType reflect.Type, newestFirst bool) (reflect.Value, error) {
+	unmined := reflect.MakeSlice(reflect.SliceOf(txType), 0, 0)
+	cursor := index.Cursor()
+	for key, hash := cursor.Seek(unminedKeyPrefix); key != nil; key, hash = cursor.Next() {
+		if hash == nil {
+			continue
+		}
+
+		record := reflect.New(txType)
+		err := db.walletDataDB.Codec().Unmarshal(records.Get(hash), record.Interface())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		unmined = reflect.Append(unmined, record.Elem())
+	}
+
+	sort.SliceStable(unmined.Interface(), func(i, j int) bool {
+		timestamp1 := unmined.Index(i).FieldByName("Timestamp").Int()
+		timestamp2 := unmined.Index(j).FieldByName("Timestamp").Int()
+		if newestFirst {
+			return timestamp1 > timestamp2
+		}
+		return timestamp1 < timestamp2
+	})
+	return unmined, nil
+}
+
+// ReadWithQuery is like Read but returns the transactions that match all the
+// criteria set in txQuery. The transactions are read from the index of one of
+// the criteria, see TxQuery.
+func (db *DB) ReadWithQuery(txQuery *TxQuery, offset, limit int32, newestFirst bool, transactions interface{}) error {
+	find := func(to interface{}) error {
+		return txQuery.find(db.walletDataDB, to)
+	}
+	return db.readIndexed(find, txQuery.matcher(), offset, limit, newestFirst, transactions)
+}
+
+// ReadSince returns all unmined transactions and the mined transactions with
+// a fieldName value that is not less than value, newest first. fieldName must
+// be an indexed int32 or int64 field, the transactions are read from its index
+// and the block height index for the unmined transactions.
+func (db *DB) ReadSince(fieldName string, value interface{}, transactions interface{}) error {
+	var max interface{}
+	switch value.(type) {
+	case int32:
+		max = int32(math.MaxInt32)
+	case int64:
+		max = int64(math.MaxInt64)
+	default:
+		return fmt.Errorf("unsupported %s value type %T", fieldName, value)
+	}
+
+	find := func(to interface{}) error {
+		err := db.walletDataDB.Range(fieldName, value, max, to)
+		if err != nil && err != storm.ErrNotFound {
+			return err
+		}
+
+		// Unmined transactions are read from the block height index
+		// below, drop the ones read from the fieldName index.
+		results := reflect.ValueOf(to).Elem()
+		mined := reflect.MakeSlice(results.Type(), 0, results.Len())
+		for i := 0; i < results.Len(); i++ {
+			if results.Index(i).FieldByName("BlockHeight").Int() != -1 {
+				mined = reflect.Append(mined, results.Index(i))
+			}
+		}
+
+		unmined := reflect.New(results.Type())
+		err = db.walletDataDB.Find("BlockHeight", int32(-1), unmined.Interface())
+		if err != nil && err != storm.ErrNotFound {
+			return err
+		}
+
+		results.Set(reflect.AppendSlice(mined, unmined.Elem()))
+		return nil
+	}
+	return db.readIndexed(find, q.True(), 0, 0, true, transactions)
+}
+
+// Search returns the transactions whose hash starts with query, which is
+// matched case-insensitively, or that involve the address in query, newest
+// first. The transactions are read from the hash index and the addresses
+// bucket rather than by scanning all saved transactions.
+func (db *DB) Search(query string, offset, limit int32, transactions interface{}) error {
+	find := func(to interface{}) error {
+		err := db.walletDataDB.Prefix("Hash", strings.ToLower(query), to)
+		if err != nil && err != storm.ErrNotFound {
+			return err
+		}
+		return readTxsForAddress(db.walletDataDB, query, to)
+	}
+	return db.readIndexed(find, q.True(), offset, limit, true, transactions)
+}
+
+// readIndexed reads the transactions returned by find, which reads them from
+// a storm index rather than scanning all saved transactions, and returns the
+// ones that match matcher in the same order and pages as readMatching.
+func (db *DB) readIndexed(find func(to interface{}) error, matcher q.Matcher, offset, limit int32, newestFirst bool, transactions interface{}) error {
+	results := reflect.ValueOf(transactions).Elem()
+	candidates := reflect.New(results.Type())
+	err := find(candidates.Interface())
 	if err != nil && err != storm.ErrNotFound {
 		return err
 	}
+
+	matched := reflect.MakeSlice(results.Type(), 0, candidates.Elem().Len())
+	for i := 0; i < candidates.Elem().Len(); i++ {
+		candidate := candidates.Elem().Index(i)
+		match, err := matcher.Match(candidate.Addr().Interface())
+		if err != nil {
+			return err
+		}
+		if match {
+			matched = reflect.Append(matched, candidate)
+		}
+	}
+
+	sort.SliceStable(matched.Interface(), func(i, j int) bool {
+		if newestFirst {
+			return txIsNewer(matched.Index(i), matched.Index(j))
+		}
+		return txIsNewer(matched.Index(j), matched.Index(i))
+	})
+
+	start, end := int(offset), matched.Len()
+	if start > end {
+		start = end
+	}
+	if limit > 0 && start+int(limit) < end {
+		end = start + int(limit)
+	}
+	results.Set(matched.Slice(start, end))
+	return nil
+}
+
+// txIsNewer orders transactions by block height and then timestamp, with
+// unmined transactions being the newest.
+func txIsNewer(tx1, tx2 reflect.Value) bool {
+	height1, height2 := tx1.FieldByName("BlockHeight").Int(), tx2.FieldByName("BlockHeight").Int()
+	if height1 != height2 {
+		if height1 == -1 || height2 == -1 {
+			return height1 == -1
+		}
+		return height1 > height2
+	}
+	return tx1.FieldByName("Timestamp").Int() > tx2.FieldByName("Timestamp").Int()
+}
+
+// readMatching reads the page of the transactions that match matcher at
+// offset, of up to limit transactions if limit is positive, in the order of
+// Read. The mined transactions are walked through the block height index with
+// a cursor in a single read-only db transaction, so only the transactions up
+// to the end of the page are read and only the ones on the page are kept.
+// If matchAll is true, the transactions before the page are skipped without
+// being decoded.
+func (db *DB) readMatching(ctx context.Context, matcher q.Matcher, matchAll bool, offset, limit int32, newestFirst bool,
+	transactions interface{}) error {
+
+	results := reflect.ValueOf(transactions).Elem()
+	txType := results.Type().Elem()
+	page := reflect.MakeSlice(results.Type(), 0, 0)
+	skip := int(offset)
+
+	pageFull := func() bool {
+		return limit > 0 && page.Len() == int(limit)
+	}
+
+	// take adds record, a pointer to a matching transaction, to the page
+	// unless it is before the page.
+	take := func(record reflect.Value) {
+		if skip > 0 {
+			skip--
+			return
+		}
+		page = reflect.Append(page, record.Elem())
+	}
+
+	err := db.walletDataDB.Bolt.View(func(tx *bolt.Tx) error {
+		records, index := db.txIndexBuckets(tx, txType)
+		if index == nil {
+			return nil
+		}
+
+		readUnmined := func() error {
+			unmined, err := db.readUnminedTxs(records, index, txType, newestFirst)
+			if err != nil {
+				return err
+			}
+
+			for i := 0; i < unmined.Len() && !pageFull(); i++ {
+				record := unmined.Index(i).Addr()
+				match, err := matcher.Match(record.Interface())
+				if err != nil {
+					return err
+				}
+				if match {
+					take(record)
+				}
+			}
+			return ctx.Err()
+		}
+
+		readMined := func() error {
+			return walkMinedTxs(index, nil, newestFirst, func(_, hash []byte) (bool, error) {
+				if err := ctx.Err(); err != nil {
+					return false, err
+				}
+
+				if matchAll && skip > 0 {
+					skip--
+					return true, nil
+				}
+
+				record := reflect.New(txType)
+				err := db.walletDataDB.Codec().Unmarshal(records.Get(hash), record.Interface())
+				if err != nil {
+					return false, err
+				}
+
+				match, err := matcher.Match(record.Interface())
+				if err != nil {
+					return false, err
+				}
+				if match {
+					take(record)
+				}
+				return !pageFull(), nil
+			})
+		}
+
+		first, second := readMined, readUnmined
+		if newestFirst {
+			first, second = readUnmined, readMined
+		}
+		if err := first(); err != nil || pageFull() {
+			return err
+		}
+		return second()
+	})
+	if err != nil {
+		return err
+	}
+
+	results.Set(page)
 	return nil
 }
 
@@ -61,6 +496,17 @@ func (db *DB) Count(txFilter int32, requiredConfirmations, bestBlock int32, txOb
 	return count, nil
 }
 
+// TxNote returns the note saved for the transaction with the provided hash
+// or an empty string if there is none.
+func (db *DB) TxNote(txHash string) (string, error) {
+	var note string
+	err := db.walletDataDB.Get(TxNotesBucketName, txHash, &note)
+	if err != nil && err != storm.ErrNotFound {
+		return "", err
+	}
+	return note, nil
+}
+
 func (db *DB) Find(matcher q.Matcher, transactions interface{}) error {
 	query := db.walletDataDB.Select(matcher)
 
@@ -6,6 +6,7 @@ import (
 
 	"decred.org/dcrwallet/v2/errors"
 	"github.com/asdine/storm"
+	bolt "go.etcd.io/bbolt"
 )
 
 const KeyEndBlock = "EndBlock"
@@ -13,9 +14,68 @@ const KeyEndBlock = "EndBlock"
 // SaveOrUpdate saves a transaction to the database and would overwrite
 // if a transaction with same hash exists
 func (db *DB) SaveOrUpdate(emptyTxPointer, record interface{}) (overwritten bool, err error) {
+	tx, err := db.walletDataDB.Begin(true)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	overwritten, err = saveOrUpdate(tx, emptyTxPointer, record)
+	if err != nil {
+		return false, err
+	}
+
+	return overwritten, tx.Commit()
+}
+
+// SaveOrUpdateBatch is like SaveOrUpdate but saves all the records, which
+// must be pointers to transactions, in a single database transaction.
+// The TicketSpender of previously saved records is kept if it isn't set
+// in the new record, since votes and revocations update the saved ticket
+// while the batch is being prepared.
+func (db *DB) SaveOrUpdateBatch(records []interface{}) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	tx, err := db.walletDataDB.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The previously saved records are read into a single value that is
+	// reused for all records, rather than allocating one per record.
+	var oldRecord reflect.Value
+	for _, record := range records {
+		recordValue := reflect.Indirect(reflect.ValueOf(record))
+		if !oldRecord.IsValid() || oldRecord.Elem().Type() != recordValue.Type() {
+			oldRecord = reflect.New(recordValue.Type())
+		} else {
+			oldRecord.Elem().Set(reflect.Zero(recordValue.Type()))
+		}
+
+		ticketSpender := recordValue.FieldByName("TicketSpender")
+		if ticketSpender.IsValid() && ticketSpender.String() == "" {
+			err = tx.One("Hash", recordValue.FieldByName("Hash").String(), oldRecord.Interface())
+			if err == nil {
+				ticketSpender.SetString(oldRecord.Elem().FieldByName("TicketSpender").String())
+			}
+		}
+
+		_, err = saveOrUpdate(tx, oldRecord.Interface(), record)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func saveOrUpdate(node storm.Node, emptyTxPointer, record interface{}) (overwritten bool, err error) {
 	v := reflect.ValueOf(record)
 	txHash := reflect.Indirect(v).FieldByName("Hash").String()
-	err = db.walletDataDB.One("Hash", txHash, emptyTxPointer)
+	err = node.One("Hash", txHash, emptyTxPointer)
 	if err != nil && err != storm.ErrNotFound {
 		err = errors.Errorf("error checking if record was already indexed: %s", err.Error())
 		return
@@ -25,14 +85,147 @@ func (db *DB) SaveOrUpdate(emptyTxPointer, record interface{}) (overwritten bool
 	timestamp := reflect.Indirect(v2).FieldByName("Timestamp").Int()
 	if timestamp > 0 {
 		overwritten = true
+
 		// delete old record before saving new (if it exists)
-		db.walletDataDB.DeleteStruct(emptyTxPointer)
+		node.DeleteStruct(emptyTxPointer)
 	}
 
-	err = db.walletDataDB.Save(record)
+	// keep the time the record was first saved
+	var oldFirstSeen int64
+	if overwritten {
+		oldFirstSeen = reflect.Indirect(v2).FieldByName("FirstSeen").Int()
+	}
+	err = keepFirstSeen(node, txHash, oldFirstSeen, reflect.Indirect(v).FieldByName("FirstSeen"))
+	if err != nil {
+		return
+	}
+
+	err = node.Save(record)
+	if err != nil {
+		return
+	}
+
+	var oldRecord interface{}
+	if overwritten {
+		oldRecord = emptyTxPointer
+	}
+	err = updateTxStats(node, oldRecord, record)
+	if err != nil {
+		return
+	}
+	err = updateTxAddresses(node, oldRecord, record)
 	return
 }
 
+// DeleteTx deletes the saved transaction with the provided hash, if any.
+func (db *DB) DeleteTx(emptyTxPointer interface{}, txHash string) error {
+	tx, err := db.walletDataDB.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.One("Hash", txHash, emptyTxPointer)
+	if err == storm.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	err = tx.DeleteStruct(emptyTxPointer)
+	if err != nil {
+		return err
+	}
+
+	err = updateTxStats(tx, emptyTxPointer, nil)
+	if err != nil {
+		return err
+	}
+
+	err = updateTxAddresses(tx, emptyTxPointer, nil)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Delete(TxFirstSeenBucketName, txHash)
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// keepFirstSeen sets firstSeen, the FirstSeen field of a record being saved,
+// to the time the transaction with the provided hash was first saved, which
+// is the saved first seen time or else oldFirstSeen, the FirstSeen of the
+// record being replaced. The time is saved if it wasn't before.
+func keepFirstSeen(node storm.Node, txHash string, oldFirstSeen int64, firstSeen reflect.Value) error {
+	if !firstSeen.IsValid() {
+		return nil
+	}
+
+	var savedFirstSeen int64
+	err := node.Get(TxFirstSeenBucketName, txHash, &savedFirstSeen)
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	switch {
+	case savedFirstSeen > 0:
+		firstSeen.SetInt(savedFirstSeen)
+		return nil
+	case oldFirstSeen > 0:
+		firstSeen.SetInt(oldFirstSeen)
+	case firstSeen.Int() <= 0:
+		return nil
+	}
+	return node.Set(TxFirstSeenBucketName, txHash, firstSeen.Int())
+}
+
+// keepFirstSeenTimes saves the FirstSeen of the saved transactions of the
+// type of txData, before they are deleted, for transactions saved before the
+// first seen times were kept separately. This is best-effort: the records are
+// read one at a time from the raw bucket and records that cannot be decoded
+// are skipped.
+func keepFirstSeenTimes(walletDataDB *storm.DB, txData interface{}) error {
+	txType := reflect.TypeOf(txData).Elem()
+	hashField, hasHash := txType.FieldByName("Hash")
+	firstSeenField, hasFirstSeen := txType.FieldByName("FirstSeen")
+	if !hasHash || !hasFirstSeen {
+		return nil
+	}
+
+	// Only the hash and first seen time of the records are decoded, so that
+	// records saved with an older layout of txData can still be read.
+	recordType := reflect.StructOf([]reflect.StructField{
+		{Name: hashField.Name, Type: hashField.Type, Tag: hashField.Tag},
+		{Name: firstSeenField.Name, Type: firstSeenField.Type, Tag: firstSeenField.Tag},
+	})
+
+	return walletDataDB.Bolt.Update(func(tx *bolt.Tx) error {
+		bucket := walletDataDB.GetBucket(tx, txType.Name())
+		if bucket == nil {
+			return nil
+		}
+
+		node := walletDataDB.WithTransaction(tx)
+		codec := walletDataDB.Codec()
+		return bucket.ForEach(func(k, v []byte) error {
+			if v == nil {
+				return nil // storm index and metadata buckets
+			}
+
+			record := reflect.New(recordType)
+			if err := codec.Unmarshal(v, record.Interface()); err != nil {
+				return nil
+			}
+
+			record = record.Elem()
+			return keepFirstSeen(node, record.Field(0).String(), 0, record.Field(1))
+		})
+	})
+}
+
 func (db *DB) SaveOrUpdateVspdRecord(emptyTxPointer, record interface{}) (updated bool, err error) {
 	v := reflect.ValueOf(record)
 	txHash := reflect.Indirect(v).FieldByName("Hash").String()
@@ -69,8 +262,37 @@ func (db *DB) SaveLastIndexPoint(endBlockHeight int32) error {
 	return nil
 }
 
+// SetTxNote saves the note for the transaction with the provided hash.
+// An empty note deletes the saved note.
+func (db *DB) SetTxNote(txHash string, note string) error {
+	if note == "" {
+		err := db.walletDataDB.Delete(TxNotesBucketName, txHash)
+		if err != nil && err != storm.ErrNotFound {
+			return err
+		}
+		return nil
+	}
+
+	return db.walletDataDB.Set(TxNotesBucketName, txHash, note)
+}
+
 func (db *DB) ClearSavedTransactions(emptyTxPointer interface{}) error {
-	err := db.walletDataDB.Drop(emptyTxPointer)
+	err := keepFirstSeenTimes(db.walletDataDB, emptyTxPointer)
+	if err != nil {
+		return err
+	}
+
+	err = db.walletDataDB.Drop(emptyTxPointer)
+	if err != nil {
+		return err
+	}
+
+	err = clearTxStats(db.walletDataDB)
+	if err != nil {
+		return err
+	}
+
+	err = clearTxAddresses(db.walletDataDB)
 	if err != nil {
 		return err
 	}
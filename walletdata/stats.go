@@ -0,0 +1,147 @@
+package walletdata
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/index"
+)
+
+const (
+	// TxStatsBucketName is the bucket for the aggregates of the saved
+	// transactions, which are updated as transactions are saved.
+	TxStatsBucketName = "TxStats"
+	KeyTxStats        = "Stats"
+
+	txStatsMonthFormat = "2006-01"
+)
+
+// TxStats are aggregates of the saved transactions. CountByMonth maps months,
+// formatted as YYYY-MM in UTC, to the number of transactions with timestamps
+// in each month.
+type TxStats struct {
+	Count        int            `json:"count"`
+	CountByType  map[string]int `json:"count_by_type"`
+	CountByMonth map[string]int `json:"count_by_month"`
+}
+
+// TxStats returns the aggregates of the saved transactions.
+func (db *DB) TxStats() (*TxStats, error) {
+	return readTxStats(db.walletDataDB)
+}
+
+// TimestampRange returns the earliest and latest timestamps of the saved
+// transactions of the type of the transactions slice pointer, or zeros if
+// there are none. The timestamp index is read, not the transactions.
+func (db *DB) TimestampRange(transactions interface{}) (earliest, latest int64, err error) {
+	timestamp := func(options ...func(*index.Options)) (int64, error) {
+		err := db.walletDataDB.AllByIndex("Timestamp", transactions, append(options, storm.Limit(1))...)
+		if err != nil && err != storm.ErrNotFound {
+			return 0, err
+		}
+
+		txs := reflect.Indirect(reflect.ValueOf(transactions))
+		if txs.Len() == 0 {
+			return 0, nil
+		}
+		return reflect.Indirect(txs.Index(0)).FieldByName("Timestamp").Int(), nil
+	}
+
+	earliest, err = timestamp()
+	if err != nil {
+		return 0, 0, err
+	}
+	latest, err = timestamp(storm.Reverse())
+	if err != nil {
+		return 0, 0, err
+	}
+	return earliest, latest, nil
+}
+
+func readTxStats(node storm.Node) (*TxStats, error) {
+	stats := new(TxStats)
+	err := node.Get(TxStatsBucketName, KeyTxStats, stats)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	if stats.CountByType == nil {
+		stats.CountByType = make(map[string]int)
+	}
+	if stats.CountByMonth == nil {
+		stats.CountByMonth = make(map[string]int)
+	}
+	return stats, nil
+}
+
+// updateTxStats removes oldRecord, if not nil, from the saved stats and adds
+// newRecord, if not nil.
+func updateTxStats(node storm.Node, oldRecord, newRecord interface{}) error {
+	stats, err := readTxStats(node)
+	if err != nil {
+		return err
+	}
+
+	if oldRecord != nil {
+		stats.add(oldRecord, -1)
+	}
+	if newRecord != nil {
+		stats.add(newRecord, 1)
+	}
+
+	return node.Set(TxStatsBucketName, KeyTxStats, stats)
+}
+
+// clearTxStats deletes the saved stats, for use when the transactions are
+// deleted.
+func clearTxStats(node storm.Node) error {
+	err := node.Delete(TxStatsBucketName, KeyTxStats)
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// buildTxStats saves the stats of the saved transactions, for dbs created
+// before the stats were kept.
+func buildTxStats(node storm.Node, txData interface{}) error {
+	records := reflect.New(reflect.SliceOf(reflect.TypeOf(txData).Elem()))
+	err := node.All(records.Interface())
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	stats := &TxStats{
+		CountByType:  make(map[string]int),
+		CountByMonth: make(map[string]int),
+	}
+	for i := 0; i < records.Elem().Len(); i++ {
+		stats.add(records.Elem().Index(i).Interface(), 1)
+	}
+
+	return node.Set(TxStatsBucketName, KeyTxStats, stats)
+}
+
+// add adds n records like record to the stats, n is negative to remove them.
+func (stats *TxStats) add(record interface{}, n int) {
+	v := reflect.Indirect(reflect.ValueOf(record))
+
+	stats.Count += n
+
+	if txType := v.FieldByName("Type"); txType.IsValid() {
+		addCount(stats.CountByType, txType.String(), n)
+	}
+
+	if timestamp := v.FieldByName("Timestamp"); timestamp.IsValid() && timestamp.Int() > 0 {
+		month := time.Unix(timestamp.Int(), 0).UTC().Format(txStatsMonthFormat)
+		addCount(stats.CountByMonth, month, n)
+	}
+}
+
+func addCount(counts map[string]int, key string, n int) {
+	counts[key] += n
+	if counts[key] <= 0 {
+		delete(counts, key)
+	}
+}
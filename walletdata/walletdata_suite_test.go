@@ -0,0 +1,13 @@
+package walletdata_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestWalletdata(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Walletdata Suite")
+}
@@ -0,0 +1,143 @@
+package dcrlibwallet
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/planetdecred/dcrlibwallet/utils"
+)
+
+const (
+	// boltMagic is the magic number in the meta pages of bolt databases.
+	boltMagic uint32 = 0xED0CDAED
+
+	// boltMetaPageFlag is the flag of the meta pages of bolt databases.
+	boltMetaPageFlag uint16 = 0x04
+
+	// badgerManifestFileName is the name of the manifest file in badger
+	// database directories.
+	badgerManifestFileName = "MANIFEST"
+)
+
+// NetworkWallets lists the ids of the wallets found for a network by
+// EnumerateWallets.
+type NetworkWallets struct {
+	Network   string `json:"network"`
+	WalletIDs []int  `json:"wallet_ids"`
+}
+
+// WalletsExistOnNetwork returns true if a MultiWallet created with rootDir and
+// netType would load at least one wallet. Unlike creating a MultiWallet, this
+// does not create or open any files.
+func WalletsExistOnNetwork(rootDir, netType string) (bool, error) {
+	chainParams, err := utils.ChainParams(netType)
+	if err != nil {
+		return false, fmt.Errorf("%s: %v", ErrInvalid, err)
+	}
+
+	walletIDs, err := walletIDsIn(rootDir, chainParams)
+	if err != nil {
+		return false, err
+	}
+	return len(walletIDs) > 0, nil
+}
+
+// EnumerateWallets returns a json array of the networks that have wallets
+// under rootDir, the root directory passed to NewMultiWallet, along with the
+// ids of their wallets. Wallet directories without a valid wallet database are
+// skipped.
+func EnumerateWallets(rootDir string) (string, error) {
+	networks := make([]*NetworkWallets, 0)
+	for _, chainParams := range []*chaincfg.Params{chaincfg.MainNetParams(), chaincfg.TestNet3Params()} {
+		walletIDs, err := walletIDsIn(rootDir, chainParams)
+		if err != nil {
+			return "", err
+		}
+		if len(walletIDs) == 0 {
+			continue
+		}
+
+		networks = append(networks, &NetworkWallets{
+			Network:   chainParams.Name,
+			WalletIDs: walletIDs,
+		})
+	}
+
+	result, err := json.Marshal(networks)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// walletIDsIn returns the ids of the wallet directories with a valid wallet
+// database in the network directory of rootDir.
+func walletIDsIn(rootDir string, chainParams *chaincfg.Params) ([]int, error) {
+	networkDir := filepath.Join(rootDir, chainParams.Name)
+	entries, err := ioutil.ReadDir(networkDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var walletIDs []int
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		walletID, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		walletDbPath := filepath.Join(networkDir, entry.Name(), walletDbName)
+		if isWalletDb(walletDbPath) {
+			walletIDs = append(walletIDs, walletID)
+		} else {
+			log.Debugf("Ignoring wallet directory without a wallet database: %s", entry.Name())
+		}
+	}
+
+	sort.Ints(walletIDs)
+	return walletIDs, nil
+}
+
+// isWalletDb returns true if path is a bolt database file or a badger database
+// directory. The database is not opened, so this works for databases that are
+// in use.
+func isWalletDb(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	if info.IsDir() {
+		exists, _ := fileExists(filepath.Join(path, badgerManifestFileName))
+		return exists
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	// The first page of bolt databases is a meta page with a 16 byte page
+	// header, where the page flags are at offset 8, followed by the magic.
+	header := make([]byte, 20)
+	if _, err = io.ReadFull(file, header); err != nil {
+		return false
+	}
+	return binary.LittleEndian.Uint16(header[8:10]) == boltMetaPageFlag &&
+		binary.LittleEndian.Uint32(header[16:20]) == boltMagic
+}